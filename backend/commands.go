@@ -0,0 +1,62 @@
+package main
+
+import (
+	"backend/models"
+	"fmt"
+	"os"
+	"text/tabwriter"
+)
+
+// runKeysCommand menjalankan perintah keyring dari CLI:
+//
+//	go run . keys list
+//	go run . keys rotate
+//	go run . keys retire <kid>
+//
+// Server yang sedang berjalan memuat ulang keyring paling lambat satu menit kemudian.
+func runKeysCommand(args []string) error {
+	keyModel := models.NewTokenKeyModel()
+
+	if len(args) == 0 {
+		return fmt.Errorf("usage: keys list | keys rotate | keys retire <kid>")
+	}
+
+	switch args[0] {
+	case "list":
+		keys, err := keyModel.FindAll()
+		if err != nil {
+			return err
+		}
+		writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(writer, "KID\tSTATUS\tCREATED\tROTATED\tRETIRED")
+		for _, key := range keys {
+			fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\n", key.Kid, key.Status, key.CreatedAt,
+				valueOrDash(key.RotatedAt), valueOrDash(key.RetiredAt))
+		}
+		return writer.Flush()
+	case "rotate":
+		kid, err := keyModel.Rotate()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("New active token key: %s\n", kid)
+		return nil
+	case "retire":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: keys retire <kid>")
+		}
+		if err := keyModel.Retire(args[1]); err != nil {
+			return err
+		}
+		fmt.Printf("Token key %s retired\n", args[1])
+		return nil
+	}
+	return fmt.Errorf("unknown keys command %q", args[0])
+}
+
+func valueOrDash(value *string) string {
+	if value == nil {
+		return "-"
+	}
+	return *value
+}
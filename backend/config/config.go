@@ -0,0 +1,465 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Secret bawaan hanya untuk development; mode production menolak berjalan dengan nilai ini
+const (
+	defaultJWTKey        = "wiki"
+	defaultEncryptionKey = "myverystrongpasswordo32bitlength"
+)
+
+// DatabaseConfig adalah pengaturan koneksi MySQL
+type DatabaseConfig struct {
+	User     string `json:"user"`
+	Password string `json:"password"`
+	// Host berformat "host:port"; kosong berarti koneksi default driver (localhost:3306)
+	Host string `json:"host"`
+	Name string `json:"name"`
+}
+
+// DSN membentuk data source name untuk driver go-sql-driver/mysql
+func (d DatabaseConfig) DSN() string {
+	address := ""
+	if d.Host != "" {
+		address = "tcp(" + d.Host + ")"
+	}
+	return d.User + ":" + d.Password + "@" + address + "/" + d.Name
+}
+
+// MailConfig adalah pengaturan SMTP untuk email keluar. Host kosong berarti email
+// tidak dikirim dan isinya hanya ditulis ke log (untuk development).
+type MailConfig struct {
+	Host     string `json:"smtp_host"`
+	Port     string `json:"smtp_port"`
+	Username string `json:"smtp_username"`
+	Password string `json:"smtp_password"`
+	From     string `json:"from"`
+}
+
+// PasswordPolicyConfig adalah aturan password baru saat user dibuat, diubah admin,
+// diganti sendiri atau di-reset. Password yang sudah tersimpan tidak diperiksa ulang.
+type PasswordPolicyConfig struct {
+	MinLength     int  `json:"min_length"`
+	RequireUpper  bool `json:"require_upper"`
+	RequireLower  bool `json:"require_lower"`
+	RequireDigit  bool `json:"require_digit"`
+	RequireSymbol bool `json:"require_symbol"`
+	// RejectCommon menolak password yang ada di daftar password umum/bocor bawaan
+	RejectCommon bool `json:"reject_common"`
+	// CommonPasswordsFile adalah daftar tambahan (satu password per baris), misalnya
+	// daftar 100 ribu password teratas; tidak dikirim ke client
+	CommonPasswordsFile string `json:"common_passwords_file,omitempty"`
+	// History adalah jumlah password terakhir (termasuk yang sekarang) yang tidak
+	// boleh dipakai lagi; 0 berarti tidak diperiksa
+	History int `json:"history"`
+}
+
+// RoleMapping memetakan grup dari sumber akun eksternal (LDAP/SSO) ke role dan
+// instansi wiki. Group berupa DN lengkap atau CN grup; RoleID/InstanceID 0 berarti
+// aturan ini tidak menentukan nilai tersebut.
+type RoleMapping struct {
+	Group      string `json:"group"`
+	RoleID     int64  `json:"role_id"`
+	InstanceID int64  `json:"instance_id"`
+}
+
+// LDAPConfig adalah pengaturan login lewat LDAP/Active Directory. URL kosong
+// berarti LDAP tidak dipakai.
+type LDAPConfig struct {
+	// URL berformat ldap://host:389 atau ldaps://host:636
+	URL                string `json:"url"`
+	StartTLS           bool   `json:"start_tls"`
+	InsecureSkipVerify bool   `json:"insecure_skip_verify"`
+	// BindDN/BindPassword adalah akun layanan untuk mencari user; kosong berarti bind anonim
+	BindDN       string `json:"bind_dn"`
+	BindPassword string `json:"bind_password"`
+	BaseDN       string `json:"base_dn"`
+	// UserFilter mencari user berdasarkan email atau NIP; {login} diganti isian login
+	UserFilter     string `json:"user_filter"`
+	EmailAttribute string `json:"email_attribute"`
+	NameAttribute  string `json:"name_attribute"`
+	NIPAttribute   string `json:"nip_attribute"`
+	GroupAttribute string `json:"group_attribute"`
+	// RoleMappings dicek berurutan; aturan pertama yang cocok menentukan role/instansi
+	RoleMappings []RoleMapping `json:"role_mappings"`
+	// DefaultRoleID/DefaultInstanceID dipakai jika tidak ada grup yang cocok;
+	// 0 berarti user tanpa grup yang cocok tidak boleh login
+	DefaultRoleID     int64 `json:"default_role_id"`
+	DefaultInstanceID int64 `json:"default_instance_id"`
+	// LinkExistingAccounts menautkan akun wiki yang sudah ada dengan email yang sama
+	// tanpa izin admin per akun; hanya aktifkan jika email di direktori terpercaya
+	LinkExistingAccounts bool `json:"link_existing_accounts"`
+	TimeoutSeconds       int  `json:"timeout_seconds"`
+}
+
+// Enabled menandakan login LDAP diaktifkan
+func (l LDAPConfig) Enabled() bool {
+	return l.URL != ""
+}
+
+// OIDCConfig adalah pengaturan single sign-on lewat OpenID Connect. IssuerURL
+// kosong berarti SSO tidak dipakai.
+type OIDCConfig struct {
+	// DisplayName adalah label tombol login di frontend, mis. "SSO Jogjaprov"
+	DisplayName  string `json:"display_name"`
+	IssuerURL    string `json:"issuer_url"`
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	// RedirectURL adalah alamat callback backend yang didaftarkan di identity provider,
+	// mis. https://wiki.jogjaprov.go.id/api/sso/oidc/callback
+	RedirectURL string   `json:"redirect_url"`
+	Scopes      []string `json:"scopes"`
+	EmailClaim  string   `json:"email_claim"`
+	NameClaim   string   `json:"name_claim"`
+	NIPClaim    string   `json:"nip_claim"`
+	GroupsClaim string   `json:"groups_claim"`
+	// RequireVerifiedEmail menolak ID token dengan email_verified false
+	RequireVerifiedEmail bool          `json:"require_verified_email"`
+	RoleMappings         []RoleMapping `json:"role_mappings"`
+	DefaultRoleID        int64         `json:"default_role_id"`
+	DefaultInstanceID    int64         `json:"default_instance_id"`
+	// LinkExistingAccounts sama seperti di LDAPConfig; hanya aktifkan jika identity
+	// provider memverifikasi kepemilikan email
+	LinkExistingAccounts bool `json:"link_existing_accounts"`
+	TimeoutSeconds       int  `json:"timeout_seconds"`
+}
+
+// Enabled menandakan login SSO diaktifkan
+func (o OIDCConfig) Enabled() bool {
+	return o.IssuerURL != ""
+}
+
+// Config adalah seluruh pengaturan aplikasi. Nilai dibaca dari file JSON
+// (CONFIG_FILE, atau config.json jika ada) lalu ditimpa oleh variabel lingkungan.
+type Config struct {
+	Env      string         `json:"env"`
+	Port     string         `json:"port"`
+	Database DatabaseConfig `json:"database"`
+	Mail     MailConfig     `json:"mail"`
+	// PasswordPolicy adalah aturan password baru
+	PasswordPolicy PasswordPolicyConfig `json:"password_policy"`
+	LDAP           LDAPConfig           `json:"ldap"`
+	OIDC           OIDCConfig           `json:"oidc"`
+	JWTKey         string               `json:"jwt_key"`
+	EncryptionKey  string               `json:"encryption_key"`
+	CORSOrigins    []string             `json:"cors_origins"`
+	// SiteURL adalah alamat frontend untuk link di sitemap, feed dan email
+	SiteURL string `json:"site_url"`
+	// RateLimits berisi batas per nama limiter dengan format "<request per menit>/<burst>"
+	RateLimits map[string]string `json:"rate_limits"`
+}
+
+var (
+	loaded     *Config
+	loadErr    error
+	loadConfig sync.Once
+)
+
+// Get mengembalikan konfigurasi aplikasi, dimuat sekali saat pertama dipanggil.
+// Konfigurasi yang tidak valid menghentikan proses agar server tidak berjalan
+// dengan pengaturan yang salah.
+func Get() *Config {
+	loadConfig.Do(func() {
+		loaded, loadErr = Load()
+	})
+	if loadErr != nil {
+		log.Fatalf("Invalid configuration: %v", loadErr)
+	}
+	return loaded
+}
+
+func defaults() *Config {
+	return &Config{
+		Env:      "development",
+		Port:     "3000",
+		Database: DatabaseConfig{User: "root", Name: "wiki"},
+		Mail:     MailConfig{Port: "25", From: "wiki@localhost"},
+		PasswordPolicy: PasswordPolicyConfig{
+			MinLength:    8,
+			RequireLower: true,
+			RequireDigit: true,
+			RejectCommon: true,
+			History:      5,
+		},
+		LDAP: LDAPConfig{
+			UserFilter:     "(&(objectClass=person)(|(mail={login})(employeeNumber={login})))",
+			EmailAttribute: "mail",
+			NameAttribute:  "cn",
+			NIPAttribute:   "employeeNumber",
+			GroupAttribute: "memberOf",
+			TimeoutSeconds: 10,
+		},
+		OIDC: OIDCConfig{
+			DisplayName:          "SSO",
+			Scopes:               []string{"openid", "email", "profile"},
+			EmailClaim:           "email",
+			NameClaim:            "name",
+			NIPClaim:             "nip",
+			GroupsClaim:          "groups",
+			RequireVerifiedEmail: true,
+			TimeoutSeconds:       10,
+		},
+		JWTKey:        defaultJWTKey,
+		EncryptionKey: defaultEncryptionKey,
+		CORSOrigins:   []string{"http://localhost:3001"},
+		SiteURL:       "http://localhost:3001",
+		RateLimits:    map[string]string{},
+	}
+}
+
+// Load membaca konfigurasi dari file dan variabel lingkungan lalu memvalidasinya
+func Load() (*Config, error) {
+	cfg := defaults()
+
+	path, required := os.Getenv("CONFIG_FILE"), true
+	if path == "" {
+		path, required = "config.json", false
+	}
+	if err := cfg.loadFile(path, required); err != nil {
+		return nil, err
+	}
+	cfg.loadEnv()
+
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	if !cfg.IsProduction() && (cfg.JWTKey == defaultJWTKey || cfg.EncryptionKey == defaultEncryptionKey) {
+		log.Println("Warning: using default development secrets; set JWT_KEY and ENCRYPTION_KEY before deploying")
+	}
+	return cfg, nil
+}
+
+func (c *Config) loadFile(path string, required bool) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && !required {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	if err := json.Unmarshal(data, c); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	if c.RateLimits == nil {
+		c.RateLimits = map[string]string{}
+	}
+	return nil
+}
+
+func (c *Config) loadEnv() {
+	setFromEnv(&c.Env, "APP_ENV")
+	setFromEnv(&c.Port, "PORT")
+	setFromEnv(&c.Database.User, "DB_USER")
+	setFromEnv(&c.Database.Password, "DB_PASSWORD")
+	setFromEnv(&c.Database.Host, "DB_HOST")
+	setFromEnv(&c.Database.Name, "DB_NAME")
+	setFromEnv(&c.Mail.Host, "SMTP_HOST")
+	setFromEnv(&c.Mail.Port, "SMTP_PORT")
+	setFromEnv(&c.Mail.Username, "SMTP_USERNAME")
+	setFromEnv(&c.Mail.Password, "SMTP_PASSWORD")
+	setFromEnv(&c.Mail.From, "MAIL_FROM")
+	setFromEnv(&c.LDAP.URL, "LDAP_URL")
+	setFromEnv(&c.LDAP.BindDN, "LDAP_BIND_DN")
+	setFromEnv(&c.LDAP.BindPassword, "LDAP_BIND_PASSWORD")
+	setFromEnv(&c.LDAP.BaseDN, "LDAP_BASE_DN")
+	setFromEnv(&c.OIDC.IssuerURL, "OIDC_ISSUER_URL")
+	setFromEnv(&c.OIDC.ClientID, "OIDC_CLIENT_ID")
+	setFromEnv(&c.OIDC.ClientSecret, "OIDC_CLIENT_SECRET")
+	setFromEnv(&c.OIDC.RedirectURL, "OIDC_REDIRECT_URL")
+	setFromEnv(&c.JWTKey, "JWT_KEY")
+	setFromEnv(&c.EncryptionKey, "ENCRYPTION_KEY")
+	setFromEnv(&c.SiteURL, "SITE_URL")
+
+	if origins := os.Getenv("CORS_ORIGINS"); origins != "" {
+		c.CORSOrigins = nil
+		for _, origin := range strings.Split(origins, ",") {
+			if origin = strings.TrimSpace(origin); origin != "" {
+				c.CORSOrigins = append(c.CORSOrigins, origin)
+			}
+		}
+	}
+
+	// RATE_LIMIT_<NAMA>="<per menit>/<burst>" untuk setiap limiter
+	for _, variable := range os.Environ() {
+		key, value, _ := strings.Cut(variable, "=")
+		if name, ok := strings.CutPrefix(key, "RATE_LIMIT_"); ok && name != "" && value != "" {
+			c.RateLimits[strings.ToLower(name)] = value
+		}
+	}
+}
+
+func setFromEnv(target *string, variable string) {
+	if value, ok := os.LookupEnv(variable); ok {
+		*target = value
+	}
+}
+
+// validate memeriksa pengaturan wajib; semua kesalahan dilaporkan sekaligus
+func (c *Config) validate() error {
+	var problems []string
+
+	c.Env = strings.ToLower(strings.TrimSpace(c.Env))
+	if c.Env != "development" && c.Env != "production" {
+		problems = append(problems, fmt.Sprintf("env must be development or production, got %q", c.Env))
+	}
+	if c.Port == "" {
+		problems = append(problems, "port is required")
+	}
+	if c.Database.User == "" || c.Database.Name == "" {
+		problems = append(problems, "database user and name are required")
+	}
+	if c.Mail.Host != "" && (c.Mail.Port == "" || c.Mail.From == "") {
+		problems = append(problems, "mail smtp_port and from are required when smtp_host is set")
+	}
+	if c.PasswordPolicy.MinLength < 1 || c.PasswordPolicy.MinLength > 72 {
+		// bcrypt hanya memakai 72 byte pertama
+		problems = append(problems, "password_policy min_length must be between 1 and 72")
+	}
+	if c.PasswordPolicy.History < 0 || c.PasswordPolicy.History > 24 {
+		problems = append(problems, "password_policy history must be between 0 and 24")
+	}
+	if c.PasswordPolicy.CommonPasswordsFile != "" {
+		if _, err := os.Stat(c.PasswordPolicy.CommonPasswordsFile); err != nil {
+			problems = append(problems, fmt.Sprintf("password_policy common_passwords_file cannot be read: %v", err))
+		}
+	}
+	if c.LDAP.Enabled() {
+		problems = append(problems, c.LDAP.validate()...)
+	}
+	if c.OIDC.Enabled() {
+		problems = append(problems, c.OIDC.validate(c.IsProduction())...)
+	}
+	if c.JWTKey == "" {
+		problems = append(problems, "jwt_key is required")
+	}
+	switch len(c.EncryptionKey) {
+	case 16, 24, 32:
+	default:
+		problems = append(problems, "encryption_key must be 16, 24 or 32 bytes long")
+	}
+	if len(c.CORSOrigins) == 0 {
+		problems = append(problems, "at least one CORS origin is required")
+	}
+	c.SiteURL = strings.TrimSuffix(c.SiteURL, "/")
+	if c.SiteURL == "" {
+		problems = append(problems, "site_url is required")
+	}
+	for name, value := range c.RateLimits {
+		if _, _, err := parseRateLimit(value); err != nil {
+			problems = append(problems, fmt.Sprintf("rate limit %s: %v", name, err))
+		}
+	}
+
+	if c.IsProduction() {
+		if c.JWTKey == defaultJWTKey || len(c.JWTKey) < 32 {
+			problems = append(problems, "jwt_key must be changed from the default and be at least 32 bytes in production")
+		}
+		if c.EncryptionKey == defaultEncryptionKey {
+			problems = append(problems, "encryption_key must be changed from the default in production")
+		}
+		if c.Mail.Host == "" {
+			problems = append(problems, "mail smtp_host is required in production")
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func (l LDAPConfig) validate() []string {
+	var problems []string
+	if !strings.HasPrefix(l.URL, "ldap://") && !strings.HasPrefix(l.URL, "ldaps://") {
+		problems = append(problems, "ldap url must start with ldap:// or ldaps://")
+	}
+	if l.BaseDN == "" {
+		problems = append(problems, "ldap base_dn is required")
+	}
+	if !strings.Contains(l.UserFilter, "{login}") {
+		problems = append(problems, "ldap user_filter must contain {login}")
+	}
+	if l.EmailAttribute == "" || l.NameAttribute == "" {
+		problems = append(problems, "ldap email_attribute and name_attribute are required")
+	}
+	if l.TimeoutSeconds <= 0 {
+		problems = append(problems, "ldap timeout_seconds must be positive")
+	}
+	problems = append(problems, validateRoleMappings("ldap", l.RoleMappings)...)
+	return problems
+}
+
+func (o OIDCConfig) validate(production bool) []string {
+	var problems []string
+	if !strings.HasPrefix(o.IssuerURL, "https://") && !strings.HasPrefix(o.IssuerURL, "http://") {
+		problems = append(problems, "oidc issuer_url must be an http(s) URL")
+	}
+	// Di production semua komunikasi dengan identity provider wajib lewat HTTPS
+	if production && (!strings.HasPrefix(o.IssuerURL, "https://") || !strings.HasPrefix(o.RedirectURL, "https://")) {
+		problems = append(problems, "oidc issuer_url and redirect_url must use https in production")
+	}
+	if o.ClientID == "" || o.RedirectURL == "" {
+		problems = append(problems, "oidc client_id and redirect_url are required")
+	}
+	if o.EmailClaim == "" || o.NameClaim == "" {
+		problems = append(problems, "oidc email_claim and name_claim are required")
+	}
+	if o.TimeoutSeconds <= 0 {
+		problems = append(problems, "oidc timeout_seconds must be positive")
+	}
+	problems = append(problems, validateRoleMappings("oidc", o.RoleMappings)...)
+	return problems
+}
+
+func validateRoleMappings(source string, mappings []RoleMapping) []string {
+	var problems []string
+	for i, mapping := range mappings {
+		if mapping.Group == "" {
+			problems = append(problems, fmt.Sprintf("%s role_mappings[%d]: group is required", source, i))
+		}
+		if mapping.RoleID == 0 && mapping.InstanceID == 0 {
+			problems = append(problems, fmt.Sprintf("%s role_mappings[%d]: role_id or instance_id is required", source, i))
+		}
+	}
+	return problems
+}
+
+// IsProduction menandakan aplikasi berjalan di mode production
+func (c *Config) IsProduction() bool {
+	return c.Env == "production"
+}
+
+// Addr adalah alamat listen server HTTP
+func (c *Config) Addr() string {
+	return ":" + c.Port
+}
+
+// RateLimit mengembalikan batas limiter dengan nama tersebut, atau nilai default
+// jika tidak diatur
+func (c *Config) RateLimit(name string, requestsPerMinute int, burst int) (int, int) {
+	value, ok := c.RateLimits[strings.ToLower(name)]
+	if !ok {
+		return requestsPerMinute, burst
+	}
+	perMinute, size, err := parseRateLimit(value)
+	if err != nil {
+		return requestsPerMinute, burst
+	}
+	return perMinute, size
+}
+
+func parseRateLimit(value string) (int, int, error) {
+	var perMinute, burst int
+	if _, err := fmt.Sscanf(value, "%d/%d", &perMinute, &burst); err != nil || perMinute <= 0 || burst <= 0 {
+		return 0, 0, fmt.Errorf("invalid value %q, expected <request per menit>/<burst>", value)
+	}
+	return perMinute, burst, nil
+}
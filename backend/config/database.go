@@ -5,33 +5,53 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"sync"
+	"time"
 
 	_ "github.com/go-sql-driver/mysql"
 )
 
-// DBConnection untuk membuat koneksi ke database
+// Satu connection pool dipakai bersama oleh semua model dan middleware
+var (
+	sharedDB   *sql.DB
+	sharedDBMu sync.Mutex
+)
+
+// DBConnection mengembalikan connection pool bersama, dan membuatnya saat pertama kali dipanggil
 func DBConnection() (*sql.DB, error) {
-	dbDriver := "mysql"
-	dbUser := "root"
-	dbPass := ""
-	dbName := "wiki"
+	sharedDBMu.Lock()
+	defer sharedDBMu.Unlock()
+
+	if sharedDB != nil {
+		return sharedDB, nil
+	}
 
 	// Membuat koneksi ke database
-	db, err := sql.Open(dbDriver, dbUser+":"+dbPass+"@/"+dbName)
+	db, err := sql.Open("mysql", Get().Database.DSN())
 	if err != nil {
 		return nil, fmt.Errorf("failed to open connection: %w", err)
 	}
 
-	// Set konfigurasi koneksi (opsional)
-	db.SetMaxOpenConns(0)
-	db.SetMaxIdleConns(0)
-	db.SetConnMaxLifetime(0)
+	// Set konfigurasi connection pool
+	db.SetMaxOpenConns(25)
+	db.SetMaxIdleConns(25)
+	db.SetConnMaxLifetime(5 * time.Minute)
 
 	// Verifikasi koneksi dengan Ping
 	if err := db.Ping(); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
 	log.Println("Database connected successfully")
-	return db, nil
+	sharedDB = db
+	return sharedDB, nil
+}
+
+// SetDBConnection memasang connection pool yang sudah dibuat sebagai pool bersama,
+// misalnya database tiruan di test, sehingga DBConnection tidak membuka koneksi MySQL
+func SetDBConnection(db *sql.DB) {
+	sharedDBMu.Lock()
+	defer sharedDBMu.Unlock()
+	sharedDB = db
 }
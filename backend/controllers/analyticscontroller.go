@@ -0,0 +1,51 @@
+package controllers
+
+import (
+	"backend/models"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+var analyticsModel = models.NewAnalyticsModel()
+
+// Rentang default dashboard adalah satu tahun terakhir, maksimal sepuluh tahun
+const defaultDashboardDays = 365
+const maxDashboardDays = 3660
+
+// GetDashboard mengembalikan statistik gabungan wiki untuk administrator.
+// Parameter: from, to (YYYY-MM-DD) dan top (jumlah item pada daftar teratas, default 10).
+func GetDashboard(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := parseDateRange(r, defaultDashboardDays, maxDashboardDays)
+	if !ok {
+		http.Error(w, "Invalid date range, use from/to as YYYY-MM-DD", http.StatusBadRequest)
+		return
+	}
+
+	top := 10
+	if topStr := r.URL.Query().Get("top"); topStr != "" {
+		value, err := strconv.Atoi(topStr)
+		if err != nil || value < 1 || value > 100 {
+			http.Error(w, "Parameter 'top' must be between 1 and 100", http.StatusBadRequest)
+			return
+		}
+		top = value
+	}
+
+	dashboard, err := analyticsModel.Dashboard(from, to, top)
+	if err != nil {
+		log.Printf("Error building dashboard: %v", err)
+		http.Error(w, "Failed to build dashboard", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dashboard)
+}
+
+// GetCacheStats menampilkan metrik hit/miss cache baca konten
+func GetCacheStats(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode([]models.CacheStats{models.ContentCacheStats()})
+}
@@ -0,0 +1,204 @@
+package controllers
+
+import (
+	"backend/entities"
+	"backend/helpers"
+	middleware "backend/middlewares"
+	"backend/models"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+var attachmentModel = models.NewAttachmentModel()
+
+// Folder penyimpanan file lampiran dan batas ukuran upload
+const attachmentDir = "uploads/attachments"
+const maxAttachmentSize = 20 << 20 // 20 MB
+
+func UploadAttachment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	contentID, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid content ID", http.StatusBadRequest)
+		return
+	}
+
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*middleware.Claims)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	content, _, _, err := contentModel.FindByIDWithAuthorName(contentID)
+	if err != nil {
+		http.Error(w, "Failed to fetch content", http.StatusInternalServerError)
+		return
+	}
+	if content == nil {
+		http.Error(w, "Content not found", http.StatusNotFound)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxAttachmentSize+1<<20)
+	if err := r.ParseMultipartForm(maxAttachmentSize); err != nil {
+		http.Error(w, "File is too large or request is not multipart", http.StatusBadRequest)
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "Form field 'file' is required", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	extension := strings.ToLower(filepath.Ext(header.Filename))
+	mimeType, supported := helpers.AttachmentMimeTypes[extension]
+	if !supported {
+		http.Error(w, "Only PDF, DOCX and TXT files are supported", http.StatusBadRequest)
+		return
+	}
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		http.Error(w, "Failed to read uploaded file", http.StatusBadRequest)
+		return
+	}
+
+	text, err := helpers.ExtractText(header.Filename, data)
+	if err != nil {
+		log.Printf("Failed to extract text from %s: %v", header.Filename, err)
+		http.Error(w, "Could not extract text from document", http.StatusUnprocessableEntity)
+		return
+	}
+
+	// Simpan file dengan nama acak agar tidak bentrok dan tidak bisa ditebak
+	randomName := make([]byte, 16)
+	if _, err := rand.Read(randomName); err != nil {
+		http.Error(w, "Failed to store file", http.StatusInternalServerError)
+		return
+	}
+	if err := os.MkdirAll(attachmentDir, 0o755); err != nil {
+		log.Printf("Failed to create attachment dir: %v", err)
+		http.Error(w, "Failed to store file", http.StatusInternalServerError)
+		return
+	}
+	storagePath := filepath.Join(attachmentDir, hex.EncodeToString(randomName)+extension)
+	if err := os.WriteFile(storagePath, data, 0o644); err != nil {
+		log.Printf("Failed to write attachment: %v", err)
+		http.Error(w, "Failed to store file", http.StatusInternalServerError)
+		return
+	}
+
+	attachment := entities.Attachment{
+		ContentID:     contentID,
+		FileName:      filepath.Base(header.Filename),
+		MimeType:      mimeType,
+		Size:          int64(len(data)),
+		StoragePath:   storagePath,
+		ExtractedText: text,
+		UploadedBy:    int64(claims.ID),
+		Created_at:    time.Now().Format("2006-01-02 15:04:05"),
+	}
+
+	attachment.Id, err = attachmentModel.CreateAttachment(attachment)
+	if err != nil {
+		os.Remove(storagePath)
+		log.Printf("Error saving attachment: %v", err)
+		http.Error(w, "Failed to save attachment", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":    "Attachment uploaded successfully",
+		"attachment": attachment,
+	})
+}
+
+func GetAttachmentsByContentID(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	contentID, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid content ID", http.StatusBadRequest)
+		return
+	}
+
+	attachments, err := attachmentModel.FindByContentID(contentID)
+	if err != nil {
+		http.Error(w, "Failed to fetch attachments", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(attachments)
+}
+
+func DownloadAttachment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid attachment ID", http.StatusBadRequest)
+		return
+	}
+
+	attachment, err := attachmentModel.FindByID(id)
+	if err != nil {
+		http.Error(w, "Failed to fetch attachment", http.StatusInternalServerError)
+		return
+	}
+	if attachment == nil {
+		http.Error(w, "Attachment not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", attachment.MimeType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", attachment.FileName))
+	http.ServeFile(w, r, attachment.StoragePath)
+}
+
+func DeleteAttachment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid attachment ID", http.StatusBadRequest)
+		return
+	}
+
+	attachment, err := attachmentModel.FindByID(id)
+	if err != nil {
+		http.Error(w, "Failed to fetch attachment", http.StatusInternalServerError)
+		return
+	}
+	if attachment == nil {
+		http.Error(w, "Attachment not found", http.StatusNotFound)
+		return
+	}
+
+	if err := attachmentModel.DeleteByID(id); err != nil {
+		http.Error(w, "Failed to delete attachment", http.StatusInternalServerError)
+		return
+	}
+	if err := os.Remove(attachment.StoragePath); err != nil && !os.IsNotExist(err) {
+		log.Printf("Failed to remove attachment file %s: %v", attachment.StoragePath, err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Attachment deleted successfully",
+		"id":      id,
+	})
+}
@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"backend/entities"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// makeETag membuat ETag lemah dari bagian-bagian yang menentukan isi respons
+func makeETag(parts ...interface{}) string {
+	hash := sha1.New()
+	for _, part := range parts {
+		fmt.Fprintf(hash, "%v|", part)
+	}
+	return `W/"` + hex.EncodeToString(hash.Sum(nil))[:20] + `"`
+}
+
+// latestUpdate mengambil waktu paling baru dari kolom DATETIME (misalnya updated_at)
+func latestUpdate(values ...string) time.Time {
+	var latest time.Time
+	for _, value := range values {
+		if parsed := parseFeedTime(value); parsed.After(latest) {
+			latest = parsed
+		}
+	}
+	return latest
+}
+
+// contentValidators menghitung ETag dan Last-Modified detail konten dari updated_at
+// konten dan subjudulnya; nama penulis dan instansi ikut dihitung karena ikut dikirim
+func contentValidators(content *entities.Content, authorName, instanceName string, subheadings []entities.Subheading) (string, time.Time) {
+	parts := []interface{}{content.Id, content.Updated_at, content.Accessibility, content.Status, authorName, instanceName}
+	updates := []string{content.Updated_at}
+	for _, subheading := range subheadings {
+		parts = append(parts, subheading.Id, subheading.Updated_at)
+		updates = append(updates, subheading.Updated_at)
+	}
+	return makeETag(parts...), latestUpdate(updates...)
+}
+
+// checkNotModified mengisi header ETag dan Last-Modified lalu mengevaluasi
+// If-None-Match / If-Modified-Since. Jika klien masih punya versi terbaru,
+// respons 304 langsung dikirim dan hasilnya true.
+func checkNotModified(w http.ResponseWriter, r *http.Request, etag string, lastModified time.Time) bool {
+	if etag != "" {
+		w.Header().Set("ETag", etag)
+	}
+	if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+	// Respons berautentikasi tidak boleh dipakai ulang browser tanpa revalidasi
+	if w.Header().Get("Cache-Control") == "" {
+		w.Header().Set("Cache-Control", "private, no-cache")
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	// If-None-Match lebih diutamakan daripada If-Modified-Since (RFC 9110)
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		if etag == "" || !etagMatches(match, etag) {
+			return false
+		}
+		writeNotModified(w)
+		return true
+	}
+
+	if since := r.Header.Get("If-Modified-Since"); since != "" && !lastModified.IsZero() {
+		sinceTime, err := http.ParseTime(since)
+		// Presisi header hanya sampai detik
+		if err != nil || lastModified.Truncate(time.Second).After(sinceTime) {
+			return false
+		}
+		writeNotModified(w)
+		return true
+	}
+	return false
+}
+
+// etagMatches membandingkan secara lemah, sehingga ETag yang diberi awalan W/
+// oleh middleware kompresi tetap cocok
+func etagMatches(header, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+func writeNotModified(w http.ResponseWriter) {
+	header := w.Header()
+	header.Del("Content-Type")
+	header.Del("Content-Length")
+	w.WriteHeader(http.StatusNotModified)
+}
@@ -22,33 +22,39 @@ var contentModel = models.NewContentModel()
 var subheadingModel = models.NewSubheadingModel()
 
 func GetIdTitleAllContents(response http.ResponseWriter, request *http.Request) {
-	response.Header().Set("Content-Type", "application/json")
+	opts, err := parseListOptions(request, models.ContentListSpec())
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	contents, err := contentModel.FindAll()
+	contents, total, err := contentModel.FindAll(opts)
 	if err != nil {
 		http.Error(response, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	json.NewEncoder(response).Encode(contents)
+	writePage(response, request, contents, total, opts)
 }
 
 func GetIdTitleAllContentsNotRejected(response http.ResponseWriter, request *http.Request) {
-	response.Header().Set("Content-Type", "application/json")
+	opts, err := parseListOptions(request, models.ContentListSpec())
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	contents, err := contentModel.FindNotRejected()
+	contents, total, err := contentModel.FindNotRejected(opts)
 	if err != nil {
 		http.Error(response, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	json.NewEncoder(response).Encode(contents)
+	writePage(response, request, contents, total, opts)
 }
 
 
 func GetIdTitleAllContentsNotDeleted(response http.ResponseWriter, request *http.Request) {
-	response.Header().Set("Content-Type", "application/json")
-
 	// Ambil instance_id dan role_id dari context
 	claims, ok := request.Context().Value(middleware.UserContextKey).(*middleware.Claims)
 	if !ok {
@@ -59,27 +65,43 @@ func GetIdTitleAllContentsNotDeleted(response http.ResponseWriter, request *http
 	instanceID := claims.InstanceID // instanceID bertipe int
 	roleID := claims.RoleID         // roleID bertipe int64
 
+	opts, err := parseListOptions(request, models.ContentListSpec())
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Panggil model dengan parameter instanceID dan roleID
-	contents, err := contentModel.FindNotDelete(instanceID, roleID)
+	contents, total, err := contentModel.FindNotDelete(instanceID, roleID, opts)
 	if err != nil {
 		http.Error(response, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	json.NewEncoder(response).Encode(contents)
+	writePage(response, request, contents, total, opts)
 }
 
 
 func GetIdTitleAllDrafts(response http.ResponseWriter, request *http.Request) {
-	response.Header().Set("Content-Type", "application/json")
+	opts, err := parseListOptions(request, models.ContentListSpec())
+	if err != nil {
+		http.Error(response, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	contents, err := contentModel.FindDrafts()
+	contents, total, err := contentModel.FindDrafts(opts)
 	if err != nil {
 		http.Error(response, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	json.NewEncoder(response).Encode(contents)
+	writePage(response, request, contents, total, opts)
+}
+
+// contentSearchResult adalah hasil pencarian beserta dokumen lampiran yang cocok
+type contentSearchResult struct {
+	entities.Content
+	MatchedDocuments []entities.AttachmentMatch `json:"matched_documents"`
 }
 
 func SearchContent(response http.ResponseWriter, request *http.Request) {
@@ -97,11 +119,29 @@ func SearchContent(response http.ResponseWriter, request *http.Request) {
 		return
 	}
 
+	// Dokumen lampiran yang cocok, dikelompokkan per konten
+	matches, err := attachmentModel.Search(searchTerm)
+	if err != nil {
+		http.Error(response, fmt.Sprintf("Error during search: %v", err), http.StatusInternalServerError)
+		return
+	}
+	matchesByContent := map[int64][]entities.AttachmentMatch{}
+	for _, match := range matches {
+		matchesByContent[match.ContentID] = append(matchesByContent[match.ContentID], match)
+	}
+
 	// Filter konten yang berstatus "approved"
-	approvedContents := []entities.Content{}
+	approvedContents := []contentSearchResult{}
 	for _, content := range contents {
 		if content.Status == "approved" {
-			approvedContents = append(approvedContents, content)
+			documents := matchesByContent[content.Id]
+			if documents == nil {
+				documents = []entities.AttachmentMatch{}
+			}
+			approvedContents = append(approvedContents, contentSearchResult{
+				Content:          content,
+				MatchedDocuments: documents,
+			})
 		}
 	}
 
@@ -147,6 +187,9 @@ func GetContentByID(response http.ResponseWriter, request *http.Request) {
 		return
 	}
 
+	// Catat view unik pembaca (deduplikasi per hari dilakukan di server)
+	recordContentView(request, content)
+
 	// Ambil subheadings terkait dengan konten
 	subheadings, err := subheadingModel.FindByContentID(content.Id)
 	if err != nil {
@@ -154,6 +197,12 @@ func GetContentByID(response http.ResponseWriter, request *http.Request) {
 		return
 	}
 
+	// Klien yang masih memegang versi terbaru cukup menerima 304
+	etag, lastModified := contentValidators(content, authorName, instanceName, subheadings)
+	if checkNotModified(response, request, etag, lastModified) {
+		return
+	}
+
 	// Menyusun data untuk dikirim sebagai respons
 	data := map[string]interface{}{
 		"content":       content,
@@ -340,28 +389,25 @@ func GetUserContents(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	opts, err := parseListOptions(r, models.ContentListSpec())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Log the authorId for debugging purposes
 	log.Printf("Fetching contents for authorId: %d", id)
 
 	// Fetch contents for the given userId
-	contents, err := contentModel.GetContentsByAuthorId(id)
+	contents, total, err := contentModel.GetContentsByAuthorId(id, opts)
 	if err != nil {
 		// Return an error if fetching contents fails
 		http.Error(w, "Error fetching contents", http.StatusInternalServerError)
 		return
 	}
 
-	// If no contents are found, return a 404
-	if len(contents) == 0 {
-		http.Error(w, "No contents found for the user", http.StatusNotFound)
-		return
-	}
-
-	// Set the response content type to JSON
-	w.Header().Set("Content-Type", "application/json")
-
-	// Return the fetched contents as JSON
-	json.NewEncoder(w).Encode(contents)
+	// Return the fetched contents as a page (empty page when the user has none)
+	writePage(w, r, contents, total, opts)
 }
 
 func ApproveContent(w http.ResponseWriter, r *http.Request) {
@@ -454,6 +500,9 @@ func GetContentViewCount(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Tambahkan view yang masih di buffer dan belum ditulis ke database
+	viewCount += viewModel.PendingViews(int64(contentID))
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]int{"viewCount": viewCount})
 }
@@ -491,6 +540,8 @@ func ResubmitRejectedContent(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// IncrementViewCount tetap tersedia untuk client lama, tetapi kini hanya mencatat
+// view unik yang sama seperti GetContentByID sehingga refresh tidak menambah hitungan
 func IncrementViewCount(response http.ResponseWriter, request *http.Request) {
 	response.Header().Set("Content-Type", "application/json")
 
@@ -505,15 +556,21 @@ func IncrementViewCount(response http.ResponseWriter, request *http.Request) {
 		return
 	}
 
-	// Increment view count
-	err = contentModel.IncrementViewCount(int(id))
+	content, _, _, err := contentModel.FindByIDWithAuthorName(id)
 	if err != nil {
 		http.Error(response, "Failed to increment view count", http.StatusInternalServerError)
 		return
 	}
+	if content == nil {
+		http.Error(response, "Content not found", http.StatusNotFound)
+		return
+	}
+
+	recordContentView(request, content)
 
 	response.WriteHeader(http.StatusOK)
 	json.NewEncoder(response).Encode(map[string]string{
 		"message": "View count incremented successfully",
 	})
 }
+
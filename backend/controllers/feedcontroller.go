@@ -0,0 +1,295 @@
+package controllers
+
+import (
+	"backend/config"
+	"backend/entities"
+	"encoding/xml"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Alamat frontend yang dipakai sebagai link artikel di sitemap dan feed
+var siteURL = config.Get().SiteURL
+
+// Jumlah item terbaru pada setiap feed
+const feedItemLimit = 50
+
+// Penulis tingkat feed Atom, dipakai untuk entri yang tidak punya penulis (RFC 4287 4.1.1)
+const feedAuthorName = "Wiki Pemda DIY"
+
+const dublinCoreNamespace = "http://purl.org/dc/elements/1.1/"
+
+// Sitemap dan feed boleh di-cache oleh crawler dan feed reader selama 10 menit
+const feedCacheControl = "public, max-age=600"
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type rssDocument struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	XmlnsDC string     `xml:"xmlns:dc,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []rssItem `xml:"item"`
+}
+
+// Nama penulis dikirim lewat dc:creator karena <author> RSS 2.0 harus berupa email
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        string   `xml:"guid"`
+	Description string   `xml:"description"`
+	Creator     string   `xml:"dc:creator,omitempty"`
+	Categories  []string `xml:"category"`
+	PubDate     string   `xml:"pubDate"`
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"feed"`
+	Xmlns   string      `xml:"xmlns,attr"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Author  atomAuthor  `xml:"author"`
+	Link    []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Link       atomLink       `xml:"link"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Author     *atomAuthor    `xml:"author,omitempty"`
+	Categories []atomCategory `xml:"category"`
+	Summary    atomText       `xml:"summary"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+func articleURL(id int64) string {
+	return fmt.Sprintf("%s/informasi/%d", siteURL, id)
+}
+
+// parseFeedTime membaca kolom DATETIME MySQL sebagai waktu WIB
+func parseFeedTime(value string) time.Time {
+	loc, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		loc = time.Local
+	}
+	parsed, err := time.ParseInLocation("2006-01-02 15:04:05", value, loc)
+	if err != nil {
+		return time.Time{}
+	}
+	return parsed
+}
+
+func latestTime(item entities.FeedItem) time.Time {
+	published, updated := parseFeedTime(item.PublishedAt), parseFeedTime(item.Updated_at)
+	if updated.After(published) {
+		return updated
+	}
+	return published
+}
+
+func feedTags(tag string) []string {
+	tags := []string{}
+	for _, part := range strings.Split(tag, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			tags = append(tags, part)
+		}
+	}
+	return tags
+}
+
+func writeXML(w http.ResponseWriter, contentType string, document interface{}) {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", feedCacheControl)
+	w.Write([]byte(xml.Header))
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(document); err != nil {
+		log.Printf("Error encoding XML: %v", err)
+	}
+}
+
+// feedQuery membaca filter instance_id dan tag dari query string
+func feedQuery(r *http.Request) (int64, string, bool) {
+	var instanceID int64
+	if value := r.URL.Query().Get("instance_id"); value != "" {
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return 0, "", false
+		}
+		instanceID = parsed
+	}
+	return instanceID, strings.TrimSpace(r.URL.Query().Get("tag")), true
+}
+
+func feedTitle(items []entities.FeedItem, instanceID int64, tag string) string {
+	title := "Wiki"
+	if instanceID != 0 && len(items) > 0 {
+		title += " - " + items[0].InstanceName
+	}
+	if tag != "" {
+		title += " - " + tag
+	}
+	return title
+}
+
+// GetSitemap menampilkan sitemap.xml berisi semua konten publik
+func GetSitemap(w http.ResponseWriter, r *http.Request) {
+	items, err := contentModel.FindPublicFeed(0, "", 0)
+	if err != nil {
+		log.Printf("Error building sitemap: %v", err)
+		http.Error(w, "Failed to build sitemap", http.StatusInternalServerError)
+		return
+	}
+
+	urlSet := sitemapURLSet{
+		Xmlns: "http://www.sitemaps.org/schemas/sitemap/0.9",
+		URLs:  []sitemapURL{{Loc: siteURL + "/"}},
+	}
+	for _, item := range items {
+		entry := sitemapURL{Loc: articleURL(item.Id)}
+		if modified := latestTime(item); !modified.IsZero() {
+			entry.LastMod = modified.Format("2006-01-02")
+		}
+		urlSet.URLs = append(urlSet.URLs, entry)
+	}
+
+	writeXML(w, "application/xml; charset=utf-8", urlSet)
+}
+
+// GetRSSFeed menampilkan feed RSS 2.0 konten publik, bisa difilter instance_id dan tag
+func GetRSSFeed(w http.ResponseWriter, r *http.Request) {
+	instanceID, tag, ok := feedQuery(r)
+	if !ok {
+		http.Error(w, "Invalid instance_id", http.StatusBadRequest)
+		return
+	}
+
+	items, err := contentModel.FindPublicFeed(instanceID, tag, feedItemLimit)
+	if err != nil {
+		log.Printf("Error building RSS feed: %v", err)
+		http.Error(w, "Failed to build feed", http.StatusInternalServerError)
+		return
+	}
+
+	channel := rssChannel{
+		Title:         feedTitle(items, instanceID, tag),
+		Link:          siteURL + "/",
+		Description:   "Artikel publik terbaru yang disetujui dan diperbarui",
+		LastBuildDate: time.Now().Format(time.RFC1123Z),
+		Items:         []rssItem{},
+	}
+	for _, item := range items {
+		channel.Items = append(channel.Items, rssItem{
+			Title:       item.Title,
+			Link:        articleURL(item.Id),
+			GUID:        articleURL(item.Id),
+			Description: item.Description,
+			Creator:     item.AuthorName,
+			Categories:  feedTags(item.Tag),
+			PubDate:     latestTime(item).Format(time.RFC1123Z),
+		})
+	}
+
+	writeXML(w, "application/rss+xml; charset=utf-8", rssDocument{Version: "2.0", XmlnsDC: dublinCoreNamespace, Channel: channel})
+}
+
+// GetAtomFeed menampilkan feed Atom konten publik, bisa difilter instance_id dan tag
+func GetAtomFeed(w http.ResponseWriter, r *http.Request) {
+	instanceID, tag, ok := feedQuery(r)
+	if !ok {
+		http.Error(w, "Invalid instance_id", http.StatusBadRequest)
+		return
+	}
+
+	items, err := contentModel.FindPublicFeed(instanceID, tag, feedItemLimit)
+	if err != nil {
+		log.Printf("Error building Atom feed: %v", err)
+		http.Error(w, "Failed to build feed", http.StatusInternalServerError)
+		return
+	}
+
+	updated := time.Now()
+	if len(items) > 0 {
+		updated = latestTime(items[0])
+	}
+
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	selfURL := scheme + "://" + r.Host + r.URL.RequestURI()
+
+	feed := atomFeed{
+		Xmlns:   "http://www.w3.org/2005/Atom",
+		ID:      selfURL,
+		Title:   feedTitle(items, instanceID, tag),
+		Updated: updated.Format(time.RFC3339),
+		Author:  atomAuthor{Name: feedAuthorName},
+		Link: []atomLink{
+			{Href: siteURL + "/"},
+			{Href: selfURL, Rel: "self"},
+		},
+		Entries: []atomEntry{},
+	}
+	for _, item := range items {
+		entry := atomEntry{
+			ID:        articleURL(item.Id),
+			Title:     item.Title,
+			Link:      atomLink{Href: articleURL(item.Id)},
+			Published: parseFeedTime(item.PublishedAt).Format(time.RFC3339),
+			Updated:   latestTime(item).Format(time.RFC3339),
+			Summary:   atomText{Type: "html", Body: item.Description},
+		}
+		if item.AuthorName != "" {
+			entry.Author = &atomAuthor{Name: item.AuthorName}
+		}
+		for _, term := range feedTags(item.Tag) {
+			entry.Categories = append(entry.Categories, atomCategory{Term: term})
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+
+	writeXML(w, "application/atom+xml; charset=utf-8", feed)
+}
@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"backend/internal/dbtest"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// expectPublicFeed mengembalikan satu artikel dengan penulis dan satu tanpa penulis
+func expectPublicFeed() {
+	dbtest.Mock.ExpectQuery("WHERE c.status = 'approved' AND c.accessibility = 'public'").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "description", "tag", "instance", "author",
+			"published_at", "updated_at"}).
+			AddRow(2, "Cuti Tahunan", "Panduan cuti", "cuti", "BKD", "Budi Santoso", "2024-05-01 10:00:00", "2024-05-02 10:00:00").
+			AddRow(1, "Mutasi", "Panduan mutasi", "mutasi", "BKD", "", "2024-04-01 10:00:00", "2024-04-01 10:00:00"))
+}
+
+func TestRSSFeedNamesAuthorsWithDublinCore(t *testing.T) {
+	expectPublicFeed()
+	recorder := httptest.NewRecorder()
+	GetRSSFeed(recorder, httptest.NewRequest(http.MethodGet, "/feeds/rss.xml", nil))
+
+	var feed struct {
+		Items []struct {
+			Creator string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+			Author  string `xml:"author"`
+		} `xml:"channel>item"`
+	}
+	if err := xml.Unmarshal(recorder.Body.Bytes(), &feed); err != nil {
+		t.Fatalf("invalid XML: %v\n%s", err, recorder.Body)
+	}
+	// <author> RSS 2.0 wajib berisi email, jadi nama tidak boleh dikirim di sana
+	if len(feed.Items) != 2 || feed.Items[0].Creator != "Budi Santoso" || feed.Items[0].Author != "" {
+		t.Errorf("items = %+v\n%s", feed.Items, recorder.Body)
+	}
+	if err := dbtest.Mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAtomFeedHasFeedLevelAuthor(t *testing.T) {
+	expectPublicFeed()
+	recorder := httptest.NewRecorder()
+	GetAtomFeed(recorder, httptest.NewRequest(http.MethodGet, "/feeds/atom.xml", nil))
+
+	var feed struct {
+		Author  string `xml:"http://www.w3.org/2005/Atom author>name"`
+		Entries []struct {
+			Author string `xml:"author>name"`
+		} `xml:"entry"`
+	}
+	if err := xml.Unmarshal(recorder.Body.Bytes(), &feed); err != nil {
+		t.Fatalf("invalid XML: %v\n%s", err, recorder.Body)
+	}
+	// Entri tanpa penulis hanya sah jika feed punya <author> sendiri (RFC 4287 4.1.1)
+	if strings.TrimSpace(feed.Author) == "" {
+		t.Errorf("feed has no author\n%s", recorder.Body)
+	}
+	if len(feed.Entries) != 2 || feed.Entries[0].Author != "Budi Santoso" || feed.Entries[1].Author != "" {
+		t.Errorf("entries = %+v", feed.Entries)
+	}
+	if err := dbtest.Mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
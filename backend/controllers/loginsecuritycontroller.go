@@ -0,0 +1,194 @@
+package controllers
+
+import (
+	"backend/entities"
+	middleware "backend/middlewares"
+	"backend/models"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+var loginSecurityModel = models.NewLoginSecurityModel()
+var notificationModel = models.NewNotificationModel()
+
+// Alasan yang dicatat pada audit percobaan login
+const (
+	loginReasonInvalid = "invalid_credentials"
+	loginReasonLocked  = "account_locked"
+	// Akun LDAP/SSO valid tetapi tidak dipetakan ke role dan instansi
+	loginReasonNotAllowed = "not_allowed"
+)
+
+// recordLoginAttempt mencatat percobaan login; kegagalan mencatat tidak menggagalkan login
+func recordLoginAttempt(request *http.Request, email string, userID int64, success bool, reason string) {
+	attempt := entities.LoginAttempt{
+		Email:     email,
+		IPAddress: middleware.ClientIP(request),
+		UserAgent: request.UserAgent(),
+		Success:   success,
+		Reason:    reason,
+	}
+	if userID != 0 {
+		attempt.UserID = &userID
+	}
+	if err := loginSecurityModel.RecordAttempt(attempt); err != nil {
+		log.Printf("Error recording login attempt for %s: %v", email, err)
+	}
+}
+
+// registerLoginFailure menambah hitungan gagal dan memberi tahu admin jika akun jadi terkunci
+func registerLoginFailure(email string, userID int64) {
+	if userID == 0 {
+		return
+	}
+
+	lockedUntil, lockoutCount, err := loginSecurityModel.RegisterFailure(userID)
+	if err != nil {
+		log.Printf("Error registering login failure for user %d: %v", userID, err)
+		return
+	}
+	if lockedUntil.IsZero() {
+		return
+	}
+
+	message := fmt.Sprintf("Akun %s dikunci sampai %s setelah %d kali gagal login berturut-turut (penguncian ke-%d)",
+		email, lockedUntil.Format("2006-01-02 15:04:05"), models.MaxFailedLogins, lockoutCount)
+	log.Println(message)
+	if err := notificationModel.Notify(models.NotificationAccountLocked, message, userID); err != nil {
+		log.Printf("Error notifying admins about locked account %d: %v", userID, err)
+	}
+}
+
+// sendAccountLocked menjawab langkah 2FA ke akun yang terkunci dengan 423 dan Retry-After.
+// Dipakai setelah password terbukti benar, sehingga status kunci tidak membocorkan akun.
+func sendAccountLocked(response http.ResponseWriter, lockedUntil time.Time) {
+	seconds := int(math.Ceil(time.Until(lockedUntil).Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+
+	response.Header().Set("Content-Type", "application/json")
+	response.Header().Set("Retry-After", strconv.Itoa(seconds))
+	response.WriteHeader(http.StatusLocked)
+	json.NewEncoder(response).Encode(map[string]interface{}{
+		"error":        "Account is temporarily locked due to too many failed login attempts",
+		"locked_until": lockedUntil.Format("2006-01-02 15:04:05"),
+	})
+}
+
+// GetLoginAttempts menampilkan riwayat percobaan login untuk admin, dengan
+// filter email, user_id, ip_address, success dan reason
+func GetLoginAttempts(w http.ResponseWriter, r *http.Request) {
+	opts, err := parseListOptions(r, models.LoginAttemptListSpec())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	attempts, total, err := loginSecurityModel.FindAttempts(opts)
+	if err != nil {
+		log.Printf("Error fetching login attempts: %v", err)
+		http.Error(w, "Failed to fetch login attempts", http.StatusInternalServerError)
+		return
+	}
+
+	writePage(w, r, attempts, total, opts)
+}
+
+// GetLockedAccounts menampilkan akun yang saat ini terkunci
+func GetLockedAccounts(w http.ResponseWriter, r *http.Request) {
+	accounts, err := loginSecurityModel.FindLocked()
+	if err != nil {
+		log.Printf("Error fetching locked accounts: %v", err)
+		http.Error(w, "Failed to fetch locked accounts", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(accounts)
+}
+
+// UnlockAccount membuka kunci akun dan mengatur ulang backoff-nya
+func UnlockAccount(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	unlocked, err := loginSecurityModel.Unlock(userID)
+	if err != nil {
+		log.Printf("Error unlocking user %d: %v", userID, err)
+		http.Error(w, "Failed to unlock account", http.StatusInternalServerError)
+		return
+	}
+	if !unlocked {
+		http.Error(w, "Account is not locked", http.StatusNotFound)
+		return
+	}
+
+	if claims, ok := r.Context().Value(middleware.UserContextKey).(*middleware.Claims); ok {
+		log.Printf("User %d unlocked account %d", claims.ID, userID)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Account unlocked successfully",
+		"user_id": userID,
+	})
+}
+
+// GetAdminNotifications menampilkan notifikasi keamanan terbaru; unread=true untuk yang belum dibaca saja
+func GetAdminNotifications(w http.ResponseWriter, r *http.Request) {
+	limit := 50
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		value, err := strconv.Atoi(limitStr)
+		if err != nil || value < 1 || value > 200 {
+			http.Error(w, "Parameter 'limit' must be between 1 and 200", http.StatusBadRequest)
+			return
+		}
+		limit = value
+	}
+
+	notifications, err := notificationModel.FindNotifications(r.URL.Query().Get("unread") == "true", limit)
+	if err != nil {
+		log.Printf("Error fetching notifications: %v", err)
+		http.Error(w, "Failed to fetch notifications", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(notifications)
+}
+
+// MarkNotificationRead menandai satu notifikasi admin sebagai sudah dibaca
+func MarkNotificationRead(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid notification ID", http.StatusBadRequest)
+		return
+	}
+
+	found, err := notificationModel.MarkRead(id)
+	if err != nil {
+		log.Printf("Error marking notification %d as read: %v", id, err)
+		http.Error(w, "Failed to update notification", http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, "Notification not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Notification marked as read"})
+}
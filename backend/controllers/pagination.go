@@ -0,0 +1,140 @@
+package controllers
+
+import (
+	"backend/models"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultPageLimit = 20
+const maxPageLimit = 100
+
+// Parameter query yang bukan filter
+var reservedListParams = map[string]bool{
+	"limit":  true,
+	"offset": true,
+	"cursor": true,
+	"sort":   true,
+	"order":  true,
+}
+
+// Page adalah envelope seragam untuk semua endpoint list
+type Page struct {
+	Items      interface{} `json:"items"`
+	Total      int         `json:"total"`
+	Limit      int         `json:"limit"`
+	Offset     int         `json:"offset"`
+	NextCursor *string     `json:"next_cursor"`
+}
+
+// parseListOptions membaca limit, offset/cursor, sort, order dan filter dari query string.
+// Contoh: ?limit=20&cursor=...&sort=-created_at&status=approved&q=pajak
+func parseListOptions(request *http.Request, spec models.ListSpec) (models.ListOptions, error) {
+	query := request.URL.Query()
+	opts := models.ListOptions{
+		Limit:   defaultPageLimit,
+		Filters: map[string]string{},
+	}
+
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 1 {
+			return opts, fmt.Errorf("invalid limit")
+		}
+		if limit > maxPageLimit {
+			limit = maxPageLimit
+		}
+		opts.Limit = limit
+	}
+
+	if cursor := query.Get("cursor"); cursor != "" {
+		offset, err := decodeCursor(cursor)
+		if err != nil {
+			return opts, fmt.Errorf("invalid cursor")
+		}
+		opts.Offset = offset
+	} else if offsetStr := query.Get("offset"); offsetStr != "" {
+		offset, err := strconv.Atoi(offsetStr)
+		if err != nil || offset < 0 {
+			return opts, fmt.Errorf("invalid offset")
+		}
+		opts.Offset = offset
+	}
+
+	// sort=-field berarti descending, bisa juga memakai order=desc
+	if sort := query.Get("sort"); sort != "" {
+		opts.SortBy = strings.TrimPrefix(sort, "-")
+		opts.Desc = strings.HasPrefix(sort, "-")
+	}
+	switch strings.ToLower(query.Get("order")) {
+	case "":
+	case "asc":
+		opts.Desc = false
+	case "desc":
+		opts.Desc = true
+	default:
+		return opts, fmt.Errorf("order must be 'asc' or 'desc'")
+	}
+
+	for key, values := range query {
+		if reservedListParams[key] || len(values) == 0 || values[0] == "" {
+			continue
+		}
+		opts.Filters[key] = values[0]
+	}
+
+	if err := opts.Validate(spec); err != nil {
+		return opts, err
+	}
+	return opts, nil
+}
+
+// writePage mengirim satu halaman list. ETag dihitung dari isi halaman sehingga
+// perubahan item maupun total menghasilkan ETag baru. Last-Modified sengaja tidak
+// dikirim: item yang keluar dari list (dihapus, ditolak) tidak meninggalkan
+// updated_at, sehingga hanya ETag yang bisa mendeteksinya.
+func writePage(response http.ResponseWriter, request *http.Request, items interface{}, total int, opts models.ListOptions) {
+	page := Page{
+		Items:  items,
+		Total:  total,
+		Limit:  opts.Limit,
+		Offset: opts.Offset,
+	}
+	if next := opts.Offset + opts.Limit; next < total {
+		cursor := encodeCursor(next)
+		page.NextCursor = &cursor
+	}
+
+	body, err := json.Marshal(page)
+	if err != nil {
+		http.Error(response, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+
+	response.Header().Set("Content-Type", "application/json")
+	if checkNotModified(response, request, makeETag(string(body)), time.Time{}) {
+		return
+	}
+	response.Write(append(body, '\n'))
+}
+
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("o:" + strconv.Itoa(offset)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, err
+	}
+	offset, err := strconv.Atoi(strings.TrimPrefix(string(raw), "o:"))
+	if err != nil || offset < 0 || !strings.HasPrefix(string(raw), "o:") {
+		return 0, fmt.Errorf("invalid cursor")
+	}
+	return offset, nil
+}
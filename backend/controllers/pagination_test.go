@@ -0,0 +1,43 @@
+package controllers
+
+import (
+	"backend/entities"
+	"backend/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// List hanya divalidasi dengan ETag: konten yang ditolak atau dihapus hilang dari
+// list tanpa menaikkan updated_at item yang tersisa, jadi If-Modified-Since tidak
+// boleh menghasilkan 304
+func TestWritePageUsesOnlyETag(t *testing.T) {
+	opts := models.ListOptions{Limit: 20}
+	before := []entities.Content{
+		{Id: 2, Title: "Cuti", Updated_at: "2024-05-01 10:00:00"},
+		{Id: 1, Title: "Mutasi", Updated_at: "2024-04-01 10:00:00"},
+	}
+	first := httptest.NewRecorder()
+	writePage(first, httptest.NewRequest(http.MethodGet, "/api/draft", nil), before, 2, opts)
+	if first.Header().Get("Last-Modified") != "" {
+		t.Errorf("Last-Modified = %q, want none for lists", first.Header().Get("Last-Modified"))
+	}
+	etag := first.Header().Get("ETag")
+
+	after := before[1:]
+	request := httptest.NewRequest(http.MethodGet, "/api/draft", nil)
+	request.Header.Set("If-Modified-Since", "Wed, 01 May 2024 03:00:00 GMT")
+	second := httptest.NewRecorder()
+	writePage(second, request, after, 1, opts)
+	if second.Code != http.StatusOK {
+		t.Fatalf("If-Modified-Since after removal: status = %d, want 200", second.Code)
+	}
+
+	request = httptest.NewRequest(http.MethodGet, "/api/draft", nil)
+	request.Header.Set("If-None-Match", etag)
+	third := httptest.NewRecorder()
+	writePage(third, request, before, 2, opts)
+	if third.Code != http.StatusNotModified {
+		t.Errorf("If-None-Match on unchanged page: status = %d, want 304", third.Code)
+	}
+}
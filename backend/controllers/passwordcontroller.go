@@ -0,0 +1,131 @@
+package controllers
+
+import (
+	"backend/config"
+	"backend/helpers"
+	middleware "backend/middlewares"
+	"encoding/json"
+	"log"
+	"net/http"
+)
+
+// checkNewPassword memeriksa password baru terhadap policy dan, untuk user yang
+// sudah ada (userID bukan 0), terhadap password-password sebelumnya. Pelanggaran
+// dikirim ke client sebagai 400 beserta daftar alasannya.
+func checkNewPassword(w http.ResponseWriter, userID int64, password string, personal ...string) bool {
+	problems := helpers.CheckPasswordPolicy(config.Get().PasswordPolicy, password, personal...)
+
+	if len(problems) == 0 && userID != 0 {
+		reused, err := userModel.PasswordReused(userID, password)
+		if err != nil {
+			log.Printf("Error checking password history of user %d: %v", userID, err)
+			http.Error(w, "Failed to check password", http.StatusInternalServerError)
+			return false
+		}
+		if reused {
+			problems = append(problems, "Password has been used recently, choose a different one")
+		}
+	}
+
+	if len(problems) > 0 {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":    problems[0],
+			"problems": problems,
+		})
+		return false
+	}
+	return true
+}
+
+// GetPasswordPolicy menampilkan aturan password agar frontend bisa menampilkannya di form
+func GetPasswordPolicy(w http.ResponseWriter, r *http.Request) {
+	policy := config.Get().PasswordPolicy
+	// Lokasi file di server tidak perlu diketahui client
+	policy.CommonPasswordsFile = ""
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(policy)
+}
+
+// ChangePassword mengganti password user yang sedang login. Password lama wajib
+// diisi; sesi di perangkat lain dicabut dan sesi ini menerima access token baru.
+func ChangePassword(w http.ResponseWriter, r *http.Request) {
+	claims, ok := loggedInClaims(w, r)
+	if !ok {
+		return
+	}
+	userID := int64(claims.ID)
+
+	var body struct {
+		CurrentPassword string `json:"current_password"`
+		NewPassword     string `json:"new_password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.CurrentPassword == "" || body.NewPassword == "" {
+		writeTokenError(w, http.StatusBadRequest, "Current and new password are required")
+		return
+	}
+
+	user, err := userModel.FindUserByID(userID)
+	if err != nil {
+		log.Printf("Error loading user %d: %v", userID, err)
+		writeTokenError(w, http.StatusInternalServerError, "Could not change password")
+		return
+	}
+	// Password akun LDAP/SSO diganti di sumber akunnya, bukan di wiki
+	if user.AuthProvider != "local" {
+		writeTokenError(w, http.StatusConflict, "Password of this account is managed by "+user.AuthProvider)
+		return
+	}
+
+	matches, err := userModel.CheckPassword(userID, body.CurrentPassword)
+	if err != nil {
+		log.Printf("Error checking password of user %d: %v", userID, err)
+		writeTokenError(w, http.StatusInternalServerError, "Could not change password")
+		return
+	}
+	if !matches {
+		writeTokenError(w, http.StatusForbidden, "Current password is incorrect")
+		return
+	}
+
+	if !checkNewPassword(w, userID, body.NewPassword, user.Name, user.Email) {
+		return
+	}
+
+	if err := userModel.ChangePassword(userID, body.NewPassword); err != nil {
+		log.Printf("Error changing password of user %d: %v", userID, err)
+		writeTokenError(w, http.StatusInternalServerError, "Could not change password")
+		return
+	}
+
+	revoked, err := sessionModel.RevokeAll(userID, claims.SessionID)
+	if err != nil {
+		log.Printf("Error revoking other sessions of user %d after password change: %v", userID, err)
+	}
+	middleware.ForgetSession(revoked...)
+	middleware.ForgetUser(claims.ID)
+	notifyPasswordChanged(userID)
+
+	// Versi token naik sehingga access token sesi ini juga harus diganti
+	user, err = userModel.FindUserByID(userID)
+	if err != nil {
+		log.Printf("Error reloading user %d after password change: %v", userID, err)
+		writeTokenError(w, http.StatusInternalServerError, "Password changed, please log in again")
+		return
+	}
+	accessToken, err := signAccessToken(user, claims.Permissions, claims.SessionID)
+	if err != nil {
+		log.Printf("Error signing access token for user %d: %v", userID, err)
+		writeTokenError(w, http.StatusInternalServerError, "Password changed, please log in again")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":    "Password changed successfully",
+		"token":      accessToken,
+		"expires_in": int(accessTokenTTL.Seconds()),
+		"revoked":    len(revoked),
+	})
+}
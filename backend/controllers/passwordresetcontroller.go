@@ -0,0 +1,171 @@
+package controllers
+
+import (
+	"backend/config"
+	"backend/helpers"
+	middleware "backend/middlewares"
+	"backend/models"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var passwordResetModel = models.NewPasswordResetModel()
+var mailSender helpers.MailSender = helpers.MailSenderFromConfig()
+
+const (
+	passwordResetTTL = 30 * time.Minute
+	// Satu alamat email paling banyak menerima satu email reset per menit
+	passwordResetCooldown = time.Minute
+)
+
+// RequestPasswordReset mengirim link reset password ke email user. Jawaban selalu
+// sama dan dikirim sebelum email diproses, sehingga tidak bisa dipakai untuk
+// menebak email mana yang terdaftar.
+func RequestPasswordReset(response http.ResponseWriter, request *http.Request) {
+	var body struct {
+		Email string `json:"email"`
+	}
+	if err := json.NewDecoder(request.Body).Decode(&body); err != nil || strings.TrimSpace(body.Email) == "" {
+		writeTokenError(response, http.StatusBadRequest, "Missing email")
+		return
+	}
+
+	go sendPasswordReset(strings.TrimSpace(body.Email), middleware.ClientIP(request))
+
+	response.Header().Set("Content-Type", "application/json")
+	response.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(response).Encode(map[string]string{
+		"message": "If the email is registered, a password reset link has been sent",
+	})
+}
+
+// sendPasswordReset membuat token reset dan mengirimkannya; kesalahan hanya dicatat di log
+func sendPasswordReset(email string, ipAddress string) {
+	userID, err := passwordResetModel.FindActiveUserByEmail(email)
+	if err != nil {
+		log.Printf("Error looking up user for password reset: %v", err)
+		return
+	}
+	if userID == 0 {
+		return
+	}
+
+	recent, err := passwordResetModel.RecentlyRequested(userID, passwordResetCooldown)
+	if err != nil {
+		log.Printf("Error checking password reset of user %d: %v", userID, err)
+		return
+	}
+	if recent {
+		return
+	}
+
+	token, err := randomHex(32)
+	if err != nil {
+		log.Printf("Error generating password reset token: %v", err)
+		return
+	}
+	if err := passwordResetModel.Create(userID, hashToken(token), ipAddress, passwordResetTTL); err != nil {
+		log.Printf("Error saving password reset of user %d: %v", userID, err)
+		return
+	}
+
+	link := config.Get().SiteURL + "/reset-password?token=" + url.QueryEscape(token)
+	err = mailSender.Send(helpers.Mail{
+		To:      email,
+		Subject: "Reset password Wiki",
+		Body: fmt.Sprintf("Kami menerima permintaan reset password untuk akun Wiki Anda.\n\n"+
+			"Buka link berikut dalam %d menit untuk membuat password baru:\n%s\n\n"+
+			"Link hanya bisa dipakai satu kali. Abaikan email ini jika Anda tidak memintanya.\n",
+			int(passwordResetTTL.Minutes()), link),
+	})
+	if err != nil {
+		log.Printf("Error sending password reset to user %d: %v", userID, err)
+	}
+}
+
+// ResetPassword mengganti password dengan token dari email. Semua sesi user
+// dicabut sehingga perangkat lain harus login ulang dengan password baru.
+func ResetPassword(response http.ResponseWriter, request *http.Request) {
+	var body struct {
+		Token    string `json:"token"`
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(request.Body).Decode(&body); err != nil || body.Token == "" {
+		writeTokenError(response, http.StatusBadRequest, "Missing reset token")
+		return
+	}
+
+	// Token diperiksa dulu tanpa dipakai agar password yang ditolak policy tidak menghanguskan link
+	tokenHash := hashToken(body.Token)
+	userID, err := passwordResetModel.FindUser(tokenHash)
+	if err != nil {
+		log.Printf("Error looking up password reset: %v", err)
+		writeTokenError(response, http.StatusInternalServerError, "Could not reset password")
+		return
+	}
+	if userID == 0 {
+		writeTokenError(response, http.StatusBadRequest, "Reset link is invalid or has expired")
+		return
+	}
+	user, err := userModel.FindUserByID(userID)
+	if err != nil {
+		log.Printf("Error loading user %d for password reset: %v", userID, err)
+		writeTokenError(response, http.StatusBadRequest, "Reset link is invalid or has expired")
+		return
+	}
+	if !checkNewPassword(response, userID, body.Password, user.Name, user.Email) {
+		return
+	}
+
+	userID, err = passwordResetModel.Reset(tokenHash, body.Password)
+	if err != nil {
+		log.Printf("Error resetting password: %v", err)
+		writeTokenError(response, http.StatusInternalServerError, "Could not reset password")
+		return
+	}
+	if userID == 0 {
+		writeTokenError(response, http.StatusBadRequest, "Reset link is invalid or has expired")
+		return
+	}
+
+	revoked, err := sessionModel.RevokeAll(userID, "")
+	if err != nil {
+		log.Printf("Error revoking sessions of user %d after password reset: %v", userID, err)
+	}
+	middleware.ForgetSession(revoked...)
+	middleware.ForgetUser(int(userID))
+	if err := loginSecurityModel.ResetFailures(userID); err != nil {
+		log.Printf("Error resetting login failures for user %d: %v", userID, err)
+	}
+	notifyPasswordChanged(userID)
+
+	response.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(response).Encode(map[string]string{
+		"message": "Password has been reset, please log in with your new password",
+	})
+}
+
+// notifyPasswordChanged memberi tahu pemilik akun bahwa password-nya baru saja diganti
+func notifyPasswordChanged(userID int64) {
+	user, err := userModel.FindUserByID(userID)
+	if err != nil {
+		log.Printf("Error loading user %d for password change notice: %v", userID, err)
+		return
+	}
+	go func() {
+		err := mailSender.Send(helpers.Mail{
+			To:      user.Email,
+			Subject: "Password Wiki Anda telah diganti",
+			Body: "Password akun Wiki Anda baru saja diganti dan sesi login di perangkat lain telah diakhiri.\n\n" +
+				"Jika bukan Anda yang melakukannya, segera hubungi admin.\n",
+		})
+		if err != nil {
+			log.Printf("Error sending password change notice to user %d: %v", userID, err)
+		}
+	}()
+}
@@ -0,0 +1,84 @@
+package controllers
+
+import (
+	"backend/models"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// Respons API publik boleh di-cache oleh proxy dan portal lain selama 5 menit
+const publicCacheControl = "public, max-age=300"
+
+// setPublicHeaders menambahkan header cache dan CORS terbuka untuk API publik
+func setPublicHeaders(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", publicCacheControl)
+	// Origin frontend sendiri sudah diisi oleh middleware CORS, origin lain boleh membaca
+	if w.Header().Get("Access-Control-Allow-Origin") == "" {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+	}
+}
+
+// GetPublicContents menampilkan daftar konten publik tanpa autentikasi,
+// dengan pagination, sort dan filter yang sama seperti endpoint list lainnya
+func GetPublicContents(w http.ResponseWriter, r *http.Request) {
+	opts, err := parseListOptions(r, models.ContentListSpec())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	contents, total, err := contentModel.FindPublic(opts)
+	if err != nil {
+		log.Printf("Error fetching public contents: %v", err)
+		http.Error(w, "Failed to fetch contents", http.StatusInternalServerError)
+		return
+	}
+
+	setPublicHeaders(w)
+	writePage(w, r, contents, total, opts)
+}
+
+// GetPublicContentByID menampilkan satu konten publik beserta subjudulnya tanpa autentikasi.
+// Konten yang belum disetujui, dihapus, atau tidak publik dijawab 404.
+func GetPublicContentByID(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid content ID", http.StatusBadRequest)
+		return
+	}
+
+	content, authorName, instanceName, err := contentModel.FindPublicByID(id)
+	if err != nil {
+		log.Printf("Error fetching public content %d: %v", id, err)
+		http.Error(w, "Failed to fetch content", http.StatusInternalServerError)
+		return
+	}
+	if content == nil {
+		http.Error(w, "Content not found", http.StatusNotFound)
+		return
+	}
+
+	subheadings, err := subheadingModel.FindByContentID(content.Id)
+	if err != nil {
+		http.Error(w, "Failed to fetch subheadings", http.StatusInternalServerError)
+		return
+	}
+
+	setPublicHeaders(w)
+	etag, lastModified := contentValidators(content, authorName, instanceName, subheadings)
+	if checkNotModified(w, r, etag, lastModified) {
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"content":       content,
+		"author_name":   authorName,
+		"instance_name": instanceName,
+		"subheadings":   subheadings,
+	})
+}
@@ -0,0 +1,164 @@
+package controllers
+
+import (
+	"backend/entities"
+	"backend/helpers"
+	middleware "backend/middlewares"
+	"backend/models"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Indeks rekomendasi dibangun ulang paling sering setiap relatedRefresh
+const relatedRefresh = 10 * time.Minute
+
+// Bobot tiap sinyal kemiripan; jumlahnya 1 sehingga skor berada di antara 0 dan 1
+const (
+	relatedTextWeight     = 0.6
+	relatedTagWeight      = 0.3
+	relatedInstanceWeight = 0.1
+)
+
+// RelatedContent adalah saran artikel "lihat juga" beserta skornya
+type RelatedContent struct {
+	Id         int64   `json:"id"`
+	Title      string  `json:"title"`
+	Tag        string  `json:"tag"`
+	InstanceID int64   `json:"instance_id"`
+	Score      float64 `json:"score"`
+}
+
+// relatedIndex adalah snapshot dokumen yang sudah ditokenisasi dan vektor TF-IDF-nya
+type relatedIndex struct {
+	documents map[int64]entities.ContentDocument
+	tags      map[int64]map[string]bool
+	tfidf     *helpers.TFIDFIndex
+	built     time.Time
+}
+
+var (
+	relatedIndexMu  sync.Mutex
+	relatedSnapshot *relatedIndex
+)
+
+func currentRelatedIndex() (*relatedIndex, error) {
+	relatedIndexMu.Lock()
+	defer relatedIndexMu.Unlock()
+
+	if relatedSnapshot != nil && time.Since(relatedSnapshot.built) < relatedRefresh {
+		return relatedSnapshot, nil
+	}
+
+	documents, err := contentModel.FindDocuments()
+	if err != nil {
+		if relatedSnapshot != nil {
+			log.Printf("Failed to rebuild related index, serving stale data: %v", err)
+			return relatedSnapshot, nil
+		}
+		return nil, err
+	}
+
+	index := &relatedIndex{
+		documents: make(map[int64]entities.ContentDocument, len(documents)),
+		tags:      make(map[int64]map[string]bool, len(documents)),
+		built:     time.Now(),
+	}
+	tokens := make(map[int64][]string, len(documents))
+	for _, document := range documents {
+		index.documents[document.Id] = document
+		index.tags[document.Id] = helpers.SplitTags(document.Tag)
+
+		// Judul dihitung dua kali karena paling mewakili isi artikel
+		titleTokens := helpers.Tokenize(document.Title)
+		words := append(append([]string{}, titleTokens...), titleTokens...)
+		words = append(words, helpers.Tokenize(document.Description)...)
+		words = append(words, helpers.Tokenize(document.Subheadings)...)
+		tokens[document.Id] = words
+	}
+	index.tfidf = helpers.NewTFIDFIndex(tokens)
+
+	relatedSnapshot = index
+	return relatedSnapshot, nil
+}
+
+// GetRelatedContents mengembalikan artikel terkait berdasarkan tag yang sama,
+// instansi yang sama dan kemiripan teks, hanya dari konten yang boleh dilihat pemanggil
+func GetRelatedContents(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	contentID, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid content ID", http.StatusBadRequest)
+		return
+	}
+
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*middleware.Claims)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	limit := 5
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		value, err := strconv.Atoi(limitStr)
+		if err != nil || value < 1 || value > 20 {
+			http.Error(w, "Parameter 'limit' must be between 1 and 20", http.StatusBadRequest)
+			return
+		}
+		limit = value
+	}
+
+	index, err := currentRelatedIndex()
+	if err != nil {
+		log.Printf("Error building related index: %v", err)
+		http.Error(w, "Failed to compute related content", http.StatusInternalServerError)
+		return
+	}
+
+	related := []RelatedContent{}
+	target, found := index.documents[contentID]
+	if found {
+		for id, candidate := range index.documents {
+			if id == contentID || !models.ContentVisibleTo(candidate.Accessibility, candidate.InstanceID, claims.InstanceID, claims.RoleID) {
+				continue
+			}
+
+			score := relatedTextWeight*index.tfidf.Similarity(contentID, id) +
+				relatedTagWeight*helpers.Jaccard(index.tags[contentID], index.tags[id])
+			if score == 0 {
+				continue
+			}
+			// Instansi yang sama hanya menambah skor, tidak cukup sendirian
+			if candidate.InstanceID == target.InstanceID {
+				score += relatedInstanceWeight
+			}
+
+			related = append(related, RelatedContent{
+				Id:         id,
+				Title:      candidate.Title,
+				Tag:        candidate.Tag,
+				InstanceID: candidate.InstanceID,
+				Score:      score,
+			})
+		}
+	}
+
+	sort.Slice(related, func(i, j int) bool {
+		if related[i].Score != related[j].Score {
+			return related[i].Score > related[j].Score
+		}
+		return related[i].Id > related[j].Id
+	})
+	if len(related) > limit {
+		related = related[:limit]
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(related)
+}
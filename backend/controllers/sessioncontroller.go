@@ -0,0 +1,194 @@
+package controllers
+
+import (
+	"backend/entities"
+	middleware "backend/middlewares"
+	"backend/models"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+var sessionModel = models.NewSessionModel()
+
+// describeDevice membuat label singkat perangkat dari User-Agent, mis. "Chrome di Windows"
+func describeDevice(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	if ua == "" {
+		return "Perangkat tidak dikenal"
+	}
+
+	browser := "Browser lain"
+	switch {
+	case strings.Contains(ua, "edg/"):
+		browser = "Edge"
+	case strings.Contains(ua, "opr/") || strings.Contains(ua, "opera"):
+		browser = "Opera"
+	case strings.Contains(ua, "firefox/"):
+		browser = "Firefox"
+	case strings.Contains(ua, "chrome/") || strings.Contains(ua, "crios/"):
+		browser = "Chrome"
+	case strings.Contains(ua, "safari/"):
+		browser = "Safari"
+	case strings.Contains(ua, "curl/") || strings.Contains(ua, "postman") || strings.Contains(ua, "go-http-client"):
+		return "Klien API"
+	}
+
+	system := "OS lain"
+	switch {
+	case strings.Contains(ua, "android"):
+		system = "Android"
+	case strings.Contains(ua, "iphone") || strings.Contains(ua, "ipad"):
+		system = "iOS"
+	case strings.Contains(ua, "windows"):
+		system = "Windows"
+	case strings.Contains(ua, "mac os"):
+		system = "macOS"
+	case strings.Contains(ua, "linux"):
+		system = "Linux"
+	}
+	return browser + " di " + system
+}
+
+// loggedInClaims mengambil claims user yang login; token tamu ditolak dengan 403
+func loggedInClaims(w http.ResponseWriter, r *http.Request) (*middleware.Claims, bool) {
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*middleware.Claims)
+	if !ok || claims.ID == 0 {
+		http.Error(w, "This endpoint requires a logged-in user", http.StatusForbidden)
+		return nil, false
+	}
+	return claims, true
+}
+
+// findSessions mengambil sesi aktif user; sesi yang tidak dipakai lebih lama
+// dari umur refresh token sudah tidak bisa diperpanjang sehingga tidak ditampilkan
+func findSessions(w http.ResponseWriter, userID int64, currentID string) ([]entities.Session, bool) {
+	sessions, err := sessionModel.FindActiveByUser(userID, refreshTokenTTL)
+	if err != nil {
+		log.Printf("Error fetching sessions of user %d: %v", userID, err)
+		http.Error(w, "Failed to fetch sessions", http.StatusInternalServerError)
+		return nil, false
+	}
+	for i := range sessions {
+		sessions[i].Current = currentID != "" && sessions[i].Id == currentID
+	}
+	return sessions, true
+}
+
+// GetMySessions menampilkan perangkat tempat user sedang login
+func GetMySessions(w http.ResponseWriter, r *http.Request) {
+	claims, ok := loggedInClaims(w, r)
+	if !ok {
+		return
+	}
+
+	sessions, ok := findSessions(w, int64(claims.ID), claims.SessionID)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sessions)
+}
+
+// RevokeMySession mengeluarkan user dari satu perangkat
+func RevokeMySession(w http.ResponseWriter, r *http.Request) {
+	claims, ok := loggedInClaims(w, r)
+	if !ok {
+		return
+	}
+
+	sessionID := mux.Vars(r)["id"]
+	revoked, err := sessionModel.Revoke(int64(claims.ID), sessionID)
+	if err != nil {
+		log.Printf("Error revoking session %s: %v", sessionID, err)
+		http.Error(w, "Failed to revoke session", http.StatusInternalServerError)
+		return
+	}
+	if !revoked {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+	middleware.ForgetSession(sessionID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Session revoked successfully"})
+}
+
+// RevokeOtherSessions mengeluarkan user dari semua perangkat selain yang sedang dipakai
+func RevokeOtherSessions(w http.ResponseWriter, r *http.Request) {
+	claims, ok := loggedInClaims(w, r)
+	if !ok {
+		return
+	}
+
+	revoked, err := sessionModel.RevokeAll(int64(claims.ID), claims.SessionID)
+	if err != nil {
+		log.Printf("Error revoking other sessions of user %d: %v", claims.ID, err)
+		http.Error(w, "Failed to revoke sessions", http.StatusInternalServerError)
+		return
+	}
+	middleware.ForgetSession(revoked...)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Other sessions revoked successfully",
+		"revoked": len(revoked),
+	})
+}
+
+// GetUserSessions menampilkan sesi aktif user tertentu untuk admin
+func GetUserSessions(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	currentID := ""
+	if claims, ok := r.Context().Value(middleware.UserContextKey).(*middleware.Claims); ok && int64(claims.ID) == userID {
+		currentID = claims.SessionID
+	}
+
+	sessions, ok := findSessions(w, userID, currentID)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sessions)
+}
+
+// RevokeUserSessions mengeluarkan user tertentu dari semua perangkat
+func RevokeUserSessions(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	revoked, err := sessionModel.RevokeAll(userID, "")
+	if err != nil {
+		log.Printf("Error revoking sessions of user %d: %v", userID, err)
+		http.Error(w, "Failed to revoke sessions", http.StatusInternalServerError)
+		return
+	}
+	middleware.ForgetSession(revoked...)
+
+	if claims, ok := r.Context().Value(middleware.UserContextKey).(*middleware.Claims); ok {
+		log.Printf("User %d revoked %d sessions of user %d", claims.ID, len(revoked), userID)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Sessions revoked successfully",
+		"user_id": userID,
+		"revoked": len(revoked),
+	})
+}
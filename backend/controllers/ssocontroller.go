@@ -0,0 +1,236 @@
+package controllers
+
+import (
+	"backend/config"
+	"backend/helpers"
+	middleware "backend/middlewares"
+	"backend/models"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+var ssoModel = models.NewSSOModel()
+
+// oidcClient bernilai nil jika SSO tidak diaktifkan di konfigurasi
+var oidcClient = newOIDCClient()
+
+const (
+	oidcLoginTTL    = 10 * time.Minute
+	ssoLoginCodeTTL = 2 * time.Minute
+	// Cookie state mengikat callback ke browser yang memulai login (mencegah login CSRF)
+	oidcStateCookie = "oidc_state"
+	oidcCookiePath  = "/api/sso/oidc"
+)
+
+func newOIDCClient() *helpers.OIDCClient {
+	cfg := config.Get().OIDC
+	if !cfg.Enabled() {
+		return nil
+	}
+	return helpers.NewOIDCClient(cfg)
+}
+
+// GetSSOProviders memberi tahu frontend apakah tombol login SSO perlu ditampilkan
+func GetSSOProviders(w http.ResponseWriter, r *http.Request) {
+	cfg := config.Get().OIDC
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"oidc": map[string]interface{}{
+			"enabled": cfg.Enabled(),
+			"name":    cfg.DisplayName,
+		},
+	})
+}
+
+// redirectSSOError mengembalikan browser ke halaman login frontend dengan kode kesalahan
+func redirectSSOError(w http.ResponseWriter, r *http.Request, reason string) {
+	http.Redirect(w, r, config.Get().SiteURL+"/login?sso_error="+url.QueryEscape(reason), http.StatusFound)
+}
+
+func setOIDCStateCookie(w http.ResponseWriter, value string, maxAge int) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    value,
+		Path:     oidcCookiePath,
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   config.Get().IsProduction(),
+		// Lax tetap dikirim saat identity provider mengarahkan browser kembali ke callback
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// StartOIDCLogin membuat state, nonce dan code_verifier PKCE lalu mengarahkan
+// browser ke halaman login identity provider
+func StartOIDCLogin(w http.ResponseWriter, r *http.Request) {
+	if oidcClient == nil {
+		http.Error(w, "SSO is not enabled", http.StatusNotFound)
+		return
+	}
+
+	state, err := randomHex(32)
+	if err != nil {
+		redirectSSOError(w, r, "failed")
+		return
+	}
+	nonce, err := randomHex(16)
+	if err != nil {
+		redirectSSOError(w, r, "failed")
+		return
+	}
+	verifier, err := helpers.GeneratePKCEVerifier()
+	if err != nil {
+		redirectSSOError(w, r, "failed")
+		return
+	}
+	if err := ssoModel.CreateLogin(hashToken(state), nonce, verifier, oidcLoginTTL); err != nil {
+		log.Printf("Error starting SSO login: %v", err)
+		redirectSSOError(w, r, "failed")
+		return
+	}
+
+	authURL, err := oidcClient.AuthCodeURL(state, nonce, helpers.PKCEChallenge(verifier))
+	if err != nil {
+		log.Printf("Error building SSO login URL: %v", err)
+		redirectSSOError(w, r, "unavailable")
+		return
+	}
+
+	setOIDCStateCookie(w, state, int(oidcLoginTTL.Seconds()))
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// OIDCCallback menerima authorization code dari identity provider, memverifikasi
+// ID token, membuat atau memperbarui user, lalu mengarahkan browser ke frontend
+// dengan kode sekali pakai untuk ditukar dengan token wiki
+func OIDCCallback(w http.ResponseWriter, r *http.Request) {
+	if oidcClient == nil {
+		http.Error(w, "SSO is not enabled", http.StatusNotFound)
+		return
+	}
+
+	query := r.URL.Query()
+	if providerError := query.Get("error"); providerError != "" {
+		log.Printf("SSO login rejected by identity provider: %s %s", providerError, query.Get("error_description"))
+		reason := "failed"
+		if providerError == "access_denied" {
+			reason = "denied"
+		}
+		redirectSSOError(w, r, reason)
+		return
+	}
+
+	state := query.Get("state")
+	cookie, err := r.Cookie(oidcStateCookie)
+	setOIDCStateCookie(w, "", -1)
+	if err != nil || state == "" || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(state)) != 1 {
+		redirectSSOError(w, r, "invalid_state")
+		return
+	}
+
+	nonce, verifier, ok, err := ssoModel.TakeLogin(hashToken(state))
+	if err != nil {
+		log.Printf("Error looking up SSO login: %v", err)
+		redirectSSOError(w, r, "failed")
+		return
+	}
+	if !ok {
+		redirectSSOError(w, r, "expired")
+		return
+	}
+
+	idToken, err := oidcClient.Exchange(query.Get("code"), verifier)
+	if err != nil {
+		log.Printf("Error exchanging SSO authorization code: %v", err)
+		redirectSSOError(w, r, "failed")
+		return
+	}
+	claims, err := oidcClient.VerifyIDToken(idToken, nonce)
+	if err != nil {
+		log.Printf("Error verifying SSO ID token: %v", err)
+		redirectSSOError(w, r, "failed")
+		return
+	}
+	external, err := oidcClient.ExternalUser(claims)
+	if errors.Is(err, helpers.ErrOIDCEmailNotVerified) {
+		redirectSSOError(w, r, "email_not_verified")
+		return
+	}
+	if err != nil {
+		log.Printf("Error reading SSO user claims: %v", err)
+		redirectSSOError(w, r, "failed")
+		return
+	}
+
+	cfg := config.Get().OIDC
+	user, err := userModel.ProvisionMappedUser(*external, cfg.RoleMappings, cfg.DefaultRoleID, cfg.DefaultInstanceID,
+		cfg.LinkExistingAccounts)
+	if errors.Is(err, models.ErrExternalLinkNotAllowed) {
+		recordLoginAttempt(r, external.Email, 0, false, loginReasonNotAllowed)
+		redirectSSOError(w, r, "link_not_allowed")
+		return
+	}
+	if errors.Is(err, models.ErrExternalUserNotAllowed) {
+		recordLoginAttempt(r, external.Email, 0, false, loginReasonNotAllowed)
+		redirectSSOError(w, r, "not_allowed")
+		return
+	}
+	if err != nil {
+		log.Printf("Error provisioning SSO user %s: %v", external.Email, err)
+		redirectSSOError(w, r, "failed")
+		return
+	}
+	// Role atau instansi mungkin baru disinkronkan dari identity provider
+	middleware.ForgetUser(int(user.Id))
+
+	code, err := randomHex(32)
+	if err != nil {
+		redirectSSOError(w, r, "failed")
+		return
+	}
+	if err := ssoModel.CreateLoginCode(user.Id, hashToken(code), ssoLoginCodeTTL); err != nil {
+		log.Printf("Error saving SSO login code for user %d: %v", user.Id, err)
+		redirectSSOError(w, r, "failed")
+		return
+	}
+	http.Redirect(w, r, config.Get().SiteURL+"/login?sso_code="+url.QueryEscape(code), http.StatusFound)
+}
+
+// ExchangeSSOCode menukar kode dari callback SSO dengan token wiki, sama seperti
+// hasil Login. Langkah 2FA tetap berlaku untuk akun yang memakainya.
+func ExchangeSSOCode(response http.ResponseWriter, request *http.Request) {
+	var body struct {
+		Code string `json:"code"`
+	}
+	if err := json.NewDecoder(request.Body).Decode(&body); err != nil || body.Code == "" {
+		writeTokenError(response, http.StatusBadRequest, "Missing SSO login code")
+		return
+	}
+
+	userID, err := ssoModel.UseLoginCode(hashToken(body.Code))
+	if err != nil {
+		log.Printf("Error using SSO login code: %v", err)
+		writeTokenError(response, http.StatusInternalServerError, "Could not complete SSO login")
+		return
+	}
+	if userID == 0 {
+		writeTokenError(response, http.StatusUnauthorized, "SSO login has expired, please try again")
+		return
+	}
+
+	user, err := userModel.FindUserByID(userID)
+	if err != nil {
+		writeTokenError(response, http.StatusUnauthorized, "User is no longer active")
+		return
+	}
+
+	if beginTwoFactor(response, user) {
+		return
+	}
+	completeLogin(response, request, user, nil)
+}
@@ -0,0 +1,266 @@
+package controllers
+
+import (
+	"backend/config"
+	"backend/helpers"
+	"backend/internal/dbtest"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ssoTestIdP adalah identity provider tiruan untuk alur callback: token endpoint
+// hanya menukar code yang diberikan dengan code_verifier yang cocok dengan
+// code_challenge dari halaman login, lalu mengembalikan ID token untuk nonce-nya.
+type ssoTestIdP struct {
+	server    *httptest.Server
+	key       *rsa.PrivateKey
+	code      string
+	challenge string
+	nonce     string
+}
+
+func startSSOTestIdP(t *testing.T) *ssoTestIdP {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idp := &ssoTestIdP{key: key, code: "kode-otorisasi"}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.server.URL,
+			"authorization_endpoint": idp.server.URL + "/authorize",
+			"token_endpoint":         idp.server.URL + "/token",
+			"jwks_uri":               idp.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kid": "kunci-1",
+				"kty": "RSA",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.FormValue("code") != idp.code || helpers.PKCEChallenge(r.FormValue("code_verifier")) != idp.challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		now := time.Now()
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+			"iss":            idp.server.URL,
+			"sub":            "3f0c9a52-budi",
+			"aud":            "wiki",
+			"exp":            now.Add(5 * time.Minute).Unix(),
+			"iat":            now.Unix(),
+			"nonce":          idp.nonce,
+			"email":          "budi.santoso@jogjaprov.go.id",
+			"email_verified": true,
+			"name":           "Budi Santoso",
+			"groups":         []string{"wiki-editor"},
+		})
+		token.Header["kid"] = "kunci-1"
+		signed, _ := token.SignedString(key)
+		json.NewEncoder(w).Encode(map[string]string{"id_token": signed})
+	})
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+// useSSOTestIdP mengaktifkan SSO ke identity provider tiruan selama test berjalan
+func useSSOTestIdP(t *testing.T, idp *ssoTestIdP) {
+	cfg := config.OIDCConfig{
+		IssuerURL:            idp.server.URL,
+		ClientID:             "wiki",
+		RedirectURL:          "http://localhost:3000/api/sso/oidc/callback",
+		EmailClaim:           "email",
+		NameClaim:            "name",
+		GroupsClaim:          "groups",
+		RequireVerifiedEmail: true,
+		RoleMappings:         []config.RoleMapping{{Group: "wiki-editor", RoleID: 2}},
+		DefaultInstanceID:    3,
+		TimeoutSeconds:       5,
+	}
+	previous := config.Get().OIDC
+	config.Get().OIDC = cfg
+	oidcClient = helpers.NewOIDCClient(cfg)
+	t.Cleanup(func() {
+		config.Get().OIDC = previous
+		oidcClient = newOIDCClient()
+		if err := dbtest.Mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func ssoUserRow(id, roleID, instanceID int64, provider string) *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id", "name", "nip", "email", "role_id", "instance_id",
+		"token_version", "auth_provider", "role", "instance"}).
+		AddRow(id, "Budi Santoso", 0, "budi.santoso@jogjaprov.go.id", roleID, instanceID, 0, provider, "Editor", "Dinas Kominfo")
+}
+
+// expectTakeLogin menjawab pemakaian state login SSO dengan nonce dan code_verifier-nya
+func expectTakeLogin(stateHash interface{}, nonce, verifier string) {
+	dbtest.Mock.ExpectExec("UPDATE oidc_logins SET used_at").WithArgs(sqlmock.AnyArg(), stateHash, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	dbtest.Mock.ExpectQuery("SELECT nonce, code_verifier FROM oidc_logins").WithArgs(stateHash).
+		WillReturnRows(sqlmock.NewRows([]string{"nonce", "code_verifier"}).AddRow(nonce, verifier))
+}
+
+func TestOIDCLoginCallbackAndExchange(t *testing.T) {
+	idp := startSSOTestIdP(t)
+	useSSOTestIdP(t, idp)
+	mock := dbtest.Mock
+
+	// 1. Login dimulai: state, nonce dan code_verifier disimpan, browser ke identity provider
+	stateHash, nonce, verifier := &dbtest.Capture{}, &dbtest.Capture{}, &dbtest.Capture{}
+	mock.ExpectExec("INSERT INTO oidc_logins").WithArgs(stateHash, nonce, verifier, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	recorder := httptest.NewRecorder()
+	StartOIDCLogin(recorder, httptest.NewRequest(http.MethodGet, "/api/sso/oidc/login", nil))
+	if recorder.Code != http.StatusFound {
+		t.Fatalf("login status = %d, want 302", recorder.Code)
+	}
+	authURL, _ := url.Parse(recorder.Header().Get("Location"))
+	authQuery := authURL.Query()
+	state := authQuery.Get("state")
+	if !strings.HasPrefix(authURL.String(), idp.server.URL+"/authorize?") || hashToken(state) != stateHash.Value ||
+		authQuery.Get("nonce") != nonce.Value || authQuery.Get("code_challenge") != helpers.PKCEChallenge(verifier.Value) {
+		t.Fatalf("authorization URL %s does not match the saved login", authURL)
+	}
+	cookies := recorder.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != oidcStateCookie || cookies[0].Value != state || !cookies[0].HttpOnly {
+		t.Fatalf("state cookie = %+v", cookies)
+	}
+
+	// 2. Identity provider mengarahkan kembali ke callback dengan code
+	idp.challenge, idp.nonce = authQuery.Get("code_challenge"), authQuery.Get("nonce")
+	expectTakeLogin(stateHash.Value, nonce.Value, verifier.Value)
+	mock.ExpectBegin()
+	mock.ExpectQuery("FROM user_identities").WithArgs("oidc", "3f0c9a52-budi").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "deleted", "auth_provider", "external_id"}))
+	mock.ExpectQuery("FROM user WHERE email").WithArgs("budi.santoso@jogjaprov.go.id").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "deleted", "auth_provider", "external_id", "external_link_allowed"}))
+	mock.ExpectExec("INSERT INTO user ").
+		WithArgs("Budi Santoso", 0, "budi.santoso@jogjaprov.go.id", 2, 3, "oidc", "3f0c9a52-budi").
+		WillReturnResult(sqlmock.NewResult(41, 1))
+	mock.ExpectExec("INSERT INTO user_identities").WithArgs(41, "oidc", "3f0c9a52-budi", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("WHERE u.id = ?").WithArgs(41).WillReturnRows(ssoUserRow(41, 2, 3, "oidc"))
+	mock.ExpectCommit()
+	codeHash := &dbtest.Capture{}
+	mock.ExpectExec("INSERT INTO sso_login_codes").WithArgs(codeHash, 41, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	callback := httptest.NewRequest(http.MethodGet, "/api/sso/oidc/callback?"+url.Values{
+		"code": {idp.code}, "state": {state}}.Encode(), nil)
+	callback.AddCookie(cookies[0])
+	recorder = httptest.NewRecorder()
+	OIDCCallback(recorder, callback)
+	location, _ := url.Parse(recorder.Header().Get("Location"))
+	code := location.Query().Get("sso_code")
+	if recorder.Code != http.StatusFound || location.Path != "/login" || code == "" || hashToken(code) != codeHash.Value {
+		t.Fatalf("callback answered %d %s", recorder.Code, location)
+	}
+
+	// 3. Frontend menukar kode sekali pakai dengan token wiki
+	mock.ExpectExec("UPDATE sso_login_codes SET used_at").WithArgs(sqlmock.AnyArg(), codeHash.Value, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT user_id FROM sso_login_codes").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(41))
+	mock.ExpectQuery("WHERE u.id = ?").WithArgs(41).WillReturnRows(ssoUserRow(41, 2, 3, "oidc"))
+	mock.ExpectQuery("FROM user_totp").WillReturnRows(sqlmock.NewRows([]string{"user_id", "secret", "enabled_at", "last_used_step"}))
+	mock.ExpectQuery("FROM role_permissions rp").WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"required"}).AddRow(false))
+	mock.ExpectExec("INSERT INTO login_attempts").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("DELETE FROM account_lockouts").WithArgs(41).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("FROM permissions p").WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "view_content").AddRow(2, "edit_content"))
+	mock.ExpectExec("INSERT INTO sessions").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO refresh_tokens").WillReturnResult(sqlmock.NewResult(1, 1))
+
+	recorder = httptest.NewRecorder()
+	ExchangeSSOCode(recorder, httptest.NewRequest(http.MethodPost, "/api/sso/exchange",
+		strings.NewReader(`{"code":"`+code+`"}`)))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("exchange status = %d: %s", recorder.Code, recorder.Body)
+	}
+	var body struct {
+		ID           int64    `json:"id"`
+		RoleID       int64    `json:"role_id"`
+		InstanceID   int64    `json:"instance_id"`
+		Permissions  []string `json:"permissions"`
+		Token        string   `json:"token"`
+		RefreshToken string   `json:"refresh_token"`
+	}
+	if err := json.NewDecoder(recorder.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if body.ID != 41 || body.RoleID != 2 || body.InstanceID != 3 || len(body.Permissions) != 2 ||
+		body.Token == "" || body.RefreshToken == "" {
+		t.Errorf("exchange body = %+v", body)
+	}
+}
+
+func TestOIDCCallbackRefusesToTakeOverExistingAccount(t *testing.T) {
+	idp := startSSOTestIdP(t)
+	useSSOTestIdP(t, idp)
+	mock := dbtest.Mock
+
+	idp.challenge, idp.nonce = helpers.PKCEChallenge("verifier-acak"), "nonce-acak"
+	expectTakeLogin(hashToken("state-acak"), "nonce-acak", "verifier-acak")
+	mock.ExpectBegin()
+	mock.ExpectQuery("FROM user_identities").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "deleted", "auth_provider", "external_id"}))
+	// Akun admin lokal dengan email yang sama dan belum diizinkan untuk ditautkan
+	mock.ExpectQuery("FROM user WHERE email").WithArgs("budi.santoso@jogjaprov.go.id").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "deleted", "auth_provider", "external_id", "external_link_allowed"}).
+			AddRow(7, false, "local", "", false))
+	mock.ExpectRollback()
+	mock.ExpectExec("INSERT INTO login_attempts").
+		WithArgs("budi.santoso@jogjaprov.go.id", nil, sqlmock.AnyArg(), sqlmock.AnyArg(), false, loginReasonNotAllowed, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	callback := httptest.NewRequest(http.MethodGet, "/api/sso/oidc/callback?code=kode-otorisasi&state=state-acak", nil)
+	callback.AddCookie(&http.Cookie{Name: oidcStateCookie, Value: "state-acak"})
+	recorder := httptest.NewRecorder()
+	OIDCCallback(recorder, callback)
+
+	location, _ := url.Parse(recorder.Header().Get("Location"))
+	if recorder.Code != http.StatusFound || location.Query().Get("sso_error") != "link_not_allowed" {
+		t.Fatalf("callback answered %d %s, want sso_error=link_not_allowed", recorder.Code, location)
+	}
+}
+
+func TestOIDCCallbackRejectsStateWithoutCookie(t *testing.T) {
+	idp := startSSOTestIdP(t)
+	useSSOTestIdP(t, idp)
+
+	recorder := httptest.NewRecorder()
+	OIDCCallback(recorder, httptest.NewRequest(http.MethodGet, "/api/sso/oidc/callback?code=kode-otorisasi&state=state-acak", nil))
+
+	location, _ := url.Parse(recorder.Header().Get("Location"))
+	if location.Query().Get("sso_error") != "invalid_state" {
+		t.Fatalf("callback answered %d %s, want sso_error=invalid_state", recorder.Code, location)
+	}
+}
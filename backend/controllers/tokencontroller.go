@@ -0,0 +1,312 @@
+package controllers
+
+import (
+	"backend/entities"
+	"backend/helpers"
+	middleware "backend/middlewares"
+	"backend/models"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var refreshTokenModel = models.NewRefreshTokenModel()
+
+// Access token berumur pendek; sesi diperpanjang lewat refresh token yang dirotasi,
+// tetapi tidak lebih dari sessionMaxLifetime sejak login
+const (
+	accessTokenTTL     = 15 * time.Minute
+	refreshTokenTTL    = 30 * 24 * time.Hour
+	sessionMaxLifetime = 90 * 24 * time.Hour
+)
+
+// tokenPair adalah pasangan token yang dikirim ke client setelah login atau refresh
+type tokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    int
+}
+
+// signAccessToken membuat access token terenkripsi untuk user. sessionID adalah
+// family refresh token sehingga token bisa dikaitkan dengan sesi login-nya.
+func signAccessToken(user *entities.UserDetail, permissions []string, sessionID string) (string, error) {
+	claims := jwt.MapClaims{
+		"id":          user.Id,
+		"role":        user.RoleName,
+		"role_id":     user.Role_Id,
+		"permissions": permissions,
+		"instance_id": user.Instance_Id,
+		"sid":         sessionID,
+		"ver":         user.TokenVersion,
+		"exp":         time.Now().Add(accessTokenTTL).Unix(),
+	}
+
+	// Token ditandatangani dan dienkripsi dengan kunci aktif di keyring
+	return helpers.IssueToken(claims)
+}
+
+func randomHex(size int) (string, error) {
+	buffer := make([]byte, size)
+	if _, err := rand.Read(buffer); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buffer), nil
+}
+
+// newRefreshToken membuat refresh token acak beserta hash yang disimpan di database
+func newRefreshToken() (string, string, error) {
+	buffer := make([]byte, 32)
+	if _, err := rand.Read(buffer); err != nil {
+		return "", "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(buffer)
+	return token, hashToken(token), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// startSession mencatat sesi baru untuk perangkat pemanggil, membuka family
+// refresh token dengan id yang sama dan menerbitkan token pertamanya
+func startSession(request *http.Request, user *entities.UserDetail, permissions []string) (tokenPair, error) {
+	familyID, err := randomHex(16)
+	if err != nil {
+		return tokenPair{}, err
+	}
+
+	err = sessionModel.Create(entities.Session{
+		Id:        familyID,
+		UserID:    user.Id,
+		Device:    describeDevice(request.UserAgent()),
+		UserAgent: request.UserAgent(),
+		IPAddress: middleware.ClientIP(request),
+	})
+	if err != nil {
+		return tokenPair{}, err
+	}
+	refreshToken, refreshHash, err := newRefreshToken()
+	if err != nil {
+		return tokenPair{}, err
+	}
+
+	_, err = refreshTokenModel.Create(entities.RefreshToken{
+		UserID:    user.Id,
+		FamilyID:  familyID,
+		TokenHash: refreshHash,
+	}, refreshTokenTTL)
+	if err != nil {
+		return tokenPair{}, err
+	}
+
+	accessToken, err := signAccessToken(user, permissions, familyID)
+	if err != nil {
+		return tokenPair{}, err
+	}
+	return tokenPair{AccessToken: accessToken, RefreshToken: refreshToken, ExpiresIn: int(accessTokenTTL.Seconds())}, nil
+}
+
+func permissionNames(roleID int64) ([]string, error) {
+	permissions, err := permissionModel.GetPermissionsByRole(roleID)
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, permission := range permissions {
+		names = append(names, permission.Name)
+	}
+	return names, nil
+}
+
+func writeTokenError(response http.ResponseWriter, status int, message string) {
+	response.Header().Set("Content-Type", "application/json")
+	response.WriteHeader(status)
+	json.NewEncoder(response).Encode(map[string]string{
+		"error": message,
+	})
+}
+
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// RefreshToken menukar refresh token dengan access token dan refresh token baru.
+// Refresh token yang sudah pernah dipakai dianggap dicuri: seluruh sesinya dicabut.
+func RefreshToken(response http.ResponseWriter, request *http.Request) {
+	var body refreshRequest
+	if err := json.NewDecoder(request.Body).Decode(&body); err != nil || body.RefreshToken == "" {
+		writeTokenError(response, http.StatusBadRequest, "Missing refresh token")
+		return
+	}
+
+	current, err := refreshTokenModel.FindByHash(hashToken(body.RefreshToken))
+	if err != nil {
+		log.Printf("Error looking up refresh token: %v", err)
+		writeTokenError(response, http.StatusInternalServerError, "Could not refresh token")
+		return
+	}
+	if current == nil {
+		writeTokenError(response, http.StatusUnauthorized, "Invalid refresh token")
+		return
+	}
+	if current.RevokedAt != nil {
+		writeTokenError(response, http.StatusUnauthorized, "Session has been revoked")
+		return
+	}
+	if current.UsedAt != nil {
+		revokeReusedSession(current)
+		writeTokenError(response, http.StatusUnauthorized, "Refresh token reuse detected, session revoked")
+		return
+	}
+	if models.RefreshTokenExpired(current) {
+		writeTokenError(response, http.StatusUnauthorized, "Refresh token expired")
+		return
+	}
+
+	// Sesi bisa dicabut dari perangkat lain atau oleh admin, atau sudah melewati umur maksimum
+	active, err := sessionModel.IsActive(current.FamilyID, current.UserID, sessionMaxLifetime)
+	if err != nil {
+		log.Printf("Error checking session %s: %v", current.FamilyID, err)
+		writeTokenError(response, http.StatusInternalServerError, "Could not refresh token")
+		return
+	}
+	if !active {
+		endSession(current.FamilyID)
+		writeTokenError(response, http.StatusUnauthorized, "Session has expired or been revoked, please log in again")
+		return
+	}
+
+	// Role, instansi dan permission dibaca ulang agar perubahan oleh admin langsung berlaku
+	user, err := userModel.FindUserByID(current.UserID)
+	if errors.Is(err, sql.ErrNoRows) {
+		endSession(current.FamilyID)
+		writeTokenError(response, http.StatusUnauthorized, "User is no longer active")
+		return
+	}
+	// Gangguan database bukan alasan mencabut sesi; klien bisa mencoba lagi
+	if err != nil {
+		log.Printf("Error loading user %d for token refresh: %v", current.UserID, err)
+		writeTokenError(response, http.StatusInternalServerError, "Could not refresh token")
+		return
+	}
+
+	// Role yang baru diwajibkan 2FA tidak boleh terus diperpanjang tanpa pendaftaran;
+	// sesi diakhiri agar user login ulang dan melewati langkah pendaftaran 2FA
+	missing, err := twoFactorEnrollmentMissing(user)
+	if err != nil {
+		log.Printf("Error checking two-factor policy for user %d: %v", user.Id, err)
+		writeTokenError(response, http.StatusInternalServerError, "Could not refresh token")
+		return
+	}
+	if missing {
+		endSession(current.FamilyID)
+		writeTokenError(response, http.StatusUnauthorized, "Two-factor authentication is required, please log in again")
+		return
+	}
+	permissions, err := permissionNames(user.Role_Id)
+	if err != nil {
+		writeTokenError(response, http.StatusInternalServerError, "Could not load permissions")
+		return
+	}
+
+	refreshToken, refreshHash, err := newRefreshToken()
+	if err != nil {
+		writeTokenError(response, http.StatusInternalServerError, "Could not refresh token")
+		return
+	}
+	rotated, err := refreshTokenModel.Rotate(current.Id, entities.RefreshToken{
+		UserID:    current.UserID,
+		FamilyID:  current.FamilyID,
+		TokenHash: refreshHash,
+	}, refreshTokenTTL)
+	if err != nil {
+		log.Printf("Error rotating refresh token: %v", err)
+		writeTokenError(response, http.StatusInternalServerError, "Could not refresh token")
+		return
+	}
+	if !rotated {
+		// Token yang sama dipakai bersamaan oleh dua pihak
+		revokeReusedSession(current)
+		writeTokenError(response, http.StatusUnauthorized, "Refresh token reuse detected, session revoked")
+		return
+	}
+
+	accessToken, err := signAccessToken(user, permissions, current.FamilyID)
+	if err != nil {
+		writeTokenError(response, http.StatusInternalServerError, "Could not generate token")
+		return
+	}
+
+	response.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(response).Encode(map[string]interface{}{
+		"token":         accessToken,
+		"refresh_token": refreshToken,
+		"expires_in":    int(accessTokenTTL.Seconds()),
+		"role":          user.RoleName,
+		"role_id":       user.Role_Id,
+		"instance_id":   user.Instance_Id,
+		"permissions":   permissions,
+	})
+}
+
+// endSession mencabut sesi beserta seluruh refresh token-nya
+func endSession(sessionID string) error {
+	middleware.ForgetSession(sessionID)
+	if err := sessionModel.RevokeByID(sessionID); err != nil {
+		return err
+	}
+	return refreshTokenModel.RevokeFamily(sessionID)
+}
+
+// revokeReusedSession mencabut sesi yang refresh token lamanya diputar ulang dan memberi tahu admin
+func revokeReusedSession(token *entities.RefreshToken) {
+	if err := endSession(token.FamilyID); err != nil {
+		log.Printf("Error revoking session %s: %v", token.FamilyID, err)
+	}
+
+	message := fmt.Sprintf("Refresh token lama milik user %d dipakai ulang; sesi %s dicabut", token.UserID, token.FamilyID)
+	log.Println(message)
+	if err := notificationModel.Notify(models.NotificationTokenReuse, message, token.UserID); err != nil {
+		log.Printf("Error notifying admins about token reuse: %v", err)
+	}
+}
+
+// Logout mencabut seluruh refresh token dalam sesi milik refresh token yang dikirim.
+// Selalu berhasil agar client tetap bisa membersihkan sesi lokalnya.
+func Logout(response http.ResponseWriter, request *http.Request) {
+	var body refreshRequest
+	if err := json.NewDecoder(request.Body).Decode(&body); err != nil || body.RefreshToken == "" {
+		writeTokenError(response, http.StatusBadRequest, "Missing refresh token")
+		return
+	}
+
+	current, err := refreshTokenModel.FindByHash(hashToken(body.RefreshToken))
+	if err != nil {
+		log.Printf("Error looking up refresh token: %v", err)
+		writeTokenError(response, http.StatusInternalServerError, "Could not log out")
+		return
+	}
+	if current != nil {
+		if err := endSession(current.FamilyID); err != nil {
+			log.Printf("Error revoking session %s: %v", current.FamilyID, err)
+			writeTokenError(response, http.StatusInternalServerError, "Could not log out")
+			return
+		}
+	}
+
+	response.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(response).Encode(map[string]string{
+		"message": "Logged out successfully",
+	})
+}
@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"backend/internal/dbtest"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// expectRefreshUntilUserLookup menyiapkan refresh token dan sesi yang valid
+// sampai langkah membaca ulang user
+func expectRefreshUntilUserLookup(userErr error) {
+	expires := time.Now().Add(24 * time.Hour).Format("2006-01-02 15:04:05")
+	dbtest.Mock.ExpectQuery("FROM refresh_tokens").WithArgs(hashToken("refresh-lama")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "family_id", "parent_id", "token_hash",
+			"created_at", "expires_at", "used_at", "revoked_at"}).
+			AddRow(5, 42, "sesi-1", nil, hashToken("refresh-lama"), "2024-05-01 10:00:00", expires, nil, nil))
+	dbtest.Mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM sessions").WithArgs("sesi-1", int64(42), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	dbtest.Mock.ExpectQuery("WHERE u.id = \\? AND u.deleted_at IS NULL").WithArgs(int64(42)).WillReturnError(userErr)
+}
+
+func refreshWith(token string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodPost, "/api/token/refresh", strings.NewReader(`{"refresh_token":"`+token+`"}`))
+	recorder := httptest.NewRecorder()
+	RefreshToken(recorder, request)
+	return recorder
+}
+
+func TestRefreshTokenEndsSessionOfRemovedUser(t *testing.T) {
+	expectRefreshUntilUserLookup(sql.ErrNoRows)
+	dbtest.Mock.ExpectBegin()
+	dbtest.Mock.ExpectExec("UPDATE sessions SET revoked_at").WillReturnResult(sqlmock.NewResult(0, 1))
+	dbtest.Mock.ExpectExec("UPDATE refresh_tokens SET revoked_at").WillReturnResult(sqlmock.NewResult(0, 1))
+	dbtest.Mock.ExpectCommit()
+	dbtest.Mock.ExpectExec("UPDATE refresh_tokens SET revoked_at").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	recorder := refreshWith("refresh-lama")
+	if recorder.Code != http.StatusUnauthorized || !strings.Contains(recorder.Body.String(), "no longer active") {
+		t.Fatalf("status = %d, body = %s", recorder.Code, recorder.Body)
+	}
+	if err := dbtest.Mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// Database yang sedang bermasalah tidak boleh membuat semua sesi ikut dicabut
+func TestRefreshTokenKeepsSessionOnDatabaseError(t *testing.T) {
+	expectRefreshUntilUserLookup(errors.New("connection refused"))
+
+	recorder := refreshWith("refresh-lama")
+	if recorder.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, body = %s, want 500", recorder.Code, recorder.Body)
+	}
+	if err := dbtest.Mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
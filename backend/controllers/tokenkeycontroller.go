@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"backend/helpers"
+	middleware "backend/middlewares"
+	"backend/models"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+var tokenKeyModel = models.NewTokenKeyModel()
+
+// GetTokenKeys menampilkan kunci token di keyring tanpa isi kuncinya
+func GetTokenKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := tokenKeyModel.FindAll()
+	if err != nil {
+		log.Printf("Error fetching token keys: %v", err)
+		http.Error(w, "Failed to fetch token keys", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"active": helpers.ActiveTokenKeyID(),
+		"keys":   keys,
+	})
+}
+
+// RotateTokenKey membuat kunci aktif baru. Token lama tetap berlaku sampai
+// kunci sebelumnya dipensiunkan.
+func RotateTokenKey(w http.ResponseWriter, r *http.Request) {
+	kid, err := tokenKeyModel.Rotate()
+	if err != nil {
+		log.Printf("Error rotating token key: %v", err)
+		http.Error(w, "Failed to rotate token key", http.StatusInternalServerError)
+		return
+	}
+	if err := helpers.ReloadTokenKeys(); err != nil {
+		log.Printf("Error reloading token keys: %v", err)
+	}
+
+	if claims, ok := r.Context().Value(middleware.UserContextKey).(*middleware.Claims); ok {
+		log.Printf("User %d rotated token key, new key %s", claims.ID, kid)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Token key rotated successfully",
+		"kid":     kid,
+	})
+}
+
+// RetireTokenKey memensiunkan kunci lama; token yang memakainya langsung ditolak
+func RetireTokenKey(w http.ResponseWriter, r *http.Request) {
+	kid := mux.Vars(r)["kid"]
+
+	err := tokenKeyModel.Retire(kid)
+	switch {
+	case errors.Is(err, models.ErrTokenKeyNotFound):
+		http.Error(w, "Token key not found", http.StatusNotFound)
+		return
+	case errors.Is(err, models.ErrTokenKeyActive):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		log.Printf("Error retiring token key %s: %v", kid, err)
+		http.Error(w, "Failed to retire token key", http.StatusInternalServerError)
+		return
+	}
+	if err := helpers.ReloadTokenKeys(); err != nil {
+		log.Printf("Error reloading token keys: %v", err)
+	}
+
+	if claims, ok := r.Context().Value(middleware.UserContextKey).(*middleware.Claims); ok {
+		log.Printf("User %d retired token key %s", claims.ID, kid)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Token key retired successfully",
+		"kid":     kid,
+	})
+}
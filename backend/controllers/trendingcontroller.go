@@ -0,0 +1,113 @@
+package controllers
+
+import (
+	"backend/entities"
+	middleware "backend/middlewares"
+	"backend/models"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Peringkat dihitung ulang paling sering setiap rankingRefresh; di antaranya dipakai dari cache
+const rankingRefresh = 5 * time.Minute
+
+// Jumlah kandidat yang disimpan di cache sebelum difilter sesuai hak akses pemanggil
+const rankingCandidates = 500
+
+// Trending memakai view 7 hari terakhir dengan half-life 2 hari
+const trendingWindowDays = 7
+const trendingHalfLifeDays = 2.0
+
+// rankingCache menyimpan hasil peringkat terakhir dan memuat ulang setelah kedaluwarsa
+type rankingCache struct {
+	mu        sync.Mutex
+	load      func() ([]entities.RankedContent, error)
+	items     []entities.RankedContent
+	refreshed time.Time
+}
+
+func (c *rankingCache) get() ([]entities.RankedContent, time.Time, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.items != nil && time.Since(c.refreshed) < rankingRefresh {
+		return c.items, c.refreshed, nil
+	}
+
+	items, err := c.load()
+	if err != nil {
+		// Jika gagal memuat ulang, tetap layani data lama bila ada
+		if c.items != nil {
+			log.Printf("Failed to refresh ranking, serving stale data: %v", err)
+			return c.items, c.refreshed, nil
+		}
+		return nil, time.Time{}, err
+	}
+
+	c.items = items
+	c.refreshed = time.Now()
+	return c.items, c.refreshed, nil
+}
+
+var trendingCache = &rankingCache{load: func() ([]entities.RankedContent, error) {
+	since := time.Now().AddDate(0, 0, -(trendingWindowDays - 1))
+	return contentModel.RankTrending(since, trendingHalfLifeDays, rankingCandidates)
+}}
+
+var popularCache = &rankingCache{load: func() ([]entities.RankedContent, error) {
+	return contentModel.RankPopular(rankingCandidates)
+}}
+
+func GetTrendingContents(w http.ResponseWriter, r *http.Request) {
+	serveRanking(w, r, trendingCache)
+}
+
+func GetPopularContents(w http.ResponseWriter, r *http.Request) {
+	serveRanking(w, r, popularCache)
+}
+
+// serveRanking memfilter peringkat dari cache sesuai accessibility pemanggil
+func serveRanking(w http.ResponseWriter, r *http.Request, cache *rankingCache) {
+	claims, ok := r.Context().Value(middleware.UserContextKey).(*middleware.Claims)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	limit := 10
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		value, err := strconv.Atoi(limitStr)
+		if err != nil || value < 1 || value > 50 {
+			http.Error(w, "Parameter 'limit' must be between 1 and 50", http.StatusBadRequest)
+			return
+		}
+		limit = value
+	}
+
+	ranked, refreshed, err := cache.get()
+	if err != nil {
+		log.Printf("Error ranking content: %v", err)
+		http.Error(w, "Failed to rank content", http.StatusInternalServerError)
+		return
+	}
+
+	items := []entities.RankedContent{}
+	for _, content := range ranked {
+		if len(items) == limit {
+			break
+		}
+		if models.ContentVisibleTo(content.Accessibility, content.InstanceID, claims.InstanceID, claims.RoleID) {
+			items = append(items, content)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"items":        items,
+		"refreshed_at": refreshed.Format(time.RFC3339),
+	})
+}
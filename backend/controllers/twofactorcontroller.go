@@ -0,0 +1,571 @@
+package controllers
+
+import (
+	"backend/entities"
+	"backend/helpers"
+	middleware "backend/middlewares"
+	"backend/models"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+var twoFactorModel = models.NewTwoFactorModel()
+
+const (
+	twoFactorIssuer       = "Wiki"
+	twoFactorChallengeTTL = 5 * time.Minute
+	recoveryCodeCount     = 10
+	loginReasonInvalid2FA = "invalid_2fa_code"
+)
+
+type twoFactorRequest struct {
+	Challenge    string `json:"challenge"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+// twoFactorEnrollmentMissing bernilai true jika role user wajib 2FA tetapi user belum mengaktifkannya
+func twoFactorEnrollmentMissing(user *entities.UserDetail) (bool, error) {
+	totp, err := twoFactorModel.FindTOTP(user.Id)
+	if err != nil {
+		return false, err
+	}
+	if totp != nil && totp.Enabled {
+		return false, nil
+	}
+	return twoFactorModel.RequiredForRole(user.Role_Id)
+}
+
+// beginTwoFactor dipanggil Login setelah password benar. Jika user memakai 2FA
+// (atau wajib mendaftar 2FA) challenge dikirim ke client dan hasilnya true.
+func beginTwoFactor(response http.ResponseWriter, user *entities.UserDetail) bool {
+	totp, err := twoFactorModel.FindTOTP(user.Id)
+	if err != nil {
+		log.Printf("Error loading two-factor of user %d: %v", user.Id, err)
+		writeTokenError(response, http.StatusInternalServerError, "Could not verify two-factor status")
+		return true
+	}
+	enabled := totp != nil && totp.Enabled
+
+	required := false
+	if !enabled {
+		required, err = twoFactorModel.RequiredForRole(user.Role_Id)
+		if err != nil {
+			log.Printf("Error checking two-factor policy for user %d: %v", user.Id, err)
+			writeTokenError(response, http.StatusInternalServerError, "Could not verify two-factor status")
+			return true
+		}
+		if !required {
+			return false
+		}
+	}
+
+	challenge, err := randomHex(32)
+	if err != nil {
+		writeTokenError(response, http.StatusInternalServerError, "Could not start two-factor login")
+		return true
+	}
+	if err := twoFactorModel.CreateChallenge(user.Id, hashToken(challenge), twoFactorChallengeTTL); err != nil {
+		log.Printf("Error creating two-factor challenge for user %d: %v", user.Id, err)
+		writeTokenError(response, http.StatusInternalServerError, "Could not start two-factor login")
+		return true
+	}
+
+	response.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(response).Encode(map[string]interface{}{
+		"two_factor_required": true,
+		// Role user wajib 2FA tetapi belum mendaftar: lanjut ke /api/login/2fa/setup
+		"two_factor_setup_required": !enabled,
+		"challenge":                 challenge,
+		"expires_in":                int(twoFactorChallengeTTL.Seconds()),
+	})
+	return true
+}
+
+// challengeUser membaca challenge dari request dan mengambil user pemiliknya.
+// Jika gagal, response sudah dikirim dan user bernilai nil.
+func challengeUser(response http.ResponseWriter, request *http.Request) (*twoFactorRequest, *entities.UserDetail) {
+	var body twoFactorRequest
+	if err := json.NewDecoder(request.Body).Decode(&body); err != nil || body.Challenge == "" {
+		writeTokenError(response, http.StatusBadRequest, "Missing two-factor challenge")
+		return nil, nil
+	}
+
+	userID, err := twoFactorModel.FindChallenge(hashToken(body.Challenge))
+	if err != nil {
+		log.Printf("Error looking up two-factor challenge: %v", err)
+		writeTokenError(response, http.StatusInternalServerError, "Could not verify two-factor challenge")
+		return nil, nil
+	}
+	if userID == 0 {
+		writeTokenError(response, http.StatusUnauthorized, "Two-factor challenge is invalid or expired, please log in again")
+		return nil, nil
+	}
+
+	user, err := userModel.FindUserByID(userID)
+	if err != nil {
+		writeTokenError(response, http.StatusUnauthorized, "User is no longer active")
+		return nil, nil
+	}
+
+	// Akun bisa terkunci oleh kode 2FA yang salah berulang kali
+	_, lockedUntil, err := loginSecurityModel.LockoutState(user.Email)
+	if err != nil {
+		writeTokenError(response, http.StatusInternalServerError, "Could not verify account status")
+		return nil, nil
+	}
+	if !lockedUntil.IsZero() {
+		recordLoginAttempt(request, user.Email, user.Id, false, loginReasonLocked)
+		sendAccountLocked(response, lockedUntil)
+		return nil, nil
+	}
+	return &body, user
+}
+
+// rejectTwoFactorCode mencatat kode 2FA yang salah sebagai kegagalan login
+func rejectTwoFactorCode(response http.ResponseWriter, request *http.Request, body *twoFactorRequest, user *entities.UserDetail) {
+	if err := twoFactorModel.FailChallenge(hashToken(body.Challenge)); err != nil {
+		log.Printf("Error updating two-factor challenge: %v", err)
+	}
+	recordLoginAttempt(request, user.Email, user.Id, false, loginReasonInvalid2FA)
+	registerLoginFailure(user.Email, user.Id)
+	writeTokenError(response, http.StatusUnauthorized, "Invalid two-factor code")
+}
+
+// consumeChallenge memastikan challenge hanya bisa dipakai satu kali
+func consumeChallenge(response http.ResponseWriter, body *twoFactorRequest) bool {
+	consumed, err := twoFactorModel.ConsumeChallenge(hashToken(body.Challenge))
+	if err != nil {
+		log.Printf("Error consuming two-factor challenge: %v", err)
+		writeTokenError(response, http.StatusInternalServerError, "Could not complete login")
+		return false
+	}
+	if !consumed {
+		writeTokenError(response, http.StatusUnauthorized, "Two-factor challenge is invalid or expired, please log in again")
+		return false
+	}
+	return true
+}
+
+// verifySecondFactor memeriksa kode TOTP atau kode pemulihan milik user
+func verifySecondFactor(totp *entities.UserTOTP, code, recoveryCode string) (bool, error) {
+	if recoveryCode != "" {
+		return twoFactorModel.UseRecoveryCode(totp.UserID, helpers.HashRecoveryCode(recoveryCode))
+	}
+	step, ok := helpers.VerifyTOTP(totp.Secret, code, time.Now())
+	if !ok {
+		return false, nil
+	}
+	return twoFactorModel.UseStep(totp.UserID, step)
+}
+
+// newRecoveryCodes membuat kode pemulihan baru beserta hash yang disimpan
+func newRecoveryCodes() ([]string, []string, error) {
+	codes, err := helpers.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, nil, err
+	}
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = helpers.HashRecoveryCode(code)
+	}
+	return codes, hashes, nil
+}
+
+// startEnrollment membuat secret TOTP yang belum aktif untuk user
+func startEnrollment(user *entities.UserDetail) (map[string]string, bool, error) {
+	secret, err := helpers.GenerateTOTPSecret()
+	if err != nil {
+		return nil, false, err
+	}
+	saved, err := twoFactorModel.SavePending(user.Id, secret)
+	if err != nil || !saved {
+		return nil, saved, err
+	}
+	return map[string]string{
+		"secret":           helpers.EncodeTOTPSecret(secret),
+		"provisioning_uri": helpers.TOTPProvisioningURI(twoFactorIssuer, user.Email, secret),
+	}, true, nil
+}
+
+// confirmEnrollment memverifikasi kode pertama dari aplikasi authenticator lalu
+// mengaktifkan 2FA. Kode pemulihan dikembalikan sekali ini saja.
+func confirmEnrollment(userID int64, code string) ([]string, bool, error) {
+	totp, err := twoFactorModel.FindTOTP(userID)
+	if err != nil || totp == nil || totp.Enabled {
+		return nil, false, err
+	}
+	step, ok := helpers.VerifyTOTP(totp.Secret, code, time.Now())
+	if !ok {
+		return nil, false, nil
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, false, err
+	}
+	if err := twoFactorModel.Enable(userID, step, hashes); err != nil {
+		return nil, false, err
+	}
+	return codes, true, nil
+}
+
+// LoginTwoFactor adalah langkah kedua login: challenge dari Login ditukar dengan
+// token jika kode TOTP atau kode pemulihan benar
+func LoginTwoFactor(response http.ResponseWriter, request *http.Request) {
+	body, user := challengeUser(response, request)
+	if user == nil {
+		return
+	}
+	if body.Code == "" && body.RecoveryCode == "" {
+		writeTokenError(response, http.StatusBadRequest, "Missing two-factor code")
+		return
+	}
+
+	totp, err := twoFactorModel.FindTOTP(user.Id)
+	if err != nil {
+		log.Printf("Error loading two-factor of user %d: %v", user.Id, err)
+		writeTokenError(response, http.StatusInternalServerError, "Could not verify two-factor code")
+		return
+	}
+	if totp == nil || !totp.Enabled {
+		writeTokenError(response, http.StatusConflict, "Two-factor setup is required before logging in")
+		return
+	}
+
+	valid, err := verifySecondFactor(totp, body.Code, body.RecoveryCode)
+	if err != nil {
+		log.Printf("Error verifying two-factor code of user %d: %v", user.Id, err)
+		writeTokenError(response, http.StatusInternalServerError, "Could not verify two-factor code")
+		return
+	}
+	if !valid {
+		rejectTwoFactorCode(response, request, body, user)
+		return
+	}
+	if !consumeChallenge(response, body) {
+		return
+	}
+
+	completeLogin(response, request, user, nil)
+}
+
+// LoginTwoFactorSetup memberi secret TOTP kepada user yang wajib 2FA tetapi belum mendaftar
+func LoginTwoFactorSetup(response http.ResponseWriter, request *http.Request) {
+	_, user := challengeUser(response, request)
+	if user == nil {
+		return
+	}
+
+	enrollment, saved, err := startEnrollment(user)
+	if err != nil {
+		log.Printf("Error starting two-factor enrollment for user %d: %v", user.Id, err)
+		writeTokenError(response, http.StatusInternalServerError, "Could not start two-factor setup")
+		return
+	}
+	if !saved {
+		writeTokenError(response, http.StatusConflict, "Two-factor is already enabled")
+		return
+	}
+
+	response.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(response).Encode(enrollment)
+}
+
+// LoginTwoFactorEnable mengonfirmasi pendaftaran 2FA saat login lalu menyelesaikan login
+func LoginTwoFactorEnable(response http.ResponseWriter, request *http.Request) {
+	body, user := challengeUser(response, request)
+	if user == nil {
+		return
+	}
+	if body.Code == "" {
+		writeTokenError(response, http.StatusBadRequest, "Missing two-factor code")
+		return
+	}
+
+	codes, enabled, err := confirmEnrollment(user.Id, body.Code)
+	if err != nil {
+		log.Printf("Error enabling two-factor for user %d: %v", user.Id, err)
+		writeTokenError(response, http.StatusInternalServerError, "Could not enable two-factor")
+		return
+	}
+	if !enabled {
+		rejectTwoFactorCode(response, request, body, user)
+		return
+	}
+	if !consumeChallenge(response, body) {
+		return
+	}
+
+	completeLogin(response, request, user, map[string]interface{}{"recovery_codes": codes})
+}
+
+// GetTwoFactorStatus menampilkan status 2FA user yang login
+func GetTwoFactorStatus(w http.ResponseWriter, r *http.Request) {
+	claims, ok := loggedInClaims(w, r)
+	if !ok {
+		return
+	}
+	userID := int64(claims.ID)
+
+	totp, err := twoFactorModel.FindTOTP(userID)
+	if err != nil {
+		log.Printf("Error loading two-factor of user %d: %v", userID, err)
+		http.Error(w, "Failed to fetch two-factor status", http.StatusInternalServerError)
+		return
+	}
+	required, err := twoFactorModel.RequiredForRole(claims.RoleID)
+	if err != nil {
+		log.Printf("Error checking two-factor policy for user %d: %v", userID, err)
+		http.Error(w, "Failed to fetch two-factor status", http.StatusInternalServerError)
+		return
+	}
+
+	status := entities.TwoFactorStatus{Required: required}
+	if totp != nil {
+		status.Enabled = totp.Enabled
+		status.Pending = !totp.Enabled
+	}
+	if status.Enabled {
+		if status.RecoveryCodesRemaining, err = twoFactorModel.RemainingRecoveryCodes(userID); err != nil {
+			log.Printf("Error counting recovery codes of user %d: %v", userID, err)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
+// SetupTwoFactor memulai pendaftaran TOTP; kirim provisioning_uri sebagai QR code
+func SetupTwoFactor(w http.ResponseWriter, r *http.Request) {
+	claims, ok := loggedInClaims(w, r)
+	if !ok {
+		return
+	}
+	user, err := userModel.FindUserByID(int64(claims.ID))
+	if err != nil {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+
+	enrollment, saved, err := startEnrollment(user)
+	if err != nil {
+		log.Printf("Error starting two-factor enrollment for user %d: %v", user.Id, err)
+		http.Error(w, "Failed to start two-factor setup", http.StatusInternalServerError)
+		return
+	}
+	if !saved {
+		http.Error(w, "Two-factor is already enabled", http.StatusConflict)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(enrollment)
+}
+
+// EnableTwoFactor mengaktifkan 2FA setelah kode pertama dari authenticator benar
+func EnableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	claims, ok := loggedInClaims(w, r)
+	if !ok {
+		return
+	}
+	var body twoFactorRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Code == "" {
+		http.Error(w, "Missing two-factor code", http.StatusBadRequest)
+		return
+	}
+
+	codes, enabled, err := confirmEnrollment(int64(claims.ID), body.Code)
+	if err != nil {
+		log.Printf("Error enabling two-factor for user %d: %v", claims.ID, err)
+		http.Error(w, "Failed to enable two-factor", http.StatusInternalServerError)
+		return
+	}
+	if !enabled {
+		http.Error(w, "Invalid two-factor code or no pending setup", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":        "Two-factor enabled successfully",
+		"recovery_codes": codes,
+	})
+}
+
+// verifyOwnSecondFactor memeriksa kode dari user yang login sebelum 2FA diubah.
+// Jika gagal, response sudah dikirim.
+func verifyOwnSecondFactor(w http.ResponseWriter, r *http.Request, userID int64) bool {
+	var body twoFactorRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || (body.Code == "" && body.RecoveryCode == "") {
+		http.Error(w, "Missing two-factor code", http.StatusBadRequest)
+		return false
+	}
+
+	totp, err := twoFactorModel.FindTOTP(userID)
+	if err != nil {
+		log.Printf("Error loading two-factor of user %d: %v", userID, err)
+		http.Error(w, "Failed to verify two-factor code", http.StatusInternalServerError)
+		return false
+	}
+	if totp == nil || !totp.Enabled {
+		http.Error(w, "Two-factor is not enabled", http.StatusConflict)
+		return false
+	}
+
+	valid, err := verifySecondFactor(totp, body.Code, body.RecoveryCode)
+	if err != nil {
+		log.Printf("Error verifying two-factor code of user %d: %v", userID, err)
+		http.Error(w, "Failed to verify two-factor code", http.StatusInternalServerError)
+		return false
+	}
+	if !valid {
+		http.Error(w, "Invalid two-factor code", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+// DisableTwoFactor mematikan 2FA milik sendiri, kecuali role user mewajibkannya
+func DisableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	claims, ok := loggedInClaims(w, r)
+	if !ok {
+		return
+	}
+	userID := int64(claims.ID)
+
+	required, err := twoFactorModel.RequiredForRole(claims.RoleID)
+	if err != nil {
+		log.Printf("Error checking two-factor policy for user %d: %v", userID, err)
+		http.Error(w, "Failed to disable two-factor", http.StatusInternalServerError)
+		return
+	}
+	if required {
+		http.Error(w, "Two-factor is required for your role", http.StatusForbidden)
+		return
+	}
+	if !verifyOwnSecondFactor(w, r, userID) {
+		return
+	}
+
+	if _, err := twoFactorModel.Disable(userID); err != nil {
+		log.Printf("Error disabling two-factor for user %d: %v", userID, err)
+		http.Error(w, "Failed to disable two-factor", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Two-factor disabled successfully"})
+}
+
+// RegenerateRecoveryCodes mengganti semua kode pemulihan; kode lama tidak berlaku lagi
+func RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	claims, ok := loggedInClaims(w, r)
+	if !ok {
+		return
+	}
+	userID := int64(claims.ID)
+	if !verifyOwnSecondFactor(w, r, userID) {
+		return
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err == nil {
+		err = twoFactorModel.ReplaceRecoveryCodes(userID, hashes)
+	}
+	if err != nil {
+		log.Printf("Error regenerating recovery codes for user %d: %v", userID, err)
+		http.Error(w, "Failed to regenerate recovery codes", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"recovery_codes": codes})
+}
+
+// ResetUserTwoFactor menghapus 2FA user lain, misalnya karena perangkatnya hilang.
+// User yang role-nya wajib 2FA akan diminta mendaftar ulang saat login berikutnya.
+func ResetUserTwoFactor(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	removed, err := twoFactorModel.Disable(userID)
+	if err != nil {
+		log.Printf("Error resetting two-factor for user %d: %v", userID, err)
+		http.Error(w, "Failed to reset two-factor", http.StatusInternalServerError)
+		return
+	}
+	if !removed {
+		http.Error(w, "User has no two-factor enrollment", http.StatusNotFound)
+		return
+	}
+
+	if claims, ok := r.Context().Value(middleware.UserContextKey).(*middleware.Claims); ok {
+		log.Printf("User %d reset two-factor of user %d", claims.ID, userID)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Two-factor reset successfully",
+		"user_id": userID,
+	})
+}
+
+// GetTwoFactorPolicy menampilkan permission yang mewajibkan 2FA
+func GetTwoFactorPolicy(w http.ResponseWriter, r *http.Request) {
+	permissions, err := twoFactorModel.FindPolicy()
+	if err != nil {
+		log.Printf("Error fetching two-factor policy: %v", err)
+		http.Error(w, "Failed to fetch two-factor policy", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"permissions": permissions})
+}
+
+// UpdateTwoFactorPolicy mengganti daftar permission yang mewajibkan 2FA
+func UpdateTwoFactorPolicy(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Permissions []string `json:"permissions"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Permissions == nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	unknown, err := twoFactorModel.SetPolicy(body.Permissions)
+	if err != nil {
+		log.Printf("Error updating two-factor policy: %v", err)
+		http.Error(w, "Failed to update two-factor policy", http.StatusInternalServerError)
+		return
+	}
+	if len(unknown) > 0 {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "Unknown permissions",
+			"unknown": unknown,
+		})
+		return
+	}
+
+	if claims, ok := r.Context().Value(middleware.UserContextKey).(*middleware.Claims); ok {
+		log.Printf("User %d set two-factor policy to %v", claims.ID, body.Permissions)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":     "Two-factor policy updated successfully",
+		"permissions": body.Permissions,
+	})
+}
@@ -2,13 +2,15 @@ package controllers
 
 import (
 	"backend/entities"
+	"backend/helpers"
+	middleware "backend/middlewares"
 	"backend/models"
-	"crypto/aes"
-	"crypto/cipher"
 	"crypto/rand"
-	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
 	"strconv"
 	"time"
@@ -18,29 +20,9 @@ import (
 )
 
 var userModel = models.NewUserModel()
-var jwtKey = []byte("wiki") 
-var encryptionKey = []byte("myverystrongpasswordo32bitlength") // Harus 32 byte
-
-func encrypt(text string, key []byte) (string, error) {
-	block, err := aes.NewCipher(key)
-	if err != nil {
-		return "", err
-	}
-
-	aesGCM, err := cipher.NewGCM(block)
-	if err != nil {
-		return "", err
-	}
-
-	nonce := make([]byte, aesGCM.NonceSize())
-	if _, err := rand.Read(nonce); err != nil {
-		return "", err
-	}
-
-	ciphertext := aesGCM.Seal(nonce, nonce, []byte(text), nil)
-	return base64.StdEncoding.EncodeToString(ciphertext), nil
-}
 
+// authProviders dicoba berurutan saat login: password lokal lalu LDAP jika diaktifkan
+var authProviders = models.NewAuthProviders(userModel)
 
 func GetGuestPermissions(w http.ResponseWriter, r *http.Request) {
     permissions, err := userModel.GetGuestPermissions()
@@ -73,83 +55,147 @@ func Login(response http.ResponseWriter, request *http.Request) {
 		return
 	}
 
-	userModel := models.NewUserModel()
-	authenticatedUser, err := userModel.Authenticate(user.Email, user.Password)
+	// Akun yang terkunci ditolak sebelum password diperiksa, dengan jawaban yang sama
+	// seperti password salah agar status kunci tidak membocorkan email yang terdaftar
+	userID, lockedUntil, err := loginSecurityModel.LockoutState(user.Email)
 	if err != nil {
 		response.Header().Set("Content-Type", "application/json")
-		response.WriteHeader(http.StatusUnauthorized)
+		response.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(response).Encode(map[string]string{
-			"error": "Invalid email or password",
+			"error": "Could not verify account status",
 		})
 		return
 	}
+	if !lockedUntil.IsZero() {
+		helpers.BurnPasswordCheck(user.Password)
+		recordLoginAttempt(request, user.Email, userID, false, loginReasonLocked)
+		sendInvalidCredentials(response)
+		return
+	}
 
-	roleModel := models.NewRoleModel()
-	role, err := roleModel.FindRoleById(authenticatedUser.Role_Id)
-	if err != nil {
+	authenticatedUser, err := authenticate(user.Email, user.Password)
+	if errors.Is(err, models.ErrExternalLinkNotAllowed) {
+		recordLoginAttempt(request, user.Email, userID, false, loginReasonNotAllowed)
 		response.Header().Set("Content-Type", "application/json")
-		response.WriteHeader(http.StatusNotFound)
+		response.WriteHeader(http.StatusForbidden)
 		json.NewEncoder(response).Encode(map[string]string{
-			"error": "Role not found",
+			"error": "A wiki account with this email already exists; ask an administrator to allow linking it",
 		})
 		return
 	}
-
-	permissionModel := models.NewPermissionModel()
-	permissions, err := permissionModel.GetPermissionsByRole(role.Id)
-	if err != nil {
+	if errors.Is(err, models.ErrExternalUserNotAllowed) {
+		recordLoginAttempt(request, user.Email, userID, false, loginReasonNotAllowed)
 		response.Header().Set("Content-Type", "application/json")
-		response.WriteHeader(http.StatusNotFound)
+		response.WriteHeader(http.StatusForbidden)
 		json.NewEncoder(response).Encode(map[string]string{
-			"error": "Permissions not found for this role",
+			"error": "Your account is not allowed to access the wiki",
+		})
+		return
+	}
+	if err != nil && !errors.Is(err, models.ErrInvalidCredentials) {
+		log.Printf("Error authenticating %s: %v", user.Email, err)
+		response.Header().Set("Content-Type", "application/json")
+		response.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(response).Encode(map[string]string{
+			"error": "Authentication service is unavailable, please try again later",
 		})
 		return
 	}
+	if err != nil {
+		recordLoginAttempt(request, user.Email, userID, false, loginReasonInvalid)
+		registerLoginFailure(user.Email, userID)
+		sendInvalidCredentials(response)
+		return
+	}
 
-	expirationTime := time.Now().Add(300 * time.Hour)
-	permissionsList := []string{}
-	for _, permission := range permissions {
-		permissionsList = append(permissionsList, permission.Name)
+	// Akun dengan 2FA aktif, atau yang role-nya wajib 2FA, melanjutkan ke langkah kedua
+	if beginTwoFactor(response, authenticatedUser) {
+		return
 	}
 
-	claims := jwt.MapClaims{
-		"id":          authenticatedUser.Id,
-		"role":        role.Name,
-		"role_id":     authenticatedUser.Role_Id,
-		"permissions": permissionsList,
-		"instance_id": authenticatedUser.Instance_Id,
-		"exp":         expirationTime.Unix(),
+	completeLogin(response, request, authenticatedUser, nil)
+}
+
+// sendInvalidCredentials menjawab login yang gagal, termasuk ke akun yang terkunci
+func sendInvalidCredentials(response http.ResponseWriter) {
+	response.Header().Set("Content-Type", "application/json")
+	response.WriteHeader(http.StatusUnauthorized)
+	json.NewEncoder(response).Encode(map[string]string{
+		"error": "Invalid email or password",
+	})
+}
+
+// authenticate mencoba setiap provider sampai ada yang menerima login. Error selain
+// ErrInvalidCredentials (mis. server LDAP mati) dikembalikan jika tidak ada provider
+// yang berhasil, agar tidak dihitung sebagai password salah.
+func authenticate(login, password string) (*entities.UserDetail, error) {
+	err := models.ErrInvalidCredentials
+	for _, provider := range authProviders {
+		user, providerErr := provider.Authenticate(login, password)
+		if providerErr == nil {
+			if provider.Name() != "local" {
+				// Role atau instansi mungkin baru disinkronkan dari sumber akun
+				middleware.ForgetUser(int(user.Id))
+			}
+			return user, nil
+		}
+		if errors.Is(providerErr, models.ErrExternalUserNotAllowed) {
+			return nil, providerErr
+		}
+		if !errors.Is(providerErr, models.ErrInvalidCredentials) {
+			log.Printf("Auth provider %s failed: %v", provider.Name(), providerErr)
+			err = providerErr
+		}
 	}
+	return nil, err
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(jwtKey)
-	if err != nil {
+// completeLogin mencatat login yang berhasil lalu membuka sesi dan mengirim token.
+// extra ditambahkan ke response, misalnya kode pemulihan saat 2FA baru didaftarkan.
+func completeLogin(response http.ResponseWriter, request *http.Request, authenticatedUser *entities.UserDetail, extra map[string]interface{}) {
+	recordLoginAttempt(request, authenticatedUser.Email, authenticatedUser.Id, true, "")
+	if err := loginSecurityModel.ResetFailures(authenticatedUser.Id); err != nil {
+		log.Printf("Error resetting login failures for user %d: %v", authenticatedUser.Id, err)
+	}
+
+	// Nama role dan instansi sudah ikut diambil oleh Authenticate. Keduanya diperiksa
+	// sebelum sesi dibuat agar login yang ditolak tidak meninggalkan sesi yatim.
+	if authenticatedUser.RoleName == "" {
 		response.Header().Set("Content-Type", "application/json")
-		response.WriteHeader(http.StatusInternalServerError)
+		response.WriteHeader(http.StatusNotFound)
 		json.NewEncoder(response).Encode(map[string]string{
-			"error": "Could not generate token",
+			"error": "Role not found",
 		})
 		return
 	}
 
-	// Enkripsi token sebelum dikirim ke client
-	encryptedToken, err := encrypt(tokenString, encryptionKey)
-	if err != nil {
+	if authenticatedUser.InstanceName == "" {
 		response.Header().Set("Content-Type", "application/json")
-		response.WriteHeader(http.StatusInternalServerError)
+		response.WriteHeader(http.StatusNotFound)
 		json.NewEncoder(response).Encode(map[string]string{
-			"error": "Could not encrypt token",
+			"error": "Instance not found",
 		})
 		return
 	}
 
-	instanceModel := models.NewInstanceModel()
-	instance, err := instanceModel.FindInstanceById(authenticatedUser.Instance_Id)
+	permissionsList, err := permissionNames(authenticatedUser.Role_Id)
 	if err != nil {
 		response.Header().Set("Content-Type", "application/json")
 		response.WriteHeader(http.StatusNotFound)
 		json.NewEncoder(response).Encode(map[string]string{
-			"error": "Instance not found",
+			"error": "Permissions not found for this role",
+		})
+		return
+	}
+
+	// Access token berumur pendek ditambah refresh token yang disimpan di server
+	tokens, err := startSession(request, authenticatedUser, permissionsList)
+	if err != nil {
+		log.Printf("Error starting session for user %d: %v", authenticatedUser.Id, err)
+		response.Header().Set("Content-Type", "application/json")
+		response.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(response).Encode(map[string]string{
+			"error": "Could not generate token",
 		})
 		return
 	}
@@ -158,13 +204,18 @@ func Login(response http.ResponseWriter, request *http.Request) {
 		"id":          authenticatedUser.Id,
 		"name":        authenticatedUser.Name,
 		"email":       authenticatedUser.Email,
-		"instance":    instance.Name,
+		"instance":    authenticatedUser.InstanceName,
 		"instance_id": authenticatedUser.Instance_Id,
-		"role":        role.Name,
+		"role":        authenticatedUser.RoleName,
 		"role_id":     authenticatedUser.Role_Id,
 		"nip":         authenticatedUser.NIP,
 		"permissions": permissionsList,
-		"token":       encryptedToken, // Token yang sudah dienkripsi
+		"token":         tokens.AccessToken, // Token yang sudah dienkripsi
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+	}
+	for key, value := range extra {
+		jsonResponse[key] = value
 	}
 
 	response.Header().Set("Content-Type", "application/json")
@@ -175,7 +226,6 @@ func Login(response http.ResponseWriter, request *http.Request) {
 
 func DefaultTokenHandler(response http.ResponseWriter, request *http.Request) {
     // Ambil role dengan role_id = 4 dari database
-    roleModel := models.NewRoleModel()
     role, err := roleModel.FindRoleById(4)
     if err != nil {
         response.Header().Set("Content-Type", "application/json")
@@ -187,7 +237,6 @@ func DefaultTokenHandler(response http.ResponseWriter, request *http.Request) {
     }
 
     // Ambil permissions berdasarkan role_id = 4 dari database
-    permissionModel := models.NewPermissionModel()
     permissions, err := permissionModel.GetPermissionsByRole(role.Id)
     if err != nil {
         response.Header().Set("Content-Type", "application/json")
@@ -204,17 +253,28 @@ func DefaultTokenHandler(response http.ResponseWriter, request *http.Request) {
         permissionsList = append(permissionsList, permission.Name)
     }
 
+    // Session id acak untuk membedakan tamu, misalnya saat menghitung view unik
+    sessionID := make([]byte, 16)
+    if _, err := rand.Read(sessionID); err != nil {
+        response.Header().Set("Content-Type", "application/json")
+        response.WriteHeader(http.StatusInternalServerError)
+        json.NewEncoder(response).Encode(map[string]string{
+            "error": "Could not generate token",
+        })
+        return
+    }
+
     // Buat claims untuk token
     claims := jwt.MapClaims{
         "role":        role.Name,
         "role_id":     role.Id,
         "permissions": permissionsList,
+        "sid":         hex.EncodeToString(sessionID),
         "exp":         expirationTime.Unix(),
     }
 
-    // Buat token JWT
-    token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-    tokenString, err := token.SignedString(jwtKey)
+    // Tanda tangani dan enkripsi token dengan kunci aktif sebelum dikirim ke client
+    encryptedToken, err := helpers.IssueToken(claims)
     if err != nil {
         response.Header().Set("Content-Type", "application/json")
         response.WriteHeader(http.StatusInternalServerError)
@@ -224,17 +284,6 @@ func DefaultTokenHandler(response http.ResponseWriter, request *http.Request) {
         return
     }
 
-    // Enkripsi token sebelum dikirim ke client
-    encryptedToken, err := encrypt(tokenString, encryptionKey)
-    if err != nil {
-        response.Header().Set("Content-Type", "application/json")
-        response.WriteHeader(http.StatusInternalServerError)
-        json.NewEncoder(response).Encode(map[string]string{
-            "error": "Could not encrypt token",
-        })
-        return
-    }
-
     // Siapkan response
     jsonResponse := map[string]interface{}{
         "role":        role.Name,
@@ -267,38 +316,22 @@ func GetUserByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	userModel := models.NewUserModel()
-	instanceModel := models.NewInstanceModel()
-	roleModel := models.NewRoleModel()
-
+	// User, role dan instansi diambil sekaligus dalam satu query
 	user, err := userModel.FindUserByID(userID)
 	if err != nil {
 		http.Error(w, "Failed to fetch user", http.StatusInternalServerError)
 		return
 	}
 
-	instance, err := instanceModel.FindInstanceById(user.Instance_Id)
-	if err != nil {
-		http.Error(w, "Failed to fetch instance", http.StatusInternalServerError)
-		return
-	}
-
-	role, err := roleModel.FindRoleById(user.Role_Id)
-	if err != nil {
-		http.Error(w, "Failed to fetch role", http.StatusInternalServerError)
-		return
-	}
-
 	response := map[string]interface{}{
 		"id":          user.Id,
 		"name":        user.Name,
 		"nip":         user.NIP,
 		"email":       user.Email,
-		"password":    user.Password,
 		"role_id":     user.Role_Id,
-		"role_name":   role.Name,
+		"role_name":   user.RoleName,
 		"instance_id": user.Instance_Id,
-		"instance":    instance.Name,
+		"instance":    user.InstanceName,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -306,42 +339,31 @@ func GetUserByID(w http.ResponseWriter, r *http.Request) {
 }
 
 func GetAllUsers(w http.ResponseWriter, r *http.Request) {
-	users, err := userModel.FindAllUsers()
+	opts, err := parseListOptions(r, models.UserListSpec())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	users, total, err := userModel.FindAllUsers(opts)
 	if err != nil {
 		http.Error(w, `{"error": "Failed to fetch users"}`, http.StatusInternalServerError)
 		return
 	}
 
-	var response []map[string]interface{}
+	response := []map[string]interface{}{}
 	for _, user := range users {
-		instanceModel := models.NewInstanceModel()
-		roleModel := models.NewRoleModel()
-
-		instance, err := instanceModel.FindInstanceById(user.Instance_Id)
-		if err != nil {
-			http.Error(w, `{"error": "Failed to fetch instance"}`, http.StatusInternalServerError)
-			return
-		}
-
-		role, err := roleModel.FindRoleById(user.Role_Id)
-		if err != nil {
-			http.Error(w, `{"error": "Failed to fetch role"}`, http.StatusInternalServerError)
-			return
-		}
-
 		response = append(response, map[string]interface{}{
 			"id":        user.Id,
 			"name":      user.Name,
 			"nip":       user.NIP,
 			"email":     user.Email,
-			"password":  user.Password,
-			"role_name": role.Name,
-			"instance":  instance.Name,
+			"role_name": user.RoleName,
+			"instance":  user.InstanceName,
 		})
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	writePage(w, r, response, total, opts)
 }
 
 func CreateUser(w http.ResponseWriter, r *http.Request) {
@@ -358,6 +380,9 @@ func CreateUser(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Missing or invalid fields", http.StatusBadRequest)
 		return
 	}
+	if !checkNewPassword(w, 0, user.Password, user.Name, user.Email) {
+		return
+	}
 
 	newUser, err := userModel.AddUser(user)
 	if err != nil {
@@ -387,12 +412,19 @@ func EditUserById(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Password kosong berarti tidak diubah
+	if user.Password != "" && !checkNewPassword(w, userID, user.Password, user.Name, user.Email) {
+		return
+	}
+
 	user.Id = userID
 	updatedUser, err := userModel.UpdateUserById(userID, user)
 	if err != nil {
 		http.Error(w, "Failed to update user", http.StatusInternalServerError)
 		return
 	}
+	// Versi token mungkin naik; jangan pakai status yang masih tersimpan di cache
+	middleware.ForgetUser(int(userID))
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(updatedUser)
@@ -412,9 +444,47 @@ func DeleteUser(w http.ResponseWriter, r *http.Request) {
         http.Error(w, "Error deleting user: "+err.Error(), http.StatusInternalServerError)
         return
     }
+    middleware.ForgetUser(id)
 
     response := map[string]string{"message": "User deleted successfully"}
     w.Header().Set("Content-Type", "application/json")
     json.NewEncoder(w).Encode(response)
 }
 
+// SetUserExternalLink mengizinkan admin menautkan akun yang sudah ada ke login
+// LDAP/SSO dengan email yang sama. Tanpa izin ini login tersebut ditolak.
+func SetUserExternalLink(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+	var body struct {
+		Allowed bool `json:"allowed"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	found, err := userModel.SetExternalLinkAllowed(userID, body.Allowed)
+	if err != nil {
+		log.Printf("Error updating external link permission of user %d: %v", userID, err)
+		http.Error(w, "Failed to update user", http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+
+	if claims, ok := r.Context().Value(middleware.UserContextKey).(*middleware.Claims); ok {
+		log.Printf("User %d set external_link_allowed=%t for user %d", claims.ID, body.Allowed, userID)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"user_id":               userID,
+		"external_link_allowed": body.Allowed,
+	})
+}
@@ -0,0 +1,185 @@
+package controllers
+
+import (
+	"backend/entities"
+	middleware "backend/middlewares"
+	"backend/models"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+var viewModel = models.NewViewModel()
+
+// Rentang default dan maksimal deret waktu view harian
+const defaultViewSeriesDays = 30
+const maxViewSeriesDays = 366
+
+// User-Agent crawler yang tidak dihitung sebagai pembaca
+var botUserAgentMarkers = []string{"bot", "crawler", "spider", "slurp", "curl", "wget", "headless"}
+
+// viewerKey menentukan identitas pembaca untuk deduplikasi view:
+// user login memakai id-nya, tamu memakai session id dari token guest.
+func viewerKey(request *http.Request) string {
+	claims, ok := request.Context().Value(middleware.UserContextKey).(*middleware.Claims)
+	if ok && claims.ID != 0 {
+		return "u:" + strconv.Itoa(claims.ID)
+	}
+	if ok && claims.SessionID != "" {
+		return "g:" + claims.SessionID
+	}
+
+	// Token guest lama tanpa session id: pakai hash IP dan User-Agent
+	host, _, err := net.SplitHostPort(request.RemoteAddr)
+	if err != nil {
+		host = request.RemoteAddr
+	}
+	sum := sha256.Sum256([]byte(host + "|" + request.UserAgent()))
+	return "a:" + hex.EncodeToString(sum[:16])
+}
+
+func isBotRequest(request *http.Request) bool {
+	userAgent := strings.ToLower(request.UserAgent())
+	if userAgent == "" {
+		return true
+	}
+	for _, marker := range botUserAgentMarkers {
+		if strings.Contains(userAgent, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// recordContentView mencatat view unik untuk konten yang sedang dibuka ke buffer;
+// penulisan ke database dilakukan secara batch oleh StartViewCounter.
+// Konten yang belum disetujui atau sudah dihapus tidak ikut dihitung.
+func recordContentView(request *http.Request, content *entities.Content) {
+	if content.Status != "approved" || content.Deleted_at.Valid {
+		return
+	}
+	if isBotRequest(request) {
+		return
+	}
+
+	loc, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		loc = time.Local
+	}
+
+	viewModel.RecordView(content.Id, content.Instance_id, viewerKey(request), time.Now().In(loc))
+}
+
+// StartViewCounter mulai menulis buffer view ke database secara berkala
+func StartViewCounter(interval time.Duration) {
+	viewModel.StartFlusher(interval)
+}
+
+// StopViewCounter menulis sisa buffer view, dipanggil saat server dimatikan
+func StopViewCounter() error {
+	return viewModel.StopFlusher()
+}
+
+// parseDateRange membaca parameter from/to (2006-01-02). Tanpa parameter, rentangnya
+// adalah defaultDays hari terakhir; rentang lebih dari maxDays hari ditolak.
+func parseDateRange(request *http.Request, defaultDays, maxDays int) (time.Time, time.Time, bool) {
+	loc, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		loc = time.Local
+	}
+	now := time.Now().In(loc)
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	from := to.AddDate(0, 0, -(defaultDays - 1))
+
+	query := request.URL.Query()
+	if value := query.Get("from"); value != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", value, loc)
+		if err != nil {
+			return from, to, false
+		}
+		from = parsed
+	}
+	if value := query.Get("to"); value != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", value, loc)
+		if err != nil {
+			return from, to, false
+		}
+		to = parsed
+	}
+
+	if from.After(to) || to.Sub(from) > time.Duration(maxDays)*24*time.Hour {
+		return from, to, false
+	}
+	return from, to, true
+}
+
+func GetContentDailyViews(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	contentID, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid content ID", http.StatusBadRequest)
+		return
+	}
+
+	from, to, ok := parseDateRange(r, defaultViewSeriesDays, maxViewSeriesDays)
+	if !ok {
+		http.Error(w, "Invalid date range, use from/to as YYYY-MM-DD within one year", http.StatusBadRequest)
+		return
+	}
+
+	series, err := viewModel.DailyViewsByContent(contentID, from, to)
+	if err != nil {
+		log.Printf("Error fetching daily views: %v", err)
+		http.Error(w, "Failed to fetch daily views", http.StatusInternalServerError)
+		return
+	}
+
+	writeDailyViews(w, series, from, to)
+}
+
+func GetInstanceDailyViews(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	instanceID, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid instance ID", http.StatusBadRequest)
+		return
+	}
+
+	from, to, ok := parseDateRange(r, defaultViewSeriesDays, maxViewSeriesDays)
+	if !ok {
+		http.Error(w, "Invalid date range, use from/to as YYYY-MM-DD within one year", http.StatusBadRequest)
+		return
+	}
+
+	series, err := viewModel.DailyViewsByInstance(instanceID, from, to)
+	if err != nil {
+		log.Printf("Error fetching daily views: %v", err)
+		http.Error(w, "Failed to fetch daily views", http.StatusInternalServerError)
+		return
+	}
+
+	writeDailyViews(w, series, from, to)
+}
+
+func writeDailyViews(w http.ResponseWriter, series []entities.DailyViews, from, to time.Time) {
+	total := 0
+	for _, day := range series {
+		total += day.Views
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"from":   from.Format("2006-01-02"),
+		"to":     to.Format("2006-01-02"),
+		"total":  total,
+		"series": series,
+	})
+}
@@ -0,0 +1,52 @@
+package entities
+
+// StatusInstanceCount adalah jumlah konten per status per instansi
+type StatusInstanceCount struct {
+	Status       string `json:"status"`
+	InstanceID   int64  `json:"instance_id"`
+	InstanceName string `json:"instance_name"`
+	Total        int    `json:"total"`
+}
+
+// TopContent adalah konten dengan view unik terbanyak dalam rentang tanggal
+type TopContent struct {
+	ContentID    int64  `json:"content_id"`
+	Title        string `json:"title"`
+	InstanceName string `json:"instance_name"`
+	Views        int    `json:"views"`
+}
+
+// ApprovalTurnaround adalah ringkasan waktu dari konten dibuat sampai disetujui, dalam jam
+type ApprovalTurnaround struct {
+	Approved    int     `json:"approved"`
+	AverageHour float64 `json:"average_hours"`
+	MedianHour  float64 `json:"median_hours"`
+	P90Hour     float64 `json:"p90_hours"`
+	MaxHour     float64 `json:"max_hours"`
+}
+
+// Contributor adalah user dengan aktivitas edit terbanyak
+type Contributor struct {
+	UserID  int64  `json:"user_id"`
+	Name    string `json:"name"`
+	Actions int    `json:"actions"`
+	Created int    `json:"created"`
+	Edited  int    `json:"edited"`
+}
+
+// MonthlyCount adalah jumlah konten yang dibuat pada satu bulan (format 2006-01)
+type MonthlyCount struct {
+	Month string `json:"month"`
+	Total int    `json:"total"`
+}
+
+// Dashboard adalah statistik gabungan untuk halaman admin
+type Dashboard struct {
+	From               string                `json:"from"`
+	To                 string                `json:"to"`
+	ContentByStatus    []StatusInstanceCount `json:"content_by_status"`
+	TopViewed          []TopContent          `json:"top_viewed"`
+	ApprovalTurnaround ApprovalTurnaround    `json:"approval_turnaround"`
+	TopContributors    []Contributor         `json:"top_contributors"`
+	CreatedPerMonth    []MonthlyCount        `json:"created_per_month"`
+}
@@ -0,0 +1,21 @@
+package entities
+
+type Attachment struct {
+	Id            int64  `json:"id"`
+	ContentID     int64  `json:"content_id"`
+	FileName      string `json:"file_name"`
+	MimeType      string `json:"mime_type"`
+	Size          int64  `json:"size"`
+	StoragePath   string `json:"-"`
+	ExtractedText string `json:"-"`
+	UploadedBy    int64  `json:"uploaded_by"`
+	Created_at    string `json:"created_at"`
+}
+
+// AttachmentMatch adalah dokumen lampiran yang cocok dengan kata kunci pencarian
+type AttachmentMatch struct {
+	AttachmentID int64  `json:"attachment_id"`
+	ContentID    int64  `json:"content_id"`
+	FileName     string `json:"file_name"`
+	Snippet      string `json:"snippet"`
+}
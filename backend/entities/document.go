@@ -0,0 +1,12 @@
+package entities
+
+// ContentDocument adalah teks konten yang dipakai untuk menghitung kemiripan antar artikel
+type ContentDocument struct {
+	Id            int64
+	Title         string
+	Description   string
+	Tag           string
+	Subheadings   string
+	InstanceID    int64
+	Accessibility string
+}
@@ -0,0 +1,13 @@
+package entities
+
+// ExternalUser adalah identitas user dari sumber akun di luar tabel user, misalnya
+// direktori LDAP atau SSO. Subject adalah id tetap di sumber tersebut (DN LDAP
+// atau claim "sub" OIDC) dan Groups dipakai untuk menentukan role dan instansi.
+type ExternalUser struct {
+	Provider string
+	Subject  string
+	Email    string
+	Name     string
+	NIP      int64
+	Groups   []string
+}
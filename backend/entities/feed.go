@@ -0,0 +1,13 @@
+package entities
+
+// FeedItem adalah konten publik untuk sitemap dan feed RSS/Atom
+type FeedItem struct {
+	Id           int64
+	Title        string
+	Description  string
+	Tag          string
+	InstanceName string
+	AuthorName   string
+	PublishedAt  string
+	Updated_at   string
+}
@@ -6,4 +6,6 @@ type History struct {
 	Editor_Id  int64  `json:"editor_id"`
 	Edited_at  string `json:"edited_at"`
 	Action  string `json:"action"`
+	// Content_Title hanya diisi oleh GetHistoriesByUserId
+	Content_Title string `json:"content_title,omitempty"`
 }
\ No newline at end of file
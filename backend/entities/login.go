@@ -0,0 +1,33 @@
+package entities
+
+// LoginAttempt adalah satu baris audit percobaan login
+type LoginAttempt struct {
+	Id          int64  `json:"id"`
+	Email       string `json:"email"`
+	UserID      *int64 `json:"user_id"`
+	IPAddress   string `json:"ip_address"`
+	UserAgent   string `json:"user_agent"`
+	Success     bool   `json:"success"`
+	Reason      string `json:"reason"`
+	AttemptedAt string `json:"attempted_at"`
+}
+
+// LockedAccount adalah akun yang sedang dikunci karena terlalu banyak gagal login
+type LockedAccount struct {
+	UserID       int64  `json:"user_id"`
+	Name         string `json:"name"`
+	Email        string `json:"email"`
+	FailedCount  int    `json:"failed_count"`
+	LockoutCount int    `json:"lockout_count"`
+	LockedUntil  string `json:"locked_until"`
+}
+
+// AdminNotification adalah pemberitahuan keamanan untuk administrator
+type AdminNotification struct {
+	Id        int64   `json:"id"`
+	Kind      string  `json:"kind"`
+	Message   string  `json:"message"`
+	UserID    *int64  `json:"user_id"`
+	CreatedAt string  `json:"created_at"`
+	ReadAt    *string `json:"read_at"`
+}
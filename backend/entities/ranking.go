@@ -0,0 +1,12 @@
+package entities
+
+// RankedContent adalah konten beserta skor peringkat untuk daftar trending/populer
+type RankedContent struct {
+	Id            int64   `json:"id"`
+	Title         string  `json:"title"`
+	Tag           string  `json:"tag"`
+	InstanceID    int64   `json:"instance_id"`
+	Accessibility string  `json:"-"`
+	ViewCount     int     `json:"view_count"`
+	Score         float64 `json:"score"`
+}
@@ -0,0 +1,14 @@
+package entities
+
+// Session adalah satu sesi login (satu perangkat) milik user
+type Session struct {
+	Id         string  `json:"id"`
+	UserID     int64   `json:"user_id"`
+	Device     string  `json:"device"`
+	UserAgent  string  `json:"user_agent"`
+	IPAddress  string  `json:"ip_address"`
+	CreatedAt  string  `json:"created_at"`
+	LastSeenAt string  `json:"last_seen_at"`
+	RevokedAt  *string `json:"revoked_at,omitempty"`
+	Current    bool    `json:"current"`
+}
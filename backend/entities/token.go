@@ -0,0 +1,24 @@
+package entities
+
+// RefreshToken adalah satu refresh token dalam sebuah family (satu sesi login).
+// Nilai token asli tidak disimpan, hanya hash-nya.
+type RefreshToken struct {
+	Id        int64
+	UserID    int64
+	FamilyID  string
+	ParentID  *int64
+	TokenHash string
+	CreatedAt string
+	ExpiresAt string
+	UsedAt    *string
+	RevokedAt *string
+}
+
+// TokenKey adalah informasi kunci token di keyring tanpa isi kuncinya
+type TokenKey struct {
+	Kid       string  `json:"kid"`
+	Status    string  `json:"status"`
+	CreatedAt string  `json:"created_at"`
+	RotatedAt *string `json:"rotated_at,omitempty"`
+	RetiredAt *string `json:"retired_at,omitempty"`
+}
@@ -0,0 +1,18 @@
+package entities
+
+// UserTOTP adalah pendaftaran TOTP milik user. Secret sudah dibuka dan tidak
+// pernah dikirim ke client selain saat pendaftaran.
+type UserTOTP struct {
+	UserID       int64
+	Secret       []byte
+	Enabled      bool
+	LastUsedStep int64
+}
+
+// TwoFactorStatus adalah status 2FA yang ditampilkan ke user
+type TwoFactorStatus struct {
+	Enabled                bool `json:"enabled"`
+	Pending                bool `json:"pending"`
+	Required               bool `json:"required"`
+	RecoveryCodesRemaining int  `json:"recovery_codes_remaining"`
+}
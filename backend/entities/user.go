@@ -2,13 +2,26 @@ package entities
 
 import "database/sql"
 
+// User adalah akun wiki. Password hanya diisi dari request (login, tambah/ubah user)
+// dan tidak pernah dikirim balik ke client. TokenVersion naik setiap kali token
+// lama user harus ditolak.
 type User struct {
-	Id          int64        `json:"id"`
-	Name        string       `json:"name"`
-	NIP         int64        `json:"nip"`
-	Email       string       `json:"email"`
-	Password    string       `json:"password"`
-	Role_Id     int64        `json:"role_id"`
-	Instance_Id int64        `json:"instance_id"`
-	Deleted_at  sql.NullTime `json:"deleted_at"`
-}
\ No newline at end of file
+	Id           int64        `json:"id"`
+	Name         string       `json:"name"`
+	NIP          int64        `json:"nip"`
+	Email        string       `json:"email"`
+	Password     string       `json:"password,omitempty"`
+	Role_Id      int64        `json:"role_id"`
+	Instance_Id  int64        `json:"instance_id"`
+	Deleted_at   sql.NullTime `json:"deleted_at"`
+	TokenVersion int64        `json:"-"`
+	// AuthProvider adalah sumber akun: "local", "ldap" atau "oidc"
+	AuthProvider string `json:"auth_provider,omitempty"`
+}
+
+// UserDetail adalah user beserta nama role dan nama instansinya
+type UserDetail struct {
+	User
+	RoleName     string `json:"role_name"`
+	InstanceName string `json:"instance"`
+}
@@ -0,0 +1,7 @@
+package entities
+
+// DailyViews adalah jumlah view unik pada satu tanggal (format 2006-01-02)
+type DailyViews struct {
+	Date  string `json:"date"`
+	Views int    `json:"views"`
+}
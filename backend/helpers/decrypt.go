@@ -1,67 +1,25 @@
 package helpers
 
 import (
-
-	"crypto/aes"
-	"crypto/cipher"
-	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-var JwtKey = []byte("wiki") 
-var EncryptionKey = []byte("myverystrongpasswordo32bitlength") // Harus 32 byte
-
-// Fungsi untuk mendekripsi token
-func decrypt(encryptedText string, key []byte) (string, error) {
-	ciphertext, err := base64.StdEncoding.DecodeString(encryptedText)
-	if err != nil {
-		return "", err
-	}
-
-	block, err := aes.NewCipher(key)
-	if err != nil {
-		return "", err
-	}
-
-	aesGCM, err := cipher.NewGCM(block)
-	if err != nil {
-		return "", err
-	}
-
-	nonceSize := aesGCM.NonceSize()
-	nonce, ciphertext := ciphertext[:nonceSize], ciphertext[nonceSize:]
-
-	plaintext, err := aesGCM.Open(nil, nonce, ciphertext, nil)
-	if err != nil {
-		return "", err
-	}
-
-	return string(plaintext), nil
-}
-
-// Fungsi untuk mendecode JWT Token
-func decodeJWTToken(tokenString string) (jwt.MapClaims, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		// Pastikan hanya menerima signing method yang valid
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method")
-		}
-		return JwtKey, nil
-	})
-
+// decodeJWTToken mendekripsi token dan memverifikasi tanda tangannya lewat keyring
+func decodeJWTToken(encryptedToken string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	token, err := ParseToken(encryptedToken, claims)
 	if err != nil {
 		return nil, err
 	}
-
-	// Validasi token dan klaim
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		return claims, nil
+	if !token.Valid {
+		return nil, fmt.Errorf("invalid token")
 	}
-	return nil, fmt.Errorf("invalid token")
+	return claims, nil
 }
 
 // Endpoint untuk mendapatkan isi JWT setelah dekripsi
@@ -81,9 +39,9 @@ func GetDecodedJWT(response http.ResponseWriter, request *http.Request) {
 		return
 	}
 
-	// Dekripsi token
-	decryptedToken, err := decrypt(requestData.EncryptedToken, EncryptionKey)
-	if err != nil {
+	// Dekripsi dan decode JWT Token
+	claims, err := decodeJWTToken(requestData.EncryptedToken)
+	if errors.Is(err, ErrTokenDecrypt) {
 		response.Header().Set("Content-Type", "application/json")
 		response.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(response).Encode(map[string]string{
@@ -91,9 +49,6 @@ func GetDecodedJWT(response http.ResponseWriter, request *http.Request) {
 		})
 		return
 	}
-
-	// Decode JWT Token
-	claims, err := decodeJWTToken(decryptedToken)
 	if err != nil {
 		response.Header().Set("Content-Type", "application/json")
 		response.WriteHeader(http.StatusUnauthorized)
@@ -0,0 +1,197 @@
+package helpers
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// Jenis dokumen yang bisa diekstrak teksnya
+var AttachmentMimeTypes = map[string]string{
+	".pdf":  "application/pdf",
+	".docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	".txt":  "text/plain",
+}
+
+// ExtractText mengambil teks polos dari dokumen PDF, DOCX, atau TXT berdasarkan ekstensi file
+func ExtractText(fileName string, data []byte) (string, error) {
+	var text string
+	var err error
+
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".pdf":
+		text, err = extractPDF(data)
+	case ".docx":
+		text, err = extractDOCX(data)
+	case ".txt":
+		if !utf8.Valid(data) {
+			return "", fmt.Errorf("text file is not valid UTF-8")
+		}
+		text = string(data)
+	default:
+		return "", fmt.Errorf("unsupported file type: %s", filepath.Ext(fileName))
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return normalizeWhitespace(text), nil
+}
+
+func extractPDF(data []byte) (text string, err error) {
+	// Library pdf bisa panic untuk file yang rusak
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("failed to parse pdf: %v", r)
+		}
+	}()
+
+	reader, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", fmt.Errorf("failed to open pdf: %w", err)
+	}
+
+	plain, err := reader.GetPlainText()
+	if err != nil {
+		return "", fmt.Errorf("failed to read pdf text: %w", err)
+	}
+
+	content, err := io.ReadAll(plain)
+	if err != nil {
+		return "", fmt.Errorf("failed to read pdf text: %w", err)
+	}
+	return string(content), nil
+}
+
+func extractDOCX(data []byte) (string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", fmt.Errorf("failed to open docx: %w", err)
+	}
+
+	var document *zip.File
+	for _, file := range archive.File {
+		if file.Name == "word/document.xml" {
+			document = file
+			break
+		}
+	}
+	if document == nil {
+		return "", fmt.Errorf("docx has no word/document.xml")
+	}
+
+	rc, err := document.Open()
+	if err != nil {
+		return "", fmt.Errorf("failed to read docx: %w", err)
+	}
+	defer rc.Close()
+
+	// Ambil isi elemen <w:t>, pisahkan paragraf <w:p> dengan baris baru
+	var builder strings.Builder
+	decoder := xml.NewDecoder(rc)
+	inText := false
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to parse docx: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				builder.WriteString("\t")
+			case "br":
+				builder.WriteString("\n")
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				builder.WriteString("\n")
+			}
+		case xml.CharData:
+			if inText {
+				builder.Write(t)
+			}
+		}
+	}
+
+	return builder.String(), nil
+}
+
+// normalizeWhitespace merapikan spasi berlebih tanpa menghilangkan baris baru
+func normalizeWhitespace(text string) string {
+	lines := strings.Split(text, "\n")
+	cleaned := make([]string, 0, len(lines))
+	for _, line := range lines {
+		line = strings.Join(strings.FieldsFunc(line, unicode.IsSpace), " ")
+		if line != "" {
+			cleaned = append(cleaned, line)
+		}
+	}
+	return strings.Join(cleaned, "\n")
+}
+
+// Snippet mengambil potongan teks di sekitar kemunculan pertama salah satu kata kunci
+func Snippet(text string, terms []string, radius int) string {
+	lower := strings.ToLower(text)
+	position := -1
+	matchLen := 0
+	for _, term := range terms {
+		term = strings.ToLower(strings.TrimSpace(term))
+		if term == "" {
+			continue
+		}
+		if index := strings.Index(lower, term); index >= 0 && (position < 0 || index < position) {
+			position = index
+			matchLen = len(term)
+		}
+	}
+	if position < 0 {
+		position = 0
+	}
+
+	start := position - radius
+	if start < 0 {
+		start = 0
+	}
+	if start > len(text) {
+		start = len(text)
+	}
+	end := position + matchLen + radius
+	if end > len(text) {
+		end = len(text)
+	}
+
+	// Jangan memotong di tengah karakter multibyte
+	for start > 0 && start < len(text) && !utf8.RuneStart(text[start]) {
+		start--
+	}
+	for end < len(text) && !utf8.RuneStart(text[end]) {
+		end++
+	}
+
+	snippet := strings.ReplaceAll(text[start:end], "\n", " ")
+	if start > 0 {
+		snippet = "..." + snippet
+	}
+	if end < len(text) {
+		snippet = snippet + "..."
+	}
+	return snippet
+}
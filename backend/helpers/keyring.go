@@ -0,0 +1,290 @@
+package helpers
+
+import (
+	"backend/config"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// TokenKey adalah sepasang kunci token dengan id (kid): kunci HMAC untuk tanda
+// tangan JWT dan kunci AES-GCM untuk enkripsi token yang dikirim ke client.
+// Hanya satu kunci yang aktif (dipakai menerbitkan token); kunci lain hanya
+// dipakai memverifikasi token lama sampai dipensiunkan.
+type TokenKey struct {
+	ID            string
+	JWTKey        []byte
+	EncryptionKey []byte
+	Active        bool
+}
+
+// LegacyKeyID adalah kid kunci dari konfigurasi (JWT_KEY dan ENCRYPTION_KEY).
+// Token tanpa prefix kid diterbitkan sebelum keyring ada dan diverifikasi dengan kunci ini.
+const LegacyKeyID = "legacy"
+
+var (
+	ErrUnknownTokenKey = errors.New("token was issued with an unknown or retired key")
+	ErrTokenDecrypt    = errors.New("failed to decrypt token")
+)
+
+// Keyring dimuat ulang berkala dan saat token memakai kid yang belum dikenal,
+// agar rotasi dari server lain (atau CLI) ikut berlaku
+const keyringReloadThrottle = 10 * time.Second
+
+type keyring struct {
+	active *TokenKey
+	keys   map[string]*TokenKey
+}
+
+var (
+	currentKeyring atomic.Pointer[keyring]
+	keyLoader      func() ([]TokenKey, error)
+	keyReloadMu    sync.Mutex
+	lastKeyReload  time.Time
+)
+
+// legacyKeyring dipakai sebelum StartKeyring dipanggil
+func legacyKeyring() *keyring {
+	cfg := config.Get()
+	key := &TokenKey{
+		ID:            LegacyKeyID,
+		JWTKey:        []byte(cfg.JWTKey),
+		EncryptionKey: []byte(cfg.EncryptionKey),
+		Active:        true,
+	}
+	return &keyring{active: key, keys: map[string]*TokenKey{key.ID: key}}
+}
+
+func currentKeys() *keyring {
+	if ring := currentKeyring.Load(); ring != nil {
+		return ring
+	}
+	ring := legacyKeyring()
+	currentKeyring.CompareAndSwap(nil, ring)
+	return currentKeyring.Load()
+}
+
+// InstallTokenKeys mengganti isi keyring. Harus ada tepat satu kunci aktif.
+func InstallTokenKeys(keys []TokenKey) error {
+	ring := &keyring{keys: map[string]*TokenKey{}}
+	for i := range keys {
+		key := keys[i]
+		if key.ID == "" || strings.Contains(key.ID, ".") {
+			return fmt.Errorf("invalid token key id %q", key.ID)
+		}
+		if len(key.JWTKey) == 0 {
+			return fmt.Errorf("token key %s has an empty signing key", key.ID)
+		}
+		switch len(key.EncryptionKey) {
+		case 16, 24, 32:
+		default:
+			return fmt.Errorf("token key %s has an invalid encryption key length", key.ID)
+		}
+		if key.Active {
+			if ring.active != nil {
+				return fmt.Errorf("more than one active token key")
+			}
+			ring.active = &key
+		}
+		ring.keys[key.ID] = &key
+	}
+	if ring.active == nil {
+		return fmt.Errorf("no active token key")
+	}
+	currentKeyring.Store(ring)
+	return nil
+}
+
+// StartKeyring memuat keyring lewat loader lalu memuatnya ulang setiap interval
+func StartKeyring(loader func() ([]TokenKey, error), interval time.Duration) error {
+	keyReloadMu.Lock()
+	keyLoader = loader
+	keyReloadMu.Unlock()
+
+	if err := ReloadTokenKeys(); err != nil {
+		return err
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := ReloadTokenKeys(); err != nil {
+				log.Printf("Failed to reload token keys: %v", err)
+			}
+		}
+	}()
+	return nil
+}
+
+// ReloadTokenKeys membaca ulang keyring dari loader, misalnya setelah rotasi
+func ReloadTokenKeys() error {
+	keyReloadMu.Lock()
+	defer keyReloadMu.Unlock()
+	return reloadLocked()
+}
+
+func reloadLocked() error {
+	if keyLoader == nil {
+		return nil
+	}
+	lastKeyReload = time.Now()
+	keys, err := keyLoader()
+	if err != nil {
+		return err
+	}
+	return InstallTokenKeys(keys)
+}
+
+// findTokenKey mencari kunci berdasarkan kid; kid yang belum dikenal memicu
+// muat ulang keyring paling sering sekali per keyringReloadThrottle
+func findTokenKey(kid string) (*TokenKey, bool) {
+	if key, ok := currentKeys().keys[kid]; ok {
+		return key, true
+	}
+
+	keyReloadMu.Lock()
+	if keyLoader != nil && time.Since(lastKeyReload) >= keyringReloadThrottle {
+		if err := reloadLocked(); err != nil {
+			log.Printf("Failed to reload token keys: %v", err)
+		}
+	}
+	keyReloadMu.Unlock()
+
+	key, ok := currentKeys().keys[kid]
+	return key, ok
+}
+
+// ActiveTokenKeyID mengembalikan kid yang sedang dipakai menerbitkan token
+func ActiveTokenKeyID() string {
+	return currentKeys().active.ID
+}
+
+// IssueToken menandatangani claims dengan kunci aktif lalu mengenkripsinya.
+// Hasilnya berformat "<kid>.<token terenkripsi base64>".
+func IssueToken(claims jwt.Claims) (string, error) {
+	key := currentKeys().active
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token.Header["kid"] = key.ID
+	tokenString, err := token.SignedString(key.JWTKey)
+	if err != nil {
+		return "", fmt.Errorf("could not generate token: %w", err)
+	}
+
+	encrypted, err := sealWithKey(key.EncryptionKey, []byte(tokenString))
+	if err != nil {
+		return "", fmt.Errorf("could not encrypt token: %w", err)
+	}
+	return key.ID + "." + encrypted, nil
+}
+
+// ParseToken mendekripsi token dari client dan memverifikasi tanda tangannya
+// dengan kunci sesuai kid. Token tanpa prefix kid memakai kunci legacy.
+func ParseToken(encryptedToken string, claims jwt.Claims) (*jwt.Token, error) {
+	kid, payload, found := strings.Cut(encryptedToken, ".")
+	if !found {
+		kid, payload = LegacyKeyID, encryptedToken
+	}
+
+	key, ok := findTokenKey(kid)
+	if !ok {
+		return nil, ErrUnknownTokenKey
+	}
+
+	plaintext, err := openWithKey(key.EncryptionKey, payload)
+	if err != nil {
+		return nil, ErrTokenDecrypt
+	}
+
+	return jwt.ParseWithClaims(string(plaintext), claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		// Token lama tidak punya header kid; token baru harus cocok dengan prefix-nya
+		if headerKid, ok := token.Header["kid"]; (ok || found) && headerKid != key.ID {
+			return nil, fmt.Errorf("token key id mismatch")
+		}
+		return key.JWTKey, nil
+	})
+}
+
+// GenerateTokenKey membuat pasangan kunci acak baru dengan kid berbasis tanggal
+func GenerateTokenKey() (TokenKey, error) {
+	suffix := make([]byte, 4)
+	jwtKey := make([]byte, 32)
+	encryptionKey := make([]byte, 32)
+	for _, buffer := range [][]byte{suffix, jwtKey, encryptionKey} {
+		if _, err := rand.Read(buffer); err != nil {
+			return TokenKey{}, err
+		}
+	}
+	return TokenKey{
+		ID:            time.Now().Format("20060102") + "-" + hex.EncodeToString(suffix),
+		JWTKey:        jwtKey,
+		EncryptionKey: encryptionKey,
+	}, nil
+}
+
+// SealSecret mengenkripsi kunci token sebelum disimpan di database dengan
+// ENCRYPTION_KEY dari konfigurasi sebagai master key
+func SealSecret(secret []byte) (string, error) {
+	return sealWithKey([]byte(config.Get().EncryptionKey), secret)
+}
+
+// OpenSecret adalah kebalikan SealSecret
+func OpenSecret(sealed string) ([]byte, error) {
+	return openWithKey([]byte(config.Get().EncryptionKey), sealed)
+}
+
+func sealWithKey(key, plaintext []byte) (string, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", err
+	}
+	aesGCM, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aesGCM.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	ciphertext := aesGCM.Seal(nonce, nonce, plaintext, nil)
+	return base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+func openWithKey(key []byte, encoded string) ([]byte, error) {
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aesGCM, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	nonceSize := aesGCM.NonceSize()
+	if len(decoded) < nonceSize {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	return aesGCM.Open(nil, decoded[:nonceSize], decoded[nonceSize:], nil)
+}
@@ -0,0 +1,116 @@
+package helpers
+
+import (
+	"backend/config"
+	"backend/entities"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// ErrDirectoryInvalidCredentials dikembalikan jika user tidak ditemukan di direktori
+// atau password-nya salah
+var ErrDirectoryInvalidCredentials = errors.New("invalid directory credentials")
+
+// LDAPDirectory memverifikasi login terhadap server LDAP/Active Directory: bind
+// dengan akun layanan, cari user berdasarkan email atau NIP, lalu bind ulang
+// sebagai user tersebut dengan password yang dimasukkan.
+type LDAPDirectory struct {
+	Config config.LDAPConfig
+}
+
+func (d LDAPDirectory) dial() (*ldap.Conn, error) {
+	timeout := time.Duration(d.Config.TimeoutSeconds) * time.Second
+	tlsConfig := &tls.Config{InsecureSkipVerify: d.Config.InsecureSkipVerify}
+	if host, _, err := net.SplitHostPort(strings.TrimPrefix(strings.TrimPrefix(d.Config.URL, "ldaps://"), "ldap://")); err == nil {
+		tlsConfig.ServerName = host
+	}
+
+	conn, err := ldap.DialURL(d.Config.URL,
+		ldap.DialWithDialer(&net.Dialer{Timeout: timeout}),
+		ldap.DialWithTLSConfig(tlsConfig))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to LDAP server: %w", err)
+	}
+	conn.SetTimeout(timeout)
+
+	if d.Config.StartTLS {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to start TLS with LDAP server: %w", err)
+		}
+	}
+	return conn, nil
+}
+
+// Authenticate mencari user berdasarkan email atau NIP lalu memeriksa password-nya
+func (d LDAPDirectory) Authenticate(login, password string) (*entities.ExternalUser, error) {
+	// Bind dengan password kosong adalah bind anonim yang selalu berhasil di banyak server
+	if strings.TrimSpace(login) == "" || password == "" {
+		return nil, ErrDirectoryInvalidCredentials
+	}
+
+	conn, err := d.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if d.Config.BindDN != "" {
+		err = conn.Bind(d.Config.BindDN, d.Config.BindPassword)
+	} else {
+		err = conn.UnauthenticatedBind("")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to bind LDAP service account: %w", err)
+	}
+
+	attributes := []string{d.Config.EmailAttribute, d.Config.NameAttribute}
+	if d.Config.NIPAttribute != "" {
+		attributes = append(attributes, d.Config.NIPAttribute)
+	}
+	if d.Config.GroupAttribute != "" {
+		attributes = append(attributes, d.Config.GroupAttribute)
+	}
+	filter := strings.ReplaceAll(d.Config.UserFilter, "{login}", ldap.EscapeFilter(strings.TrimSpace(login)))
+	result, err := conn.Search(ldap.NewSearchRequest(
+		d.Config.BaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
+		2, d.Config.TimeoutSeconds, false, filter, attributes, nil))
+	if err != nil && !ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+		return nil, fmt.Errorf("failed to search LDAP user: %w", err)
+	}
+	// Lebih dari satu hasil berarti filter tidak unik; lebih aman ditolak
+	if result == nil || len(result.Entries) != 1 {
+		return nil, ErrDirectoryInvalidCredentials
+	}
+	entry := result.Entries[0]
+
+	if err := conn.Bind(entry.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, ErrDirectoryInvalidCredentials
+		}
+		return nil, fmt.Errorf("failed to bind LDAP user: %w", err)
+	}
+
+	user := &entities.ExternalUser{
+		Provider: "ldap",
+		Subject:  entry.DN,
+		Email:    strings.ToLower(entry.GetAttributeValue(d.Config.EmailAttribute)),
+		Name:     entry.GetAttributeValue(d.Config.NameAttribute),
+		Groups:   entry.GetAttributeValues(d.Config.GroupAttribute),
+	}
+	if user.Email == "" {
+		return nil, fmt.Errorf("LDAP user %s has no %s attribute", entry.DN, d.Config.EmailAttribute)
+	}
+	if d.Config.NIPAttribute != "" {
+		// NIP yang tidak berupa angka dibiarkan 0
+		user.NIP, _ = strconv.ParseInt(strings.TrimSpace(entry.GetAttributeValue(d.Config.NIPAttribute)), 10, 64)
+	}
+	return user, nil
+}
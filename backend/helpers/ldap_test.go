@@ -0,0 +1,361 @@
+package helpers
+
+import (
+	"backend/config"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+)
+
+// ldapStubEntry adalah satu user di direktori tiruan
+type ldapStubEntry struct {
+	dn         string
+	password   string
+	attributes map[string][]string
+}
+
+// ldapStub adalah server LDAP minimal di proses yang sama: bind sederhana, search
+// dengan filter and/or/not/equality/present, dan unbind. Search hanya dilayani
+// setelah bind sebagai akun layanan, seperti direktori yang menolak bind anonim.
+type ldapStub struct {
+	listener        net.Listener
+	serviceDN       string
+	servicePassword string
+	entries         []ldapStubEntry
+
+	mu          sync.Mutex
+	connections int
+	binds       []string
+	searches    []string
+}
+
+func startLDAPStub(t *testing.T, entries ...ldapStubEntry) *ldapStub {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stub := &ldapStub{
+		listener:        listener,
+		serviceDN:       "cn=wiki-service,ou=services,dc=jogjaprov,dc=go,dc=id",
+		servicePassword: "rahasia-layanan",
+		entries:         entries,
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go stub.serve(conn)
+		}
+	}()
+	return stub
+}
+
+func (s *ldapStub) config() config.LDAPConfig {
+	return config.LDAPConfig{
+		URL:            "ldap://" + s.listener.Addr().String(),
+		BindDN:         s.serviceDN,
+		BindPassword:   s.servicePassword,
+		BaseDN:         "ou=people,dc=jogjaprov,dc=go,dc=id",
+		UserFilter:     "(&(objectClass=person)(|(mail={login})(employeeNumber={login})))",
+		EmailAttribute: "mail",
+		NameAttribute:  "cn",
+		NIPAttribute:   "employeeNumber",
+		GroupAttribute: "memberOf",
+		TimeoutSeconds: 5,
+	}
+}
+
+func (s *ldapStub) serve(conn net.Conn) {
+	defer conn.Close()
+	s.mu.Lock()
+	s.connections++
+	s.mu.Unlock()
+
+	boundDN := ""
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil || len(packet.Children) < 2 {
+			return
+		}
+		messageID := packet.Children[0].Value.(int64)
+		op := packet.Children[1]
+
+		switch op.Tag {
+		case ber.Tag(0): // BindRequest
+			dn := op.Children[1].Data.String()
+			password := op.Children[2].Data.String()
+			s.mu.Lock()
+			s.binds = append(s.binds, dn)
+			s.mu.Unlock()
+
+			code := int64(49) // invalidCredentials
+			if s.checkPassword(dn, password) {
+				code = 0
+				boundDN = dn
+			}
+			conn.Write(ldapMessage(messageID, ldapResult(1, code)).Bytes())
+		case ber.Tag(3): // SearchRequest
+			if boundDN != s.serviceDN {
+				conn.Write(ldapMessage(messageID, ldapResult(5, 50)).Bytes()) // insufficientAccessRights
+				continue
+			}
+			sizeLimit := int(op.Children[3].Value.(int64))
+			filter := op.Children[6]
+			s.mu.Lock()
+			s.searches = append(s.searches, describeFilter(filter))
+			s.mu.Unlock()
+
+			sent := 0
+			code := int64(0)
+			for _, entry := range s.entries {
+				if !matchFilter(filter, entry) {
+					continue
+				}
+				if sizeLimit > 0 && sent == sizeLimit {
+					code = 4 // sizeLimitExceeded
+					break
+				}
+				conn.Write(ldapMessage(messageID, ldapEntry(entry)).Bytes())
+				sent++
+			}
+			conn.Write(ldapMessage(messageID, ldapResult(5, code)).Bytes())
+		case ber.Tag(2): // UnbindRequest
+			return
+		}
+	}
+}
+
+func (s *ldapStub) checkPassword(dn, password string) bool {
+	if dn == s.serviceDN {
+		return password == s.servicePassword
+	}
+	for _, entry := range s.entries {
+		if strings.EqualFold(entry.dn, dn) {
+			return password != "" && password == entry.password
+		}
+	}
+	return false
+}
+
+func ldapMessage(messageID int64, op *ber.Packet) *ber.Packet {
+	packet := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Message")
+	packet.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, messageID, "MessageID"))
+	packet.AppendChild(op)
+	return packet
+}
+
+func ldapResult(tag ber.Tag, code int64) *ber.Packet {
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "Result")
+	op.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, code, "resultCode"))
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "matchedDN"))
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "diagnosticMessage"))
+	return op
+}
+
+func ldapEntry(entry ldapStubEntry) *ber.Packet {
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, 4, nil, "SearchResultEntry")
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, entry.dn, "objectName"))
+	attributes := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "attributes")
+	for name, values := range entry.attributes {
+		attribute := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "attribute")
+		attribute.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "type"))
+		set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "values")
+		for _, value := range values {
+			set.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, "value"))
+		}
+		attribute.AppendChild(set)
+		attributes.AppendChild(attribute)
+	}
+	op.AppendChild(attributes)
+	return op
+}
+
+// matchFilter mengevaluasi filter and (0), or (1), not (2), equality (3) dan present (7)
+func matchFilter(filter *ber.Packet, entry ldapStubEntry) bool {
+	switch filter.Tag {
+	case 0:
+		for _, child := range filter.Children {
+			if !matchFilter(child, entry) {
+				return false
+			}
+		}
+		return true
+	case 1:
+		for _, child := range filter.Children {
+			if matchFilter(child, entry) {
+				return true
+			}
+		}
+		return false
+	case 2:
+		return !matchFilter(filter.Children[0], entry)
+	case 3:
+		want := filter.Children[1].Data.String()
+		for _, value := range entry.values(filter.Children[0].Data.String()) {
+			if strings.EqualFold(value, want) {
+				return true
+			}
+		}
+		return false
+	case 7:
+		return len(entry.values(filter.Data.String())) > 0
+	}
+	return false
+}
+
+// describeFilter menulis ulang nilai equality di filter, untuk memeriksa escaping
+func describeFilter(filter *ber.Packet) string {
+	if filter.Tag == 3 {
+		return filter.Children[0].Data.String() + "=" + filter.Children[1].Data.String()
+	}
+	parts := []string{}
+	for _, child := range filter.Children {
+		parts = append(parts, describeFilter(child))
+	}
+	return strings.Join(parts, " ")
+}
+
+func (e ldapStubEntry) values(attribute string) []string {
+	for name, values := range e.attributes {
+		if strings.EqualFold(name, attribute) {
+			return values
+		}
+	}
+	return nil
+}
+
+var ldapBudi = ldapStubEntry{
+	dn:       "uid=budi,ou=people,dc=jogjaprov,dc=go,dc=id",
+	password: "Merapi-2024",
+	attributes: map[string][]string{
+		"objectClass":    {"person"},
+		"mail":           {"Budi.Santoso@jogjaprov.go.id"},
+		"cn":             {"Budi Santoso"},
+		"employeeNumber": {"198703122010011002"},
+		"memberOf":       {"CN=Editor Wiki,OU=Groups,DC=jogjaprov,DC=go,DC=id", "CN=Diskominfo,OU=Instansi,DC=jogjaprov,DC=go,DC=id"},
+	},
+}
+
+var ldapSari = ldapStubEntry{
+	dn:       "uid=sari,ou=people,dc=jogjaprov,dc=go,dc=id",
+	password: "Prambanan-99",
+	attributes: map[string][]string{
+		"objectClass":    {"person"},
+		"mail":           {"sari@jogjaprov.go.id"},
+		"cn":             {"Sari Wulandari"},
+		"employeeNumber": {"199001012015022001"},
+	},
+}
+
+func TestLDAPAuthenticateByEmail(t *testing.T) {
+	stub := startLDAPStub(t, ldapBudi, ldapSari)
+
+	user, err := LDAPDirectory{Config: stub.config()}.Authenticate("budi.santoso@jogjaprov.go.id", "Merapi-2024")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Provider != "ldap" || user.Subject != ldapBudi.dn {
+		t.Errorf("identity = %s %s", user.Provider, user.Subject)
+	}
+	if user.Email != "budi.santoso@jogjaprov.go.id" || user.Name != "Budi Santoso" || user.NIP != 198703122010011002 {
+		t.Errorf("user = %+v", user)
+	}
+	if len(user.Groups) != 2 {
+		t.Errorf("groups = %v", user.Groups)
+	}
+	// Akun layanan bind lebih dulu, baru user yang ditemukan
+	if len(stub.binds) != 2 || stub.binds[0] != stub.serviceDN || stub.binds[1] != ldapBudi.dn {
+		t.Errorf("binds = %v", stub.binds)
+	}
+}
+
+func TestLDAPAuthenticateByNIP(t *testing.T) {
+	stub := startLDAPStub(t, ldapBudi, ldapSari)
+
+	user, err := LDAPDirectory{Config: stub.config()}.Authenticate(" 199001012015022001 ", "Prambanan-99")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Subject != ldapSari.dn || user.Email != "sari@jogjaprov.go.id" {
+		t.Errorf("user = %+v", user)
+	}
+}
+
+func TestLDAPAuthenticateRejectsWrongPassword(t *testing.T) {
+	stub := startLDAPStub(t, ldapBudi)
+
+	_, err := LDAPDirectory{Config: stub.config()}.Authenticate("budi.santoso@jogjaprov.go.id", "salah")
+	if !errors.Is(err, ErrDirectoryInvalidCredentials) {
+		t.Fatalf("err = %v, want ErrDirectoryInvalidCredentials", err)
+	}
+}
+
+func TestLDAPAuthenticateRejectsUnknownUser(t *testing.T) {
+	stub := startLDAPStub(t, ldapBudi)
+
+	_, err := LDAPDirectory{Config: stub.config()}.Authenticate("tidak.ada@jogjaprov.go.id", "Merapi-2024")
+	if !errors.Is(err, ErrDirectoryInvalidCredentials) {
+		t.Fatalf("err = %v, want ErrDirectoryInvalidCredentials", err)
+	}
+}
+
+func TestLDAPAuthenticateRejectsAmbiguousFilter(t *testing.T) {
+	duplicate := ldapSari
+	duplicate.attributes = map[string][]string{"objectClass": {"person"}, "mail": {"sari2@jogjaprov.go.id"},
+		"employeeNumber": {"199001012015022001"}}
+	duplicate.dn = "uid=sari2,ou=people,dc=jogjaprov,dc=go,dc=id"
+	stub := startLDAPStub(t, ldapSari, duplicate)
+
+	_, err := LDAPDirectory{Config: stub.config()}.Authenticate("199001012015022001", "Prambanan-99")
+	if !errors.Is(err, ErrDirectoryInvalidCredentials) {
+		t.Fatalf("err = %v, want ErrDirectoryInvalidCredentials", err)
+	}
+	// Tidak ada bind sebagai salah satu user yang cocok
+	if len(stub.binds) != 1 {
+		t.Errorf("binds = %v, want only the service account", stub.binds)
+	}
+}
+
+func TestLDAPAuthenticateRejectsEmptyPassword(t *testing.T) {
+	stub := startLDAPStub(t, ldapBudi)
+
+	_, err := LDAPDirectory{Config: stub.config()}.Authenticate("budi.santoso@jogjaprov.go.id", "")
+	if !errors.Is(err, ErrDirectoryInvalidCredentials) {
+		t.Fatalf("err = %v, want ErrDirectoryInvalidCredentials", err)
+	}
+	// Bind dengan password kosong adalah bind anonim; server tidak boleh dihubungi
+	if stub.connections != 0 {
+		t.Errorf("directory was contacted %d times", stub.connections)
+	}
+}
+
+func TestLDAPAuthenticateEscapesLogin(t *testing.T) {
+	stub := startLDAPStub(t, ldapBudi)
+
+	_, err := LDAPDirectory{Config: stub.config()}.Authenticate("*)(mail=*", "Merapi-2024")
+	if !errors.Is(err, ErrDirectoryInvalidCredentials) {
+		t.Fatalf("err = %v, want ErrDirectoryInvalidCredentials", err)
+	}
+	if len(stub.searches) != 1 || !strings.Contains(stub.searches[0], "mail=*)(mail=*") {
+		t.Errorf("searches = %v, want the login as a literal value", stub.searches)
+	}
+}
+
+func TestLDAPAuthenticateReportsServiceBindFailure(t *testing.T) {
+	stub := startLDAPStub(t, ldapBudi)
+	cfg := stub.config()
+	cfg.BindPassword = "kedaluwarsa"
+
+	_, err := LDAPDirectory{Config: cfg}.Authenticate("budi.santoso@jogjaprov.go.id", "Merapi-2024")
+	if err == nil || errors.Is(err, ErrDirectoryInvalidCredentials) {
+		t.Fatalf("err = %v, want a directory error that is not counted as a wrong password", err)
+	}
+}
@@ -0,0 +1,101 @@
+package helpers
+
+import (
+	"backend/config"
+	"bytes"
+	"fmt"
+	"log"
+	"mime"
+	"net"
+	"net/smtp"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Mail adalah satu email teks biasa
+type Mail struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// MailSender mengirim email. Implementasi lain (misalnya layanan email pihak
+// ketiga) cukup memenuhi interface ini.
+type MailSender interface {
+	Send(mail Mail) error
+}
+
+// SMTPSender mengirim email lewat server SMTP. STARTTLS dipakai jika server
+// mendukungnya; autentikasi hanya dipakai jika Username diisi, sehingga server
+// SMTP lokal palsu (misalnya MailHog di localhost:1025) bisa dipakai saat pengujian.
+type SMTPSender struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+func (s SMTPSender) Send(mail Mail) error {
+	var auth smtp.Auth
+	if s.Username != "" {
+		auth = smtp.PlainAuth("", s.Username, s.Password, s.Host)
+	}
+	address := net.JoinHostPort(s.Host, s.Port)
+	if err := smtp.SendMail(address, auth, s.From, []string{mail.To}, buildMessage(s.From, mail)); err != nil {
+		return fmt.Errorf("failed to send mail to %s: %w", mail.To, err)
+	}
+	return nil
+}
+
+// LogSender hanya menulis email ke log; dipakai jika SMTP belum diatur. Link dan
+// token di body disamarkan karena log bisa dibaca orang selain penerima email.
+type LogSender struct{}
+
+func (LogSender) Send(mail Mail) error {
+	log.Printf("Mail (not sent, SMTP not configured) to=%s subject=%q\n%s", mail.To, mail.Subject, redactMailBody(mail.Body))
+	return nil
+}
+
+var (
+	mailLinkPattern  = regexp.MustCompile(`https?://\S+`)
+	mailTokenPattern = regexp.MustCompile(`[A-Za-z0-9_-]{20,}`)
+)
+
+// redactMailBody mengganti link dan string acak panjang (token) di body email
+func redactMailBody(body string) string {
+	body = mailLinkPattern.ReplaceAllString(body, "[link redacted]")
+	return mailTokenPattern.ReplaceAllString(body, "[token redacted]")
+}
+
+// MailSenderFromConfig memilih pengirim email sesuai konfigurasi
+func MailSenderFromConfig() MailSender {
+	cfg := config.Get().Mail
+	if cfg.Host == "" {
+		return LogSender{}
+	}
+	return SMTPSender{
+		Host:     cfg.Host,
+		Port:     cfg.Port,
+		Username: cfg.Username,
+		Password: cfg.Password,
+		From:     cfg.From,
+	}
+}
+
+func buildMessage(from string, mail Mail) []byte {
+	// Header tidak boleh berisi baris baru agar tidak bisa disisipi header lain
+	clean := strings.NewReplacer("\r", "", "\n", "")
+
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "From: %s\r\n", clean.Replace(from))
+	fmt.Fprintf(&message, "To: %s\r\n", clean.Replace(mail.To))
+	fmt.Fprintf(&message, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", clean.Replace(mail.Subject)))
+	fmt.Fprintf(&message, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	message.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	message.WriteString(strings.ReplaceAll(mail.Body, "\n", "\r\n"))
+	return message.Bytes()
+}
@@ -0,0 +1,131 @@
+package helpers
+
+import (
+	"bytes"
+	"log"
+	"net"
+	"net/textproto"
+	"os"
+	"strings"
+	"testing"
+)
+
+// fakeSMTPServer menerima satu sesi SMTP tanpa TLS dan autentikasi, lalu
+// mengirim penerima dan isi DATA yang diterimanya ke channel
+type fakeSMTPServer struct {
+	listener   net.Listener
+	recipients chan []string
+	data       chan string
+}
+
+func startFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &fakeSMTPServer{listener: listener, recipients: make(chan []string, 1), data: make(chan string, 1)}
+	t.Cleanup(func() { listener.Close() })
+	go server.serve()
+	return server
+}
+
+func (s *fakeSMTPServer) serve() {
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	text := textproto.NewConn(conn)
+	text.PrintfLine("220 localhost ESMTP fake")
+	var recipients []string
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch command {
+		case "EHLO", "HELO":
+			text.PrintfLine("250-localhost")
+			text.PrintfLine("250 8BITMIME")
+		case "MAIL":
+			text.PrintfLine("250 OK")
+		case "RCPT":
+			recipients = append(recipients, strings.TrimSuffix(strings.TrimPrefix(line[len("RCPT TO:"):], "<"), ">"))
+			text.PrintfLine("250 OK")
+		case "DATA":
+			text.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+			lines, err := text.ReadDotLines()
+			if err != nil {
+				return
+			}
+			s.recipients <- recipients
+			s.data <- strings.Join(lines, "\n")
+			text.PrintfLine("250 OK")
+		case "QUIT":
+			text.PrintfLine("221 Bye")
+			return
+		default:
+			text.PrintfLine("502 Command not implemented")
+		}
+	}
+}
+
+func TestSMTPSenderSendsSanitizedMail(t *testing.T) {
+	server := startFakeSMTPServer(t)
+	host, port, _ := net.SplitHostPort(server.listener.Addr().String())
+
+	sender := SMTPSender{Host: host, Port: port, From: "wiki@example.go.id"}
+	err := sender.Send(Mail{
+		To:      "pegawai@example.go.id",
+		Subject: "Reset password\r\nBcc: attacker@example.com",
+		Body:    "Baris pertama\nBaris kedua",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if recipients := <-server.recipients; len(recipients) != 1 || recipients[0] != "pegawai@example.go.id" {
+		t.Errorf("recipients = %v, want only pegawai@example.go.id", recipients)
+	}
+
+	data := <-server.data
+	headers, body, found := strings.Cut(data, "\n\n")
+	if !found {
+		t.Fatalf("message has no header/body separator:\n%s", data)
+	}
+	for _, line := range strings.Split(headers, "\n") {
+		if strings.HasPrefix(strings.ToLower(line), "bcc:") {
+			t.Errorf("injected header line %q", line)
+		}
+	}
+	if !strings.Contains(headers, "To: pegawai@example.go.id\n") {
+		t.Errorf("missing To header in:\n%s", headers)
+	}
+	if body != "Baris pertama\nBaris kedua" {
+		t.Errorf("body = %q", body)
+	}
+}
+
+func TestLogSenderRedactsLinksAndTokens(t *testing.T) {
+	var output bytes.Buffer
+	log.SetOutput(&output)
+	defer log.SetOutput(os.Stderr)
+
+	token := "Qx7vN2mK9pL4sT8wZ1yB3cD5fG6hJ0aE"
+	LogSender{}.Send(Mail{
+		To:      "pegawai@example.go.id",
+		Subject: "Reset password Wiki",
+		Body:    "Buka link berikut:\nhttps://wiki.example.go.id/reset-password?token=" + token + "\n\nKode: " + token,
+	})
+
+	logged := output.String()
+	if strings.Contains(logged, token) || strings.Contains(logged, "reset-password") {
+		t.Errorf("log still contains the reset link or token:\n%s", logged)
+	}
+	if !strings.Contains(logged, "Buka link berikut") {
+		t.Errorf("log lost the rest of the body:\n%s", logged)
+	}
+}
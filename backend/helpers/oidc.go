@@ -0,0 +1,408 @@
+package helpers
+
+import (
+	"backend/config"
+	"backend/entities"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrOIDCEmailNotVerified dikembalikan jika identity provider belum memverifikasi email user
+var ErrOIDCEmailNotVerified = errors.New("email is not verified by the identity provider")
+
+// Jeda minimal sebelum JWKS diambil ulang karena ada kid yang tidak dikenal,
+// agar token palsu tidak bisa membanjiri identity provider
+const oidcKeyRefreshInterval = time.Minute
+
+// oidcDiscovery adalah bagian dokumen .well-known/openid-configuration yang dipakai
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// OIDCClient menjalankan alur authorization code + PKCE ke identity provider
+// OpenID Connect dan memverifikasi ID token-nya. Dokumen discovery dan kunci JWKS
+// diambil saat pertama dibutuhkan lalu disimpan di memori.
+type OIDCClient struct {
+	Config     config.OIDCConfig
+	httpClient *http.Client
+
+	mu          sync.Mutex
+	discovery   *oidcDiscovery
+	keys        map[string]interface{}
+	keysFetched time.Time
+}
+
+func NewOIDCClient(cfg config.OIDCConfig) *OIDCClient {
+	return &OIDCClient{
+		Config:     cfg,
+		httpClient: &http.Client{Timeout: time.Duration(cfg.TimeoutSeconds) * time.Second},
+	}
+}
+
+// GeneratePKCEVerifier membuat code_verifier PKCE acak (43 karakter)
+func GeneratePKCEVerifier() (string, error) {
+	buffer := make([]byte, 32)
+	if _, err := rand.Read(buffer); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buffer), nil
+}
+
+// PKCEChallenge menghitung code_challenge metode S256 dari code_verifier
+func PKCEChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func (c *OIDCClient) getJSON(address string, target interface{}) error {
+	response, err := c.httpClient.Get(address)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s returned %s", address, response.Status)
+	}
+	return json.NewDecoder(io.LimitReader(response.Body, 1<<20)).Decode(target)
+}
+
+func (c *OIDCClient) discover() (*oidcDiscovery, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.discovery != nil {
+		return c.discovery, nil
+	}
+
+	var discovery oidcDiscovery
+	address := strings.TrimSuffix(c.Config.IssuerURL, "/") + "/.well-known/openid-configuration"
+	if err := c.getJSON(address, &discovery); err != nil {
+		return nil, fmt.Errorf("failed to load OIDC discovery document: %w", err)
+	}
+	if discovery.Issuer != c.Config.IssuerURL {
+		return nil, fmt.Errorf("OIDC discovery issuer %q does not match configured issuer %q", discovery.Issuer, c.Config.IssuerURL)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JWKSURI == "" {
+		return nil, errors.New("OIDC discovery document is missing required endpoints")
+	}
+	c.discovery = &discovery
+	return c.discovery, nil
+}
+
+// AuthCodeURL membuat alamat halaman login identity provider
+func (c *OIDCClient) AuthCodeURL(state, nonce, codeChallenge string) (string, error) {
+	discovery, err := c.discover()
+	if err != nil {
+		return "", err
+	}
+
+	scopes := c.Config.Scopes
+	if !containsString(scopes, "openid") {
+		scopes = append([]string{"openid"}, scopes...)
+	}
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {c.Config.ClientID},
+		"redirect_uri":          {c.Config.RedirectURL},
+		"scope":                 {strings.Join(scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {codeChallenge},
+		"code_challenge_method": {"S256"},
+	}
+
+	separator := "?"
+	if strings.Contains(discovery.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return discovery.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+// Exchange menukar authorization code dengan token dan mengembalikan ID token-nya
+func (c *OIDCClient) Exchange(code, codeVerifier string) (string, error) {
+	discovery, err := c.discover()
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {c.Config.RedirectURL},
+		"client_id":     {c.Config.ClientID},
+		"code_verifier": {codeVerifier},
+	}
+	request, err := http.NewRequest(http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+	// Client publik (tanpa secret) cukup mengandalkan PKCE
+	if c.Config.ClientSecret != "" {
+		request.SetBasicAuth(url.QueryEscape(c.Config.ClientID), url.QueryEscape(c.Config.ClientSecret))
+	}
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return "", fmt.Errorf("failed to call OIDC token endpoint: %w", err)
+	}
+	defer response.Body.Close()
+
+	var body struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(io.LimitReader(response.Body, 1<<20)).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to read OIDC token response (%s): %w", response.Status, err)
+	}
+	if response.StatusCode != http.StatusOK || body.Error != "" {
+		return "", fmt.Errorf("OIDC token endpoint returned %s: %s %s", response.Status, body.Error, body.ErrorDescription)
+	}
+	if body.IDToken == "" {
+		return "", errors.New("OIDC token response has no id_token")
+	}
+	return body.IDToken, nil
+}
+
+// VerifyIDToken memeriksa tanda tangan ID token dengan kunci JWKS, lalu issuer,
+// audience, masa berlaku dan nonce yang dikirim saat login dimulai
+func (c *OIDCClient) VerifyIDToken(rawToken, nonce string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(rawToken, claims, c.signingKey,
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}),
+		jwt.WithIssuer(c.Config.IssuerURL),
+		jwt.WithAudience(c.Config.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(time.Minute))
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID token: %w", err)
+	}
+
+	// azp yang ada harus berisi client ini, dan wajib ada jika audience lebih dari satu
+	azp, hasAZP := claims["azp"]
+	if audience, _ := claims.GetAudience(); hasAZP || len(audience) > 1 {
+		if azp, _ := azp.(string); azp != c.Config.ClientID {
+			return nil, errors.New("invalid ID token: azp does not match client_id")
+		}
+	}
+	if tokenNonce, _ := claims["nonce"].(string); nonce == "" || tokenNonce != nonce {
+		return nil, errors.New("invalid ID token: nonce mismatch")
+	}
+	if subject, _ := claims.GetSubject(); subject == "" {
+		return nil, errors.New("invalid ID token: missing sub")
+	}
+	return claims, nil
+}
+
+// signingKey mencari kunci publik JWKS sesuai kid di header token. JWKS diambil
+// ulang jika kid belum dikenal, karena identity provider bisa merotasi kuncinya.
+func (c *OIDCClient) signingKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	c.mu.Lock()
+	key, known := c.lookupKey(kid)
+	stale := time.Since(c.keysFetched) > oidcKeyRefreshInterval
+	c.mu.Unlock()
+	if known {
+		return key, nil
+	}
+	if !stale {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	discovery, err := c.discover()
+	if err != nil {
+		return nil, err
+	}
+	var set struct {
+		Keys []json.RawMessage `json:"keys"`
+	}
+	if err := c.getJSON(discovery.JWKSURI, &set); err != nil {
+		return nil, fmt.Errorf("failed to load OIDC signing keys: %w", err)
+	}
+	keys := map[string]interface{}{}
+	for _, raw := range set.Keys {
+		id, publicKey, err := parseJWK(raw)
+		if err != nil {
+			// Kunci dengan tipe yang tidak didukung dilewati saja
+			continue
+		}
+		keys[id] = publicKey
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.keys = keys
+	c.keysFetched = time.Now()
+	if key, known := c.lookupKey(kid); known {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// lookupKey harus dipanggil dengan mu terkunci. Token tanpa kid hanya diterima
+// jika JWKS berisi tepat satu kunci.
+func (c *OIDCClient) lookupKey(kid string) (interface{}, bool) {
+	if kid == "" && len(c.keys) == 1 {
+		for _, key := range c.keys {
+			return key, true
+		}
+	}
+	key, ok := c.keys[kid]
+	return key, ok
+}
+
+// parseJWK membaca satu kunci publik RSA atau EC dari JWKS
+func parseJWK(raw json.RawMessage) (string, interface{}, error) {
+	var jwk struct {
+		Kid string `json:"kid"`
+		Kty string `json:"kty"`
+		Use string `json:"use"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+		Crv string `json:"crv"`
+		X   string `json:"x"`
+		Y   string `json:"y"`
+	}
+	if err := json.Unmarshal(raw, &jwk); err != nil {
+		return "", nil, err
+	}
+	if jwk.Use != "" && jwk.Use != "sig" {
+		return "", nil, fmt.Errorf("key %q is not a signing key", jwk.Kid)
+	}
+
+	decode := func(value string) (*big.Int, error) {
+		data, err := base64.RawURLEncoding.DecodeString(value)
+		if err != nil || len(data) == 0 {
+			return nil, fmt.Errorf("invalid key parameter in key %q", jwk.Kid)
+		}
+		return new(big.Int).SetBytes(data), nil
+	}
+
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decode(jwk.N)
+		if err != nil {
+			return "", nil, err
+		}
+		e, err := decode(jwk.E)
+		if err != nil {
+			return "", nil, err
+		}
+		return jwk.Kid, &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return "", nil, fmt.Errorf("unsupported curve %q", jwk.Crv)
+		}
+		x, err := decode(jwk.X)
+		if err != nil {
+			return "", nil, err
+		}
+		y, err := decode(jwk.Y)
+		if err != nil {
+			return "", nil, err
+		}
+		return jwk.Kid, &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return "", nil, fmt.Errorf("unsupported key type %q", jwk.Kty)
+}
+
+// ExternalUser mengambil identitas user dari claims ID token sesuai nama claim
+// di konfigurasi
+func (c *OIDCClient) ExternalUser(claims jwt.MapClaims) (*entities.ExternalUser, error) {
+	subject, _ := claims.GetSubject()
+	email, _ := claims[c.Config.EmailClaim].(string)
+	if email == "" {
+		return nil, fmt.Errorf("ID token has no %s claim", c.Config.EmailClaim)
+	}
+	if c.Config.RequireVerifiedEmail {
+		if verified, _ := claims["email_verified"].(bool); !verified {
+			return nil, ErrOIDCEmailNotVerified
+		}
+	}
+
+	user := &entities.ExternalUser{
+		Provider: "oidc",
+		Subject:  subject,
+		Email:    strings.ToLower(email),
+		Name:     claimString(claims[c.Config.NameClaim]),
+		Groups:   claimStrings(claims[c.Config.GroupsClaim]),
+	}
+	if user.Name == "" {
+		user.Name = user.Email
+	}
+	if c.Config.NIPClaim != "" {
+		// NIP yang tidak berupa angka dibiarkan 0
+		user.NIP, _ = strconv.ParseInt(claimString(claims[c.Config.NIPClaim]), 10, 64)
+	}
+	return user, nil
+}
+
+// claimString membaca claim teks atau angka
+func claimString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case json.Number:
+		return v.String()
+	}
+	return ""
+}
+
+// claimStrings membaca claim berupa daftar teks atau satu teks
+func claimStrings(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if text, ok := item.(string); ok {
+				values = append(values, text)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+func containsString(values []string, want string) bool {
+	for _, value := range values {
+		if value == want {
+			return true
+		}
+	}
+	return false
+}
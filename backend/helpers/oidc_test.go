@@ -0,0 +1,280 @@
+package helpers
+
+import (
+	"backend/config"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// mockIdP adalah identity provider tiruan dengan dokumen discovery, JWKS dan
+// token endpoint. Token endpoint hanya menerima code dan code_verifier yang
+// diisi di field code dan verifier, lalu menjawab dengan idToken.
+type mockIdP struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+	kid    string
+
+	code     string
+	verifier string
+	idToken  string
+}
+
+func startMockIdP(t *testing.T) *mockIdP {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idp := &mockIdP{key: key, kid: "kunci-1"}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.server.URL,
+			"authorization_endpoint": idp.server.URL + "/authorize",
+			"token_endpoint":         idp.server.URL + "/token",
+			"jwks_uri":               idp.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kid": idp.kid,
+				"kty": "RSA",
+				"use": "sig",
+				"alg": "RS256",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method != http.MethodPost || r.FormValue("grant_type") != "authorization_code" ||
+			r.FormValue("code") != idp.code || r.FormValue("code_verifier") != idp.verifier {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"access_token": "akses", "token_type": "Bearer", "id_token": idp.idToken})
+	})
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+func (idp *mockIdP) config() config.OIDCConfig {
+	return config.OIDCConfig{
+		IssuerURL:            idp.server.URL,
+		ClientID:             "wiki",
+		RedirectURL:          "http://localhost:3000/api/sso/oidc/callback",
+		Scopes:               []string{"email", "profile"},
+		EmailClaim:           "email",
+		NameClaim:            "name",
+		NIPClaim:             "nip",
+		GroupsClaim:          "groups",
+		RequireVerifiedEmail: true,
+		TimeoutSeconds:       5,
+	}
+}
+
+// claims adalah isi ID token yang valid untuk client "wiki" dan nonce yang diberikan
+func (idp *mockIdP) claims(nonce string) jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":            idp.server.URL,
+		"sub":            "3f0c9a52-budi",
+		"aud":            "wiki",
+		"exp":            now.Add(5 * time.Minute).Unix(),
+		"iat":            now.Unix(),
+		"nonce":          nonce,
+		"email":          "Budi.Santoso@jogjaprov.go.id",
+		"email_verified": true,
+		"name":           "Budi Santoso",
+		"nip":            "198703122010011002",
+		"groups":         []string{"wiki-editor"},
+	}
+}
+
+func (idp *mockIdP) sign(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+	return signTestToken(t, idp.key, idp.kid, claims)
+}
+
+func signTestToken(t *testing.T, key *rsa.PrivateKey, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestOIDCClientCodeFlow(t *testing.T) {
+	idp := startMockIdP(t)
+	client := NewOIDCClient(idp.config())
+
+	verifier, err := GeneratePKCEVerifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+	authURL, err := client.AuthCodeURL("state-acak", "nonce-acak", PKCEChallenge(verifier))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, _ := url.Parse(authURL)
+	query := parsed.Query()
+	if parsed.Path != "/authorize" || query.Get("client_id") != "wiki" || query.Get("state") != "state-acak" ||
+		query.Get("nonce") != "nonce-acak" || query.Get("code_challenge_method") != "S256" ||
+		query.Get("scope") != "openid email profile" {
+		t.Errorf("authorization URL = %s", authURL)
+	}
+
+	idp.code, idp.verifier = "kode-otorisasi", verifier
+	idp.idToken = idp.sign(t, idp.claims("nonce-acak"))
+	rawToken, err := client.Exchange("kode-otorisasi", verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := client.VerifyIDToken(rawToken, "nonce-acak")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, err := client.ExternalUser(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Provider != "oidc" || user.Subject != "3f0c9a52-budi" || user.Email != "budi.santoso@jogjaprov.go.id" ||
+		user.NIP != 198703122010011002 || len(user.Groups) != 1 || user.Groups[0] != "wiki-editor" {
+		t.Errorf("user = %+v", user)
+	}
+}
+
+func TestOIDCExchangeRejectsWrongVerifier(t *testing.T) {
+	idp := startMockIdP(t)
+	client := NewOIDCClient(idp.config())
+	idp.code, idp.verifier = "kode-otorisasi", "verifier-asli"
+	idp.idToken = idp.sign(t, idp.claims("nonce-acak"))
+
+	if _, err := client.Exchange("kode-otorisasi", "verifier-lain"); err == nil || !strings.Contains(err.Error(), "invalid_grant") {
+		t.Fatalf("err = %v, want invalid_grant", err)
+	}
+}
+
+func TestVerifyIDTokenRejects(t *testing.T) {
+	idp := startMockIdP(t)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		token func() string
+		nonce string
+		want  string
+	}{
+		{"bad signature", func() string {
+			return signTestToken(t, otherKey, idp.kid, idp.claims("nonce-acak"))
+		}, "nonce-acak", "signature is invalid"},
+		{"unknown key", func() string {
+			return signTestToken(t, idp.key, "kunci-lama", idp.claims("nonce-acak"))
+		}, "nonce-acak", "unknown signing key"},
+		{"wrong issuer", func() string {
+			claims := idp.claims("nonce-acak")
+			claims["iss"] = "https://idp-lain.example.com"
+			return idp.sign(t, claims)
+		}, "nonce-acak", "invalid issuer"},
+		{"wrong audience", func() string {
+			claims := idp.claims("nonce-acak")
+			claims["aud"] = "aplikasi-lain"
+			return idp.sign(t, claims)
+		}, "nonce-acak", "invalid audience"},
+		{"wrong azp", func() string {
+			claims := idp.claims("nonce-acak")
+			claims["azp"] = "aplikasi-lain"
+			return idp.sign(t, claims)
+		}, "nonce-acak", "azp"},
+		{"several audiences without azp", func() string {
+			claims := idp.claims("nonce-acak")
+			claims["aud"] = []string{"wiki", "aplikasi-lain"}
+			return idp.sign(t, claims)
+		}, "nonce-acak", "azp"},
+		{"expired", func() string {
+			claims := idp.claims("nonce-acak")
+			claims["iat"] = time.Now().Add(-time.Hour).Unix()
+			claims["exp"] = time.Now().Add(-10 * time.Minute).Unix()
+			return idp.sign(t, claims)
+		}, "nonce-acak", "token is expired"},
+		{"missing exp", func() string {
+			claims := idp.claims("nonce-acak")
+			delete(claims, "exp")
+			return idp.sign(t, claims)
+		}, "nonce-acak", "exp claim is required"},
+		{"nonce mismatch", func() string {
+			return idp.sign(t, idp.claims("nonce-lain"))
+		}, "nonce-acak", "nonce mismatch"},
+		{"empty nonce", func() string {
+			return idp.sign(t, idp.claims(""))
+		}, "", "nonce mismatch"},
+		{"missing sub", func() string {
+			claims := idp.claims("nonce-acak")
+			delete(claims, "sub")
+			return idp.sign(t, claims)
+		}, "nonce-acak", "missing sub"},
+		{"HMAC with the public key", func() string {
+			token := jwt.NewWithClaims(jwt.SigningMethodHS256, idp.claims("nonce-acak"))
+			token.Header["kid"] = idp.kid
+			signed, _ := token.SignedString(idp.key.PublicKey.N.Bytes())
+			return signed
+		}, "nonce-acak", "signing method HS256 is invalid"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewOIDCClient(idp.config())
+			_, err := client.VerifyIDToken(tt.token(), tt.nonce)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyIDTokenAcceptsMatchingAZP(t *testing.T) {
+	idp := startMockIdP(t)
+	claims := idp.claims("nonce-acak")
+	claims["aud"] = []string{"wiki", "aplikasi-lain"}
+	claims["azp"] = "wiki"
+
+	if _, err := NewOIDCClient(idp.config()).VerifyIDToken(idp.sign(t, claims), "nonce-acak"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOIDCExternalUserRequiresVerifiedEmail(t *testing.T) {
+	idp := startMockIdP(t)
+	claims := idp.claims("nonce-acak")
+	claims["email_verified"] = false
+	client := NewOIDCClient(idp.config())
+
+	verified, err := client.VerifyIDToken(idp.sign(t, claims), "nonce-acak")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.ExternalUser(verified); err != ErrOIDCEmailNotVerified {
+		t.Fatalf("err = %v, want ErrOIDCEmailNotVerified", err)
+	}
+}
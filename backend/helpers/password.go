@@ -0,0 +1,49 @@
+package helpers
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Cost bcrypt untuk hash baru; hash dengan cost lebih rendah di-hash ulang saat login
+const PasswordHashCost = 12
+
+// dummyPasswordHash dipakai saat email tidak ditemukan agar waktu respons login
+// sama dengan saat password salah
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password"), PasswordHashCost)
+
+// HashPassword membuat hash bcrypt dari password
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), PasswordHashCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// IsPasswordHash membedakan hash bcrypt dari password lama yang masih tersimpan apa adanya
+func IsPasswordHash(stored string) bool {
+	return strings.HasPrefix(stored, "$2a$") || strings.HasPrefix(stored, "$2b$") || strings.HasPrefix(stored, "$2y$")
+}
+
+// VerifyPassword mencocokkan password dengan nilai di database. needsRehash bernilai
+// true jika password cocok tetapi masih plaintext atau memakai cost lama.
+func VerifyPassword(stored, password string) (matches bool, needsRehash bool) {
+	if !IsPasswordHash(stored) {
+		matches = stored != "" && subtle.ConstantTimeCompare([]byte(stored), []byte(password)) == 1
+		return matches, matches
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(stored), []byte(password)) != nil {
+		return false, false
+	}
+	cost, err := bcrypt.Cost([]byte(stored))
+	return true, err != nil || cost < PasswordHashCost
+}
+
+// BurnPasswordCheck menjalankan perbandingan bcrypt palsu untuk menyamakan waktu respons
+func BurnPasswordCheck(password string) {
+	bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+}
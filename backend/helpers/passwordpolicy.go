@@ -0,0 +1,147 @@
+package helpers
+
+import (
+	"backend/config"
+	_ "embed"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+//go:embed common_passwords.txt
+var commonPasswordList string
+
+// commonPasswords berisi daftar password umum/bocor yang dibundel bersama aplikasi
+var commonPasswords = parseCommonPasswords(commonPasswordList)
+
+// Daftar tambahan dari password_policy.common_passwords_file, dibaca sekali per path
+var (
+	extraCommonPasswordsMu   sync.Mutex
+	extraCommonPasswordsPath string
+	extraCommonPasswords     map[string]struct{}
+)
+
+func parseCommonPasswords(list string) map[string]struct{} {
+	set := map[string]struct{}{}
+	for _, line := range strings.Split(list, "\n") {
+		line = strings.ToLower(strings.TrimSpace(line))
+		if line != "" && !strings.HasPrefix(line, "#") {
+			set[line] = struct{}{}
+		}
+	}
+	return set
+}
+
+// commonPasswordFile memuat daftar tambahan; jika file gagal dibaca, hanya daftar
+// bawaan yang dipakai dan kesalahannya dicatat di log
+func commonPasswordFile(path string) map[string]struct{} {
+	if path == "" {
+		return nil
+	}
+	extraCommonPasswordsMu.Lock()
+	defer extraCommonPasswordsMu.Unlock()
+
+	if path != extraCommonPasswordsPath {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			log.Printf("Failed to read common password file %s: %v", path, err)
+			return nil
+		}
+		extraCommonPasswords = parseCommonPasswords(string(data))
+		extraCommonPasswordsPath = path
+	}
+	return extraCommonPasswords
+}
+
+// isCommonPassword juga menangkap variasi sederhana seperti "Password123!" atau "jogja2024"
+func isCommonPassword(password string, extra map[string]struct{}) bool {
+	lower := strings.ToLower(password)
+	stripped := strings.TrimRightFunc(lower, func(r rune) bool {
+		return unicode.IsDigit(r) || unicode.IsPunct(r) || unicode.IsSymbol(r)
+	})
+	for _, set := range []map[string]struct{}{commonPasswords, extra} {
+		if _, ok := set[lower]; ok {
+			return true
+		}
+		if _, ok := set[stripped]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckPasswordPolicy memeriksa password baru terhadap policy. personal berisi data
+// user (mis. email dan nama) yang tidak boleh dipakai sebagai password. Hasilnya
+// daftar pelanggaran; kosong berarti password diterima.
+func CheckPasswordPolicy(policy config.PasswordPolicyConfig, password string, personal ...string) []string {
+	problems := []string{}
+
+	if len(password) < policy.MinLength {
+		problems = append(problems, fmt.Sprintf("Password must be at least %d characters", policy.MinLength))
+	}
+	if len(password) > 72 {
+		problems = append(problems, "Password must be at most 72 bytes")
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case !unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+	if policy.RequireUpper && !upper {
+		problems = append(problems, "Password must contain an uppercase letter")
+	}
+	if policy.RequireLower && !lower {
+		problems = append(problems, "Password must contain a lowercase letter")
+	}
+	if policy.RequireDigit && !digit {
+		problems = append(problems, "Password must contain a digit")
+	}
+	if policy.RequireSymbol && !symbol {
+		problems = append(problems, "Password must contain a symbol")
+	}
+
+	if policy.RejectCommon && isCommonPassword(password, commonPasswordFile(policy.CommonPasswordsFile)) {
+		problems = append(problems, "Password is too common")
+	}
+
+	lowerPassword := strings.ToLower(password)
+	for _, word := range personalWords(personal) {
+		if strings.Contains(lowerPassword, word) {
+			problems = append(problems, "Password must not contain your name or email")
+			break
+		}
+	}
+	return problems
+}
+
+// personalWords memecah nama dan bagian lokal email menjadi kata minimal 4 huruf,
+// mis. "budi" dan "santoso" dari "Budi Santoso" atau "budi.santoso@jogjaprov.go.id"
+func personalWords(values []string) []string {
+	words := []string{}
+	for _, value := range values {
+		value = strings.ToLower(value)
+		if local, _, ok := strings.Cut(value, "@"); ok {
+			value = local
+		}
+		for _, word := range strings.FieldsFunc(value, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}) {
+			if len(word) >= 4 {
+				words = append(words, word)
+			}
+		}
+	}
+	return words
+}
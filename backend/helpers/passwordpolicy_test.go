@@ -0,0 +1,38 @@
+package helpers
+
+import (
+	"backend/config"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCommonPasswordListIsBundled(t *testing.T) {
+	if len(commonPasswords) < 7000 {
+		t.Fatalf("bundled common password list has %d entries", len(commonPasswords))
+	}
+	for _, password := range []string{"password", "Qwerty123!", "dragon2024", "michael", "jogjakarta"} {
+		if !isCommonPassword(password, nil) {
+			t.Errorf("%q is not treated as common", password)
+		}
+	}
+	if isCommonPassword("Kabut-Merapi-7Sore", nil) {
+		t.Error("uncommon password rejected")
+	}
+}
+
+func TestCheckPasswordPolicyUsesExtraFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "passwords.txt")
+	if err := os.WriteFile(path, []byte("# daftar tambahan\nKabutMerapi\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	policy := config.PasswordPolicyConfig{MinLength: 8, RejectCommon: true, CommonPasswordsFile: path}
+
+	if problems := CheckPasswordPolicy(policy, "kabutmerapi99"); len(problems) != 1 || problems[0] != "Password is too common" {
+		t.Errorf("problems = %v, want only the common password problem", problems)
+	}
+	policy.CommonPasswordsFile = ""
+	if problems := CheckPasswordPolicy(policy, "kabutmerapi99"); len(problems) != 0 {
+		t.Errorf("problems without extra file = %v", problems)
+	}
+}
@@ -0,0 +1,124 @@
+package helpers
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var htmlTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// Kata umum bahasa Indonesia dan Inggris yang tidak membantu membedakan dokumen
+var stopwords = map[string]bool{
+	"dan": true, "yang": true, "di": true, "ke": true, "dari": true, "untuk": true, "dengan": true,
+	"pada": true, "ini": true, "itu": true, "atau": true, "dalam": true, "adalah": true, "oleh": true,
+	"sebagai": true, "tidak": true, "akan": true, "juga": true, "telah": true, "dapat": true,
+	"para": true, "serta": true, "tersebut": true, "bagi": true, "nbsp": true,
+	"the": true, "and": true, "of": true, "to": true, "in": true, "for": true, "on": true,
+	"is": true, "a": true, "an": true, "with": true, "by": true, "as": true, "at": true, "or": true,
+}
+
+// Tokenize mengubah teks (boleh berisi HTML) menjadi daftar kata kecil tanpa stopword
+func Tokenize(text string) []string {
+	text = htmlTagPattern.ReplaceAllString(text, " ")
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	tokens := make([]string, 0, len(words))
+	for _, word := range words {
+		if len([]rune(word)) < 3 || stopwords[word] {
+			continue
+		}
+		tokens = append(tokens, word)
+	}
+	return tokens
+}
+
+// TFIDFIndex menyimpan vektor TF-IDF ternormalisasi untuk sekumpulan dokumen
+type TFIDFIndex struct {
+	vectors map[int64]map[string]float64
+}
+
+// NewTFIDFIndex membangun indeks dari token per dokumen (key adalah id dokumen)
+func NewTFIDFIndex(documents map[int64][]string) *TFIDFIndex {
+	documentFrequency := map[string]int{}
+	for _, tokens := range documents {
+		seen := map[string]bool{}
+		for _, token := range tokens {
+			if !seen[token] {
+				seen[token] = true
+				documentFrequency[token]++
+			}
+		}
+	}
+
+	total := float64(len(documents))
+	index := &TFIDFIndex{vectors: make(map[int64]map[string]float64, len(documents))}
+	for id, tokens := range documents {
+		termFrequency := map[string]float64{}
+		for _, token := range tokens {
+			termFrequency[token]++
+		}
+
+		vector := make(map[string]float64, len(termFrequency))
+		var norm float64
+		for term, count := range termFrequency {
+			// IDF dengan smoothing agar kata yang muncul di semua dokumen tetap bernilai kecil
+			weight := (1 + math.Log(count)) * math.Log((1+total)/(1+float64(documentFrequency[term])))
+			vector[term] = weight
+			norm += weight * weight
+		}
+
+		if norm > 0 {
+			norm = math.Sqrt(norm)
+			for term := range vector {
+				vector[term] /= norm
+			}
+		}
+		index.vectors[id] = vector
+	}
+	return index
+}
+
+// Similarity menghitung cosine similarity dua dokumen dalam indeks (0 sampai 1)
+func (index *TFIDFIndex) Similarity(a, b int64) float64 {
+	vectorA, vectorB := index.vectors[a], index.vectors[b]
+	if len(vectorA) > len(vectorB) {
+		vectorA, vectorB = vectorB, vectorA
+	}
+
+	var dot float64
+	for term, weight := range vectorA {
+		dot += weight * vectorB[term]
+	}
+	return dot
+}
+
+// SplitTags memecah tag yang dipisahkan koma menjadi himpunan tag kecil
+func SplitTags(tag string) map[string]bool {
+	tags := map[string]bool{}
+	for _, part := range strings.Split(tag, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if part != "" {
+			tags[part] = true
+		}
+	}
+	return tags
+}
+
+// Jaccard menghitung kemiripan dua himpunan tag (irisan dibagi gabungan)
+func Jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	shared := 0
+	for tag := range a {
+		if b[tag] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
@@ -0,0 +1,35 @@
+// Package dbtest memasang database tiruan (sqlmock) sebagai connection pool
+// bersama. Package controllers membuat modelnya saat init, jadi test-nya cukup
+// mengimpor package ini agar pool tiruan sudah terpasang lebih dulu.
+package dbtest
+
+import (
+	"backend/config"
+	"database/sql/driver"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// Mock adalah expectation untuk semua query ke connection pool bersama
+var Mock sqlmock.Sqlmock
+
+func init() {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		panic(err)
+	}
+	config.SetDBConnection(db)
+	Mock = mock
+}
+
+// Capture adalah argumen query yang cocok dengan teks apa pun dan menyimpan
+// nilainya, misalnya hash state yang dibuat acak oleh controller
+type Capture struct {
+	Value string
+}
+
+func (c *Capture) Match(value driver.Value) bool {
+	text, ok := value.(string)
+	c.Value = text
+	return ok
+}
@@ -6,28 +6,58 @@ import (
 	"backend/helpers"
 	"backend/models"
 
+	analyticscontroller "backend/controllers"
+	attachmentcontroller "backend/controllers"
 	contentcontroller "backend/controllers"
+	feedcontroller "backend/controllers"
 	historycontroller "backend/controllers"
 	instancecontroller "backend/controllers"
+	loginsecuritycontroller "backend/controllers"
+	passwordcontroller "backend/controllers"
+	passwordresetcontroller "backend/controllers"
 	permissioncontroller "backend/controllers"
+	publiccontroller "backend/controllers"
+	relatedcontroller "backend/controllers"
 	rolecontroller "backend/controllers"
+	sessioncontroller "backend/controllers"
+	ssocontroller "backend/controllers"
 	subheadingcontroller "backend/controllers"
+	tokencontroller "backend/controllers"
+	tokenkeycontroller "backend/controllers"
+	trendingcontroller "backend/controllers"
+	twofactorcontroller "backend/controllers"
 	usercontroller "backend/controllers"
+	viewcontroller "backend/controllers"
 	middleware "backend/middlewares"
+	"context"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
 )
 
 func main() {
+	// Perintah CLI, misalnya "go run . keys rotate"
+	if len(os.Args) > 1 && os.Args[1] == "keys" {
+		if err := runKeysCommand(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 
 	    // Buat instance dari RolePermissionController
 		rolePermissionModel := models.NewRolePermissionModel()
 		contentModel := models.NewContentModel()
 		rolePermissionController := controllers.NewRolePermissionController(rolePermissionModel, contentModel)
 
+	// Konfigurasi sudah divalidasi saat pertama dibaca; server tidak jalan jika tidak valid
+	cfg := config.Get()
+
 	// Inisialisasi koneksi database
 	db, err := config.DBConnection()
 	if err != nil {
@@ -37,64 +67,172 @@ func main() {
 	// Set DB global untuk middleware
 	middleware.DB = db
 
+	// Keyring kunci token dimuat dari database dan dimuat ulang setiap menit
+	if err := helpers.StartKeyring(models.NewTokenKeyModel().LoadKeys, time.Minute); err != nil {
+		log.Fatalf("Error loading token keys: %v", err)
+	}
+
 	// Inisialisasi router
 	r := mux.NewRouter()
 
 	
 
+	// Batas request per route, bisa diubah lewat rate_limits di config atau RATE_LIMIT_<NAMA>="<per menit>/<burst>"
+	loginLimiter := middleware.RateLimiterFromConfig("login", 10, 5)
+	guestLimiter := middleware.RateLimiterFromConfig("guest", 30, 10)
+	refreshLimiter := middleware.RateLimiterFromConfig("refresh", 30, 10)
+	passwordResetLimiter := middleware.RateLimiterFromConfig("password_reset", 5, 3)
+	searchLimiter := middleware.RateLimiterFromConfig("search", 60, 20)
+	publicLimiter := middleware.RateLimiterFromConfig("public", 60, 20)
+	apiLimiter := middleware.RateLimiterFromConfig("api", 300, 100)
+
+	// Semua route dengan JWT dibatasi per user lewat apiLimiter; route dengan limiter
+	// sendiri (pencarian, ganti password) tetap kena batas tambahan tersebut
+	authenticated := func(next http.Handler) http.Handler {
+		return middleware.JWTAuth(middleware.RateLimitByUser(apiLimiter, next))
+	}
+
 	// Konfigurasi CORS
 	cors := handlers.CORS(
-		handlers.AllowedOrigins([]string{"http://localhost:3001"}), // Frontend URL
+		handlers.AllowedOrigins(cfg.CORSOrigins), // Frontend URL
 		handlers.AllowedMethods([]string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}),
-		handlers.AllowedHeaders([]string{"Content-Type", "Authorization", "X-Requested-With", "Accept"}),
+		handlers.AllowedHeaders([]string{"Content-Type", "Authorization", "X-Requested-With", "Accept", "If-None-Match", "If-Modified-Since"}),
+		handlers.ExposedHeaders([]string{"ETag", "Last-Modified", "Retry-After", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset"}),
 		handlers.AllowCredentials(), // Izinkan penggunaan credentials (cookies, dll.)
 	)
 
 	
-	r.Handle("/api", middleware.JWTAuth(middleware.RoleAuthMiddleware("view_contents", http.HandlerFunc(contentcontroller.GetIdTitleAllContents)))).Methods("GET")
-	r.Handle("/api/notReject", middleware.JWTAuth(middleware.RoleAuthMiddleware("view_contents_notReject", http.HandlerFunc(contentcontroller.GetIdTitleAllContentsNotRejected)))).Methods("GET")
-	r.Handle("/api/active", middleware.JWTAuth(middleware.RoleAuthMiddleware("view_active_content", http.HandlerFunc(contentcontroller.GetIdTitleAllContentsNotDeleted)))).Methods("GET")
-	r.Handle("/api/draft", middleware.JWTAuth(middleware.RoleAuthMiddleware("view_drafts", http.HandlerFunc(contentcontroller.GetIdTitleAllDrafts)))).Methods("GET")
-	r.Handle("/api/content", middleware.JWTAuth(middleware.RoleAuthMiddleware("search_contents", http.HandlerFunc(contentcontroller.SearchContent)))).Methods("GET")
-	r.Handle("/api/content/{id}", middleware.JWTAuth(middleware.RoleAuthMiddleware("view_content", http.HandlerFunc(contentcontroller.GetContentByID)))).Methods("GET")
-	r.Handle("/api/content/edit/{id}", middleware.JWTAuth(middleware.RoleAuthMiddleware("edit_content", http.HandlerFunc(contentcontroller.EditContentByID)))).Methods("PUT")
-	r.Handle("/api/content/add", middleware.JWTAuth(middleware.RoleAuthMiddleware("create_content", http.HandlerFunc(contentcontroller.CreateContent)))).Methods("POST")
-	r.Handle("/api/subheading/add/{id}", middleware.JWTAuth(middleware.RoleAuthMiddleware("create_subheading", http.HandlerFunc(subheadingcontroller.CreateSubheading)))).Methods("POST")
-	r.Handle("/api/subheading/delete/{id}", middleware.JWTAuth(middleware.RoleAuthMiddleware("delete_subheading", http.HandlerFunc(subheadingcontroller.DeleteSubheadingByID)))).Methods("DELETE")
-	r.Handle("/api/content/delete/{id}", middleware.JWTAuth(middleware.RoleAuthMiddleware("delete_content", http.HandlerFunc(contentcontroller.DeleteContent)))).Methods("PUT")
-	r.Handle("/api/contents/user/{id}", middleware.JWTAuth(middleware.RoleAuthMiddleware("view_user_contents", http.HandlerFunc(contentcontroller.GetUserContents)))).Methods("GET")
-	r.Handle("/api/instances", middleware.JWTAuth(middleware.RoleAuthMiddleware("view_instances", http.HandlerFunc(instancecontroller.GetInstances)))).Methods("GET")
-	r.Handle("/api/user/{id}", middleware.JWTAuth(middleware.RoleAuthMiddleware("view_user", http.HandlerFunc(usercontroller.GetUserByID)))).Methods("GET")
-	r.Handle("/api/users", middleware.JWTAuth(middleware.RoleAuthMiddleware("view_all_users", http.HandlerFunc(usercontroller.GetAllUsers)))).Methods("GET")
-	r.Handle("/api/roles", middleware.JWTAuth(middleware.RoleAuthMiddleware("view_roles", http.HandlerFunc(rolecontroller.GetRoles)))).Methods("GET")
-	r.Handle("/api/createuser", middleware.JWTAuth(middleware.RoleAuthMiddleware("create_user", http.HandlerFunc(usercontroller.CreateUser)))).Methods("POST")
-	r.Handle("/api/user/edit/{id}", middleware.JWTAuth(middleware.RoleAuthMiddleware("edit_user", http.HandlerFunc(usercontroller.EditUserById)))).Methods("PUT")
-	r.Handle("/api/user/{id}", middleware.JWTAuth(middleware.RoleAuthMiddleware("delete_user", http.HandlerFunc(usercontroller.DeleteUser)))).Methods("PUT")
-	r.Handle("/api/history/add", middleware.JWTAuth(middleware.RoleAuthMiddleware("add_history", http.HandlerFunc(historycontroller.AddHistory)))).Methods("POST")
-	r.Handle("/api/history/user/{id}", middleware.JWTAuth(middleware.RoleAuthMiddleware("view_history_user", http.HandlerFunc(historycontroller.GetByIdUser)))).Methods("GET")
-	r.Handle("/api/latest-editor-name/{contentId}", middleware.JWTAuth(middleware.RoleAuthMiddleware("view_latest_editor", http.HandlerFunc(historycontroller.GetLatestEditorNameByContentId)))).Methods("GET")
-	r.Handle("/api/content/approve/{id}", middleware.JWTAuth(middleware.RoleAuthMiddleware("approve_content", http.HandlerFunc(contentcontroller.ApproveContent)))).Methods("PUT")
-	r.Handle("/api/content/reject/{id}", middleware.JWTAuth(middleware.RoleAuthMiddleware("reject_content", http.HandlerFunc(contentcontroller.RejectContent)))).Methods("PUT")
-	r.Handle("/api/permissions", middleware.JWTAuth(middleware.RoleAuthMiddleware("manage_role", http.HandlerFunc(permissioncontroller.GetPermissionList)))).Methods("GET")
-	r.Handle("/api/role_permissions", middleware.JWTAuth(middleware.RoleAuthMiddleware("view_permission", http.HandlerFunc(permissioncontroller.GetPermissionsByRole)))).Methods("GET")
-	r.Handle("/api/roles/{role_id}/permissions",middleware.JWTAuth(middleware.RoleAuthMiddleware("view_role_permission",http.HandlerFunc(rolePermissionController.GetPermissionsByRole)))).Methods("GET")
-	r.Handle("/api/roles/{role_id}/permissions/add/{permission_id}",middleware.JWTAuth(middleware.RoleAuthMiddleware("add_permission",http.HandlerFunc(rolePermissionController.AddPermissionToRole)))).Methods("POST") // 
-	r.Handle("/api/roles/{role_id}/permissions/delete/{permission_id}",middleware.JWTAuth(middleware.RoleAuthMiddleware("remove_permission",http.HandlerFunc(rolePermissionController.RemovePermissionFromRole)))).Methods("DELETE") //
-	r.Handle("/api/content/viewcount/{id}", middleware.JWTAuth(middleware.RoleAuthMiddleware("view_content", http.HandlerFunc(contentcontroller.GetContentViewCount)))).Methods("GET")
-	r.Handle("/api/content/resubmit/{id}", middleware.JWTAuth(middleware.RoleAuthMiddleware("resubmit_content", http.HandlerFunc(contentcontroller.ResubmitRejectedContent)))).Methods("PUT")
-	r.Handle("/api/content/increment-viewcount/{id}", middleware.JWTAuth(middleware.RoleAuthMiddleware("view_content", http.HandlerFunc(contentcontroller.IncrementViewCount)))).Methods("PUT")
-
-	r.HandleFunc("/api/guest", usercontroller.DefaultTokenHandler).Methods("GET")
+	r.Handle("/api", authenticated(middleware.RoleAuthMiddleware("view_contents", http.HandlerFunc(contentcontroller.GetIdTitleAllContents)))).Methods("GET")
+	r.Handle("/api/notReject", authenticated(middleware.RoleAuthMiddleware("view_contents_notReject", http.HandlerFunc(contentcontroller.GetIdTitleAllContentsNotRejected)))).Methods("GET")
+	r.Handle("/api/active", authenticated(middleware.RoleAuthMiddleware("view_active_content", http.HandlerFunc(contentcontroller.GetIdTitleAllContentsNotDeleted)))).Methods("GET")
+	r.Handle("/api/draft", authenticated(middleware.RoleAuthMiddleware("view_drafts", http.HandlerFunc(contentcontroller.GetIdTitleAllDrafts)))).Methods("GET")
+	r.Handle("/api/content", authenticated(middleware.RateLimitByUser(searchLimiter, middleware.RoleAuthMiddleware("search_contents", http.HandlerFunc(contentcontroller.SearchContent))))).Methods("GET")
+	r.Handle("/api/content/{id}", authenticated(middleware.RoleAuthMiddleware("view_content", http.HandlerFunc(contentcontroller.GetContentByID)))).Methods("GET")
+	r.Handle("/api/content/edit/{id}", authenticated(middleware.RoleAuthMiddleware("edit_content", http.HandlerFunc(contentcontroller.EditContentByID)))).Methods("PUT")
+	r.Handle("/api/content/add", authenticated(middleware.RoleAuthMiddleware("create_content", http.HandlerFunc(contentcontroller.CreateContent)))).Methods("POST")
+	r.Handle("/api/subheading/add/{id}", authenticated(middleware.RoleAuthMiddleware("create_subheading", http.HandlerFunc(subheadingcontroller.CreateSubheading)))).Methods("POST")
+	r.Handle("/api/subheading/delete/{id}", authenticated(middleware.RoleAuthMiddleware("delete_subheading", http.HandlerFunc(subheadingcontroller.DeleteSubheadingByID)))).Methods("DELETE")
+	r.Handle("/api/content/delete/{id}", authenticated(middleware.RoleAuthMiddleware("delete_content", http.HandlerFunc(contentcontroller.DeleteContent)))).Methods("PUT")
+	r.Handle("/api/contents/user/{id}", authenticated(middleware.RoleAuthMiddleware("view_user_contents", http.HandlerFunc(contentcontroller.GetUserContents)))).Methods("GET")
+	r.Handle("/api/instances", authenticated(middleware.RoleAuthMiddleware("view_instances", http.HandlerFunc(instancecontroller.GetInstances)))).Methods("GET")
+	r.Handle("/api/user/{id}", authenticated(middleware.RoleAuthMiddleware("view_user", http.HandlerFunc(usercontroller.GetUserByID)))).Methods("GET")
+	r.Handle("/api/users", authenticated(middleware.RoleAuthMiddleware("view_all_users", http.HandlerFunc(usercontroller.GetAllUsers)))).Methods("GET")
+	r.Handle("/api/roles", authenticated(middleware.RoleAuthMiddleware("view_roles", http.HandlerFunc(rolecontroller.GetRoles)))).Methods("GET")
+	r.Handle("/api/createuser", authenticated(middleware.RoleAuthMiddleware("create_user", http.HandlerFunc(usercontroller.CreateUser)))).Methods("POST")
+	r.Handle("/api/user/edit/{id}", authenticated(middleware.RoleAuthMiddleware("edit_user", http.HandlerFunc(usercontroller.EditUserById)))).Methods("PUT")
+	r.Handle("/api/user/{id}", authenticated(middleware.RoleAuthMiddleware("delete_user", http.HandlerFunc(usercontroller.DeleteUser)))).Methods("PUT")
+	r.Handle("/api/history/add", authenticated(middleware.RoleAuthMiddleware("add_history", http.HandlerFunc(historycontroller.AddHistory)))).Methods("POST")
+	r.Handle("/api/history/user/{id}", authenticated(middleware.RoleAuthMiddleware("view_history_user", http.HandlerFunc(historycontroller.GetByIdUser)))).Methods("GET")
+	r.Handle("/api/latest-editor-name/{contentId}", authenticated(middleware.RoleAuthMiddleware("view_latest_editor", http.HandlerFunc(historycontroller.GetLatestEditorNameByContentId)))).Methods("GET")
+	r.Handle("/api/content/approve/{id}", authenticated(middleware.RoleAuthMiddleware("approve_content", http.HandlerFunc(contentcontroller.ApproveContent)))).Methods("PUT")
+	r.Handle("/api/content/reject/{id}", authenticated(middleware.RoleAuthMiddleware("reject_content", http.HandlerFunc(contentcontroller.RejectContent)))).Methods("PUT")
+	r.Handle("/api/permissions", authenticated(middleware.RoleAuthMiddleware("manage_role", http.HandlerFunc(permissioncontroller.GetPermissionList)))).Methods("GET")
+	r.Handle("/api/role_permissions", authenticated(middleware.RoleAuthMiddleware("view_permission", http.HandlerFunc(permissioncontroller.GetPermissionsByRole)))).Methods("GET")
+	r.Handle("/api/roles/{role_id}/permissions",authenticated(middleware.RoleAuthMiddleware("view_role_permission",http.HandlerFunc(rolePermissionController.GetPermissionsByRole)))).Methods("GET")
+	r.Handle("/api/roles/{role_id}/permissions/add/{permission_id}",authenticated(middleware.RoleAuthMiddleware("add_permission",http.HandlerFunc(rolePermissionController.AddPermissionToRole)))).Methods("POST") // 
+	r.Handle("/api/roles/{role_id}/permissions/delete/{permission_id}",authenticated(middleware.RoleAuthMiddleware("remove_permission",http.HandlerFunc(rolePermissionController.RemovePermissionFromRole)))).Methods("DELETE") //
+	r.Handle("/api/content/viewcount/{id}", authenticated(middleware.RoleAuthMiddleware("view_content", http.HandlerFunc(contentcontroller.GetContentViewCount)))).Methods("GET")
+	r.Handle("/api/content/resubmit/{id}", authenticated(middleware.RoleAuthMiddleware("resubmit_content", http.HandlerFunc(contentcontroller.ResubmitRejectedContent)))).Methods("PUT")
+	r.Handle("/api/content/increment-viewcount/{id}", authenticated(middleware.RoleAuthMiddleware("view_content", http.HandlerFunc(contentcontroller.IncrementViewCount)))).Methods("PUT")
+	r.Handle("/api/analytics/dashboard", authenticated(middleware.RoleAuthMiddleware("view_dashboard", http.HandlerFunc(analyticscontroller.GetDashboard)))).Methods("GET")
+	r.Handle("/api/analytics/cache", authenticated(middleware.RoleAuthMiddleware("view_dashboard", http.HandlerFunc(analyticscontroller.GetCacheStats)))).Methods("GET")
+	r.Handle("/api/trending", authenticated(middleware.RoleAuthMiddleware("view_active_content", http.HandlerFunc(trendingcontroller.GetTrendingContents)))).Methods("GET")
+	r.Handle("/api/popular", authenticated(middleware.RoleAuthMiddleware("view_active_content", http.HandlerFunc(trendingcontroller.GetPopularContents)))).Methods("GET")
+	r.Handle("/api/content/{id}/related", authenticated(middleware.RoleAuthMiddleware("view_content", http.HandlerFunc(relatedcontroller.GetRelatedContents)))).Methods("GET")
+	r.Handle("/api/content/{id}/views/daily", authenticated(middleware.RoleAuthMiddleware("view_analytics", http.HandlerFunc(viewcontroller.GetContentDailyViews)))).Methods("GET")
+	r.Handle("/api/instance/{id}/views/daily", authenticated(middleware.RoleAuthMiddleware("view_analytics", http.HandlerFunc(viewcontroller.GetInstanceDailyViews)))).Methods("GET")
+	r.Handle("/api/content/{id}/attachments", authenticated(middleware.RoleAuthMiddleware("upload_attachment", http.HandlerFunc(attachmentcontroller.UploadAttachment)))).Methods("POST")
+	r.Handle("/api/content/{id}/attachments", authenticated(middleware.RoleAuthMiddleware("view_attachments", http.HandlerFunc(attachmentcontroller.GetAttachmentsByContentID)))).Methods("GET")
+	r.Handle("/api/attachment/{id}", authenticated(middleware.RoleAuthMiddleware("view_attachments", http.HandlerFunc(attachmentcontroller.DownloadAttachment)))).Methods("GET")
+	r.Handle("/api/attachment/delete/{id}", authenticated(middleware.RoleAuthMiddleware("delete_attachment", http.HandlerFunc(attachmentcontroller.DeleteAttachment)))).Methods("DELETE")
+	r.Handle("/api/security/login-attempts", authenticated(middleware.RoleAuthMiddleware("view_login_attempts", http.HandlerFunc(loginsecuritycontroller.GetLoginAttempts)))).Methods("GET")
+	r.Handle("/api/security/locked-accounts", authenticated(middleware.RoleAuthMiddleware("view_login_attempts", http.HandlerFunc(loginsecuritycontroller.GetLockedAccounts)))).Methods("GET")
+	r.Handle("/api/security/unlock/{id}", authenticated(middleware.RoleAuthMiddleware("unlock_account", http.HandlerFunc(loginsecuritycontroller.UnlockAccount)))).Methods("PUT")
+	r.Handle("/api/security/notifications", authenticated(middleware.RoleAuthMiddleware("view_login_attempts", http.HandlerFunc(loginsecuritycontroller.GetAdminNotifications)))).Methods("GET")
+	r.Handle("/api/security/notifications/{id}/read", authenticated(middleware.RoleAuthMiddleware("view_login_attempts", http.HandlerFunc(loginsecuritycontroller.MarkNotificationRead)))).Methods("PUT")
+	r.Handle("/api/security/keys", authenticated(middleware.RoleAuthMiddleware("manage_token_keys", http.HandlerFunc(tokenkeycontroller.GetTokenKeys)))).Methods("GET")
+	r.Handle("/api/security/keys/rotate", authenticated(middleware.RoleAuthMiddleware("manage_token_keys", http.HandlerFunc(tokenkeycontroller.RotateTokenKey)))).Methods("POST")
+	r.Handle("/api/security/keys/{kid}/retire", authenticated(middleware.RoleAuthMiddleware("manage_token_keys", http.HandlerFunc(tokenkeycontroller.RetireTokenKey)))).Methods("PUT")
+
+	// Sesi login: user mengelola sesinya sendiri, admin bisa mencabut semua sesi user lain
+	r.Handle("/api/sessions", authenticated(http.HandlerFunc(sessioncontroller.GetMySessions))).Methods("GET")
+	r.Handle("/api/sessions/revoke-others", authenticated(http.HandlerFunc(sessioncontroller.RevokeOtherSessions))).Methods("POST")
+	r.Handle("/api/sessions/{id}", authenticated(http.HandlerFunc(sessioncontroller.RevokeMySession))).Methods("DELETE")
+	r.Handle("/api/user/{id}/sessions", authenticated(middleware.RoleAuthMiddleware("manage_sessions", http.HandlerFunc(sessioncontroller.GetUserSessions)))).Methods("GET")
+	r.Handle("/api/user/{id}/sessions", authenticated(middleware.RoleAuthMiddleware("manage_sessions", http.HandlerFunc(sessioncontroller.RevokeUserSessions)))).Methods("DELETE")
+
+	// Two-factor (TOTP): pendaftaran oleh user sendiri, reset dan kebijakan oleh admin
+	r.Handle("/api/2fa", authenticated(http.HandlerFunc(twofactorcontroller.GetTwoFactorStatus))).Methods("GET")
+	r.Handle("/api/2fa/setup", authenticated(http.HandlerFunc(twofactorcontroller.SetupTwoFactor))).Methods("POST")
+	r.Handle("/api/2fa/enable", authenticated(http.HandlerFunc(twofactorcontroller.EnableTwoFactor))).Methods("POST")
+	r.Handle("/api/2fa/disable", authenticated(http.HandlerFunc(twofactorcontroller.DisableTwoFactor))).Methods("POST")
+	r.Handle("/api/2fa/recovery-codes", authenticated(http.HandlerFunc(twofactorcontroller.RegenerateRecoveryCodes))).Methods("POST")
+	r.Handle("/api/2fa/policy", authenticated(middleware.RoleAuthMiddleware("manage_two_factor", http.HandlerFunc(twofactorcontroller.GetTwoFactorPolicy)))).Methods("GET")
+	r.Handle("/api/2fa/policy", authenticated(middleware.RoleAuthMiddleware("manage_two_factor", http.HandlerFunc(twofactorcontroller.UpdateTwoFactorPolicy)))).Methods("PUT")
+	r.Handle("/api/user/{id}/2fa", authenticated(middleware.RoleAuthMiddleware("manage_two_factor", http.HandlerFunc(twofactorcontroller.ResetUserTwoFactor)))).Methods("DELETE")
+
+	// Izin menautkan akun yang sudah ada ke login LDAP/SSO dengan email yang sama
+	r.Handle("/api/user/{id}/external-link", authenticated(middleware.RoleAuthMiddleware("edit_user", http.HandlerFunc(usercontroller.SetUserExternalLink)))).Methods("PUT")
+
+	r.Handle("/api/guest", middleware.RateLimitByIP(guestLimiter, http.HandlerFunc(usercontroller.DefaultTokenHandler))).Methods("GET")
+
+	// Sitemap dan feed konten publik, tanpa JWT
+	r.HandleFunc("/sitemap.xml", feedcontroller.GetSitemap).Methods("GET")
+	r.HandleFunc("/feeds/rss.xml", feedcontroller.GetRSSFeed).Methods("GET")
+	r.HandleFunc("/feeds/atom.xml", feedcontroller.GetAtomFeed).Methods("GET")
+
+	// API baca publik tanpa login, dibatasi per IP
+	r.Handle("/api/public/contents", middleware.RateLimitByIP(publicLimiter, http.HandlerFunc(publiccontroller.GetPublicContents))).Methods("GET")
+	r.Handle("/api/public/contents/{id}", middleware.RateLimitByIP(publicLimiter, http.HandlerFunc(publiccontroller.GetPublicContentByID))).Methods("GET")
 
 	// Endpoint Untuk decyrypt
 	r.HandleFunc("/api/decode", helpers.GetDecodedJWT).Methods("POST")
 
 	// Endpoint tanpa middleware untuk login
-	r.HandleFunc("/api/login", usercontroller.Login).Methods("POST")
+	r.Handle("/api/login", middleware.RateLimitByIP(loginLimiter, http.HandlerFunc(usercontroller.Login))).Methods("POST")
+	r.Handle("/api/login/2fa", middleware.RateLimitByIP(loginLimiter, http.HandlerFunc(twofactorcontroller.LoginTwoFactor))).Methods("POST")
+	r.Handle("/api/login/2fa/setup", middleware.RateLimitByIP(loginLimiter, http.HandlerFunc(twofactorcontroller.LoginTwoFactorSetup))).Methods("POST")
+	r.Handle("/api/login/2fa/enable", middleware.RateLimitByIP(loginLimiter, http.HandlerFunc(twofactorcontroller.LoginTwoFactorEnable))).Methods("POST")
+	// Single sign-on OpenID Connect (authorization code + PKCE)
+	r.HandleFunc("/api/sso", ssocontroller.GetSSOProviders).Methods("GET")
+	r.Handle("/api/sso/oidc/login", middleware.RateLimitByIP(loginLimiter, http.HandlerFunc(ssocontroller.StartOIDCLogin))).Methods("GET")
+	r.HandleFunc("/api/sso/oidc/callback", ssocontroller.OIDCCallback).Methods("GET")
+	r.Handle("/api/sso/exchange", middleware.RateLimitByIP(loginLimiter, http.HandlerFunc(ssocontroller.ExchangeSSOCode))).Methods("POST")
+	r.Handle("/api/token/refresh", middleware.RateLimitByIP(refreshLimiter, http.HandlerFunc(tokencontroller.RefreshToken))).Methods("POST")
+	r.HandleFunc("/api/logout", tokencontroller.Logout).Methods("POST")
+
+	// Lupa password: link reset sekali pakai dikirim lewat email
+	r.Handle("/api/password/forgot", middleware.RateLimitByIP(passwordResetLimiter, http.HandlerFunc(passwordresetcontroller.RequestPasswordReset))).Methods("POST")
+	r.Handle("/api/password/reset", middleware.RateLimitByIP(passwordResetLimiter, http.HandlerFunc(passwordresetcontroller.ResetPassword))).Methods("POST")
+
+	// Ganti password sendiri; aturan password berlaku untuk buat/edit user, reset dan ganti password
+	r.HandleFunc("/api/password/policy", passwordcontroller.GetPasswordPolicy).Methods("GET")
+	r.Handle("/api/password/change", middleware.RateLimitByIP(loginLimiter, authenticated(http.HandlerFunc(passwordcontroller.ChangePassword)))).Methods("POST")
+
+	// View konten dibuffer di memori dan ditulis ke database setiap 10 detik
+	controllers.StartViewCounter(10 * time.Second)
 
 	// Jalankan server dengan middleware CORS
-	log.Println("Server is running on port 3000")
-	if err := http.ListenAndServe(":3000", cors(r)); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	server := &http.Server{Addr: cfg.Addr(), Handler: cors(middleware.Compress(r))}
+	go func() {
+		log.Printf("Server is running on port %s (%s)", cfg.Port, cfg.Env)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("Failed to start server: %v", err)
+		}
+	}()
+
+	// Tunggu sinyal shutdown, lalu selesaikan request yang berjalan dan flush buffer view
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	<-stop
+
+	log.Println("Shutting down server...")
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("Server shutdown error: %v", err)
+	}
+	if err := controllers.StopViewCounter(); err != nil {
+		log.Printf("Failed to flush view counts: %v", err)
 	}
 }
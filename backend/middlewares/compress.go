@@ -0,0 +1,228 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+)
+
+// Respons lebih kecil dari ini dikirim apa adanya karena overhead kompresi tidak sebanding
+const compressMinSize = 1024
+
+// Tipe konten yang layak dikompres; gambar, PDF dan arsip sudah terkompresi
+var compressibleTypes = []string{
+	"application/json",
+	"application/xml",
+	"application/rss+xml",
+	"application/atom+xml",
+	"application/javascript",
+	"text/",
+	"image/svg+xml",
+}
+
+var gzipWriterPool = sync.Pool{New: func() interface{} {
+	writer, _ := gzip.NewWriterLevel(io.Discard, gzip.DefaultCompression)
+	return writer
+}}
+
+var brotliWriterPool = sync.Pool{New: func() interface{} {
+	return brotli.NewWriterLevel(io.Discard, brotli.DefaultCompression)
+}}
+
+// Compress mengompres respons dengan brotli atau gzip sesuai Accept-Encoding klien
+func Compress(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+		if encoding == "" || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &compressWriter{ResponseWriter: w, encoding: encoding, status: http.StatusOK}
+		defer cw.Close()
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// negotiateEncoding memilih br lalu gzip, mengabaikan encoding dengan q=0
+func negotiateEncoding(header string) string {
+	accepted := map[string]bool{}
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		name := strings.ToLower(strings.TrimSpace(fields[0]))
+		quality := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if value, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					quality = value
+				}
+			}
+		}
+		if quality > 0 {
+			accepted[name] = true
+		}
+	}
+
+	switch {
+	case accepted["br"]:
+		return "br"
+	case accepted["gzip"]:
+		return "gzip"
+	}
+	return ""
+}
+
+func isCompressible(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	for _, prefix := range compressibleTypes {
+		if strings.HasPrefix(contentType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// compressWriter menahan awal respons sampai compressMinSize byte untuk memutuskan
+// apakah respons dikompres. Header baru dikirim setelah keputusan itu dibuat.
+type compressWriter struct {
+	http.ResponseWriter
+	encoding    string
+	status      int
+	wroteHeader bool
+	decided     bool
+	buffer      []byte
+	encoder     io.WriteCloser
+}
+
+func (cw *compressWriter) WriteHeader(status int) {
+	if cw.wroteHeader {
+		return
+	}
+	cw.wroteHeader = true
+	cw.status = status
+
+	// Respons tanpa body, yang sudah di-encode handler, atau potongan range tidak disentuh;
+	// offset Content-Range merujuk ke byte asli sehingga body 206 tidak boleh dikompres
+	header := cw.Header()
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified ||
+		status == http.StatusPartialContent || header.Get("Content-Range") != "" ||
+		header.Get("Content-Encoding") != "" || !isCompressible(header.Get("Content-Type")) {
+		cw.decide(false)
+	}
+}
+
+func (cw *compressWriter) Write(data []byte) (int, error) {
+	if !cw.wroteHeader {
+		if cw.Header().Get("Content-Type") == "" {
+			cw.Header().Set("Content-Type", http.DetectContentType(data))
+		}
+		cw.WriteHeader(http.StatusOK)
+	}
+
+	if cw.decided {
+		if cw.encoder != nil {
+			return cw.encoder.Write(data)
+		}
+		return cw.ResponseWriter.Write(data)
+	}
+
+	cw.buffer = append(cw.buffer, data...)
+	if len(cw.buffer) >= compressMinSize {
+		cw.decide(true)
+		if err := cw.flushBuffer(); err != nil {
+			return 0, err
+		}
+	}
+	return len(data), nil
+}
+
+// decide mengirim header dan menyiapkan encoder bila respons dikompres
+func (cw *compressWriter) decide(compress bool) {
+	if cw.decided {
+		return
+	}
+	cw.decided = true
+
+	if compress {
+		header := cw.Header()
+		header.Set("Content-Encoding", cw.encoding)
+		header.Del("Content-Length")
+		// ETag kuat tidak lagi berlaku untuk representasi yang dikompres
+		if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			header.Set("ETag", "W/"+etag)
+		}
+
+		switch cw.encoding {
+		case "br":
+			writer := brotliWriterPool.Get().(*brotli.Writer)
+			writer.Reset(cw.ResponseWriter)
+			cw.encoder = writer
+		case "gzip":
+			writer := gzipWriterPool.Get().(*gzip.Writer)
+			writer.Reset(cw.ResponseWriter)
+			cw.encoder = writer
+		}
+	}
+	cw.ResponseWriter.WriteHeader(cw.status)
+}
+
+func (cw *compressWriter) flushBuffer() error {
+	if len(cw.buffer) == 0 {
+		return nil
+	}
+	var err error
+	if cw.encoder != nil {
+		_, err = cw.encoder.Write(cw.buffer)
+	} else {
+		_, err = cw.ResponseWriter.Write(cw.buffer)
+	}
+	cw.buffer = nil
+	return err
+}
+
+// Flush mengirim data yang sudah ada, misalnya untuk respons streaming
+func (cw *compressWriter) Flush() {
+	if !cw.wroteHeader {
+		return
+	}
+	cw.decide(len(cw.buffer) >= compressMinSize)
+	cw.flushBuffer()
+	if flusher, ok := cw.encoder.(interface{ Flush() error }); ok {
+		flusher.Flush()
+	}
+	if flusher, ok := cw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Close menyelesaikan respons: body kecil dikirim tanpa kompresi, encoder dikembalikan ke pool
+func (cw *compressWriter) Close() error {
+	if !cw.wroteHeader {
+		return nil
+	}
+	cw.decide(false)
+	err := cw.flushBuffer()
+
+	switch encoder := cw.encoder.(type) {
+	case *brotli.Writer:
+		if closeErr := encoder.Close(); err == nil {
+			err = closeErr
+		}
+		brotliWriterPool.Put(encoder)
+	case *gzip.Writer:
+		if closeErr := encoder.Close(); err == nil {
+			err = closeErr
+		}
+		gzipWriterPool.Put(encoder)
+	}
+	cw.encoder = nil
+	return err
+}
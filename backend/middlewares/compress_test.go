@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompressSkipsRangeResponses(t *testing.T) {
+	body := strings.Repeat("lampiran wiki ", 500)
+	handler := Compress(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "lampiran.txt", time.Time{}, bytes.NewReader([]byte(body)))
+	}))
+
+	request := httptest.NewRequest(http.MethodGet, "/lampiran.txt", nil)
+	request.Header.Set("Accept-Encoding", "br, gzip")
+	request.Header.Set("Range", "bytes=100-2099")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusPartialContent {
+		t.Fatalf("status = %d, want 206", recorder.Code)
+	}
+	if encoding := recorder.Header().Get("Content-Encoding"); encoding != "" {
+		t.Errorf("range response was compressed with %s", encoding)
+	}
+	if got := recorder.Body.String(); got != body[100:2100] {
+		t.Errorf("range body has %d bytes, want the 2000 requested bytes", len(got))
+	}
+}
+
+func TestCompressFullResponse(t *testing.T) {
+	body := strings.Repeat("lampiran wiki ", 500)
+	handler := Compress(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "lampiran.txt", time.Time{}, bytes.NewReader([]byte(body)))
+	}))
+
+	request := httptest.NewRequest(http.MethodGet, "/lampiran.txt", nil)
+	request.Header.Set("Accept-Encoding", "gzip")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	if encoding := recorder.Header().Get("Content-Encoding"); encoding != "gzip" {
+		t.Errorf("Content-Encoding = %q, want gzip", encoding)
+	}
+	if recorder.Body.Len() >= len(body) {
+		t.Errorf("compressed body has %d bytes, original %d", recorder.Body.Len(), len(body))
+	}
+}
@@ -1,28 +1,25 @@
 package middleware
 
 import (
+	"backend/helpers"
 	"context"
-	"crypto/aes"
-	"crypto/cipher"
-	"encoding/base64"
 	"encoding/json"
 	"errors"
-	"fmt"
+	"log"
 	"net/http"
 	"strings"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-var jwtKey = []byte("wiki")
-var encryptionKey = []byte("myverystrongpasswordo32bitlength")
-
 type Claims struct {
 	ID          int      `json:"id"`
 	Role        string   `json:"role"`
 	RoleID      int64    `json:"role_id"`
 	Permissions []string `json:"permissions"`
 	InstanceID  int      `json:"instance_id"`
+	SessionID   string   `json:"sid"`
+	Version     int64    `json:"ver"`
 	jwt.RegisteredClaims
 }
 
@@ -36,38 +33,6 @@ type UnauthorizedResponse struct {
 	Code    int    `json:"code"`
 }
 
-func decryptToken(encryptedToken string) (string, error) {
-	decoded, err := base64.StdEncoding.DecodeString(encryptedToken)
-	if err != nil {
-		return "", fmt.Errorf("failed to decode base64 token: %v", err)
-	}
-
-	block, err := aes.NewCipher(encryptionKey)
-	if err != nil {
-		return "", fmt.Errorf("failed to create AES cipher: %v", err)
-	}
-
-	aesGCM, err := cipher.NewGCM(block)
-	if err != nil {
-		return "", fmt.Errorf("failed to create GCM mode: %v", err)
-	}
-
-	nonceSize := aesGCM.NonceSize()
-	if len(decoded) < nonceSize {
-		return "", fmt.Errorf("invalid encrypted token format")
-	}
-
-	nonce := decoded[:nonceSize]
-	ciphertext := decoded[nonceSize:]
-
-	plaintext, err := aesGCM.Open(nil, nonce, ciphertext, nil)
-	if err != nil {
-		return "", fmt.Errorf("failed to decrypt token: %v", err)
-	}
-
-	return string(plaintext), nil
-}
-
 // Helper function to send unauthorized response
 func sendUnauthorizedResponse(w http.ResponseWriter, message string) {
 	w.Header().Set("Content-Type", "application/json")
@@ -101,28 +66,44 @@ func JWTAuth(next http.Handler) http.Handler {
 		}
 
 		encryptedToken := strings.TrimPrefix(authHeader, "Bearer ")
-		tokenString, err := decryptToken(encryptedToken)
-		if err != nil {
-			sendUnauthorizedResponse(w, "Failed to decrypt token")
+		claims := &Claims{}
+		token, err := helpers.ParseToken(encryptedToken, claims)
+		if err != nil || !token.Valid {
+			switch {
+			case errors.Is(err, jwt.ErrTokenExpired):
+				sendUnauthorizedResponse(w, "Token has expired")
+			case errors.Is(err, helpers.ErrTokenDecrypt):
+				sendUnauthorizedResponse(w, "Failed to decrypt token")
+			case errors.Is(err, helpers.ErrUnknownTokenKey):
+				// Kunci sudah dipensiunkan; client perlu refresh atau login ulang
+				sendUnauthorizedResponse(w, "Token key has been retired")
+			default:
+				sendUnauthorizedResponse(w, "Invalid token")
+			}
 			return
 		}
 
-		claims := &Claims{}
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		// Token user login harus terikat ke sesi yang belum dicabut; token guest tidak punya sesi
+		if claims.ID != 0 {
+			if claims.SessionID == "" {
+				sendUnauthorizedResponse(w, "Session is no longer valid, please log in again")
+				return
 			}
-			return jwtKey, nil
-		})
-
-		if err != nil || !token.Valid {
-			// Check if token is expired
-			if errors.Is(err, jwt.ErrTokenExpired) {
-				sendUnauthorizedResponse(w, "Token has expired")
+			state, err := checkSession(r, claims.SessionID, claims.ID, claims.Version)
+			if err != nil {
+				log.Printf("Error checking session: %v", err)
+				sendUnauthorizedResponse(w, "Failed to verify session")
 				return
-			}						
-			sendUnauthorizedResponse(w, "Invalid token")
-			return
+			}
+			switch state {
+			case sessionRevoked:
+				sendUnauthorizedResponse(w, "Session has been revoked")
+				return
+			case sessionOutdated:
+				// Role, instansi atau password user berubah; client perlu refresh token
+				sendUnauthorizedResponse(w, "Token is outdated, please refresh")
+				return
+			}
 		}
 
 		ctx := context.WithValue(r.Context(), UserContextKey, claims)
@@ -0,0 +1,183 @@
+package middleware
+
+import (
+	"backend/config"
+	"encoding/json"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// tokenBucket menyimpan sisa token untuk satu key (misalnya satu IP)
+type tokenBucket struct {
+	tokens   float64
+	lastSeen time.Time
+}
+
+// RateLimiter adalah token bucket per key: setiap key mendapat burst token
+// yang terisi kembali sebanyak ratePerSecond token per detik
+type RateLimiter struct {
+	mu            sync.Mutex
+	buckets       map[string]*tokenBucket
+	ratePerSecond float64
+	burst         float64
+	lastCleanup   time.Time
+}
+
+// RateLimitResult adalah hasil pengambilan token, dipakai untuk header RateLimit-*
+type RateLimitResult struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset adalah lama waktu sampai bucket penuh kembali
+	Reset time.Duration
+	// RetryAfter adalah lama waktu sampai token berikutnya tersedia (jika ditolak)
+	RetryAfter time.Duration
+}
+
+func NewRateLimiter(requestsPerMinute int, burst int) *RateLimiter {
+	return &RateLimiter{
+		buckets:       map[string]*tokenBucket{},
+		ratePerSecond: float64(requestsPerMinute) / 60,
+		burst:         float64(burst),
+		lastCleanup:   time.Now(),
+	}
+}
+
+// RateLimiterFromConfig membuat limiter dengan batas dari konfigurasi (rate_limits
+// di file config atau RATE_LIMIT_<NAME>="<request per menit>/<burst>").
+// Nilai default dipakai jika batas untuk nama tersebut tidak diatur.
+func RateLimiterFromConfig(name string, requestsPerMinute int, burst int) *RateLimiter {
+	return NewRateLimiter(config.Get().RateLimit(name, requestsPerMinute, burst))
+}
+
+// Allow mengambil satu token untuk key. Jika habis, hasilnya false beserta
+// lama waktu tunggu sampai token berikutnya tersedia.
+func (l *RateLimiter) Allow(key string) (bool, time.Duration) {
+	result := l.Take(key)
+	return result.Allowed, result.RetryAfter
+}
+
+// Take sama seperti Allow tetapi mengembalikan sisa kuota untuk header RateLimit-*
+func (l *RateLimiter) Take(key string) RateLimitResult {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.cleanup(now)
+
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: l.burst, lastSeen: now}
+		l.buckets[key] = bucket
+	}
+
+	elapsed := now.Sub(bucket.lastSeen).Seconds()
+	bucket.tokens = math.Min(l.burst, bucket.tokens+elapsed*l.ratePerSecond)
+	bucket.lastSeen = now
+
+	result := RateLimitResult{Limit: int(l.burst)}
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = l.secondsFor(1 - bucket.tokens)
+	}
+	result.Remaining = int(bucket.tokens)
+	result.Reset = l.secondsFor(l.burst - bucket.tokens)
+	return result
+}
+
+// secondsFor menghitung lama waktu untuk mengisi sejumlah token
+func (l *RateLimiter) secondsFor(tokens float64) time.Duration {
+	return time.Duration(tokens / l.ratePerSecond * float64(time.Second))
+}
+
+// cleanup membuang bucket yang sudah penuh kembali agar map tidak terus membesar
+func (l *RateLimiter) cleanup(now time.Time) {
+	if now.Sub(l.lastCleanup) < time.Minute {
+		return
+	}
+	l.lastCleanup = now
+
+	refill := l.secondsFor(l.burst)
+	for key, bucket := range l.buckets {
+		if now.Sub(bucket.lastSeen) > refill {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// ClientIP mengambil IP pemanggil dari RemoteAddr
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// RateLimitByIP membatasi jumlah request per IP memakai limiter yang diberikan
+func RateLimitByIP(limiter *RateLimiter, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !applyRateLimit(w, limiter, "ip:"+ClientIP(r)) {
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RateLimitByUser membatasi jumlah request per user id dari Claims, sehingga user
+// di balik NAT yang sama tidak saling menghabiskan kuota. Harus dipasang di dalam
+// JWTAuth; token guest (tanpa id) dibatasi per IP.
+func RateLimitByUser(limiter *RateLimiter, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := "ip:" + ClientIP(r)
+		if claims, ok := r.Context().Value(UserContextKey).(*Claims); ok && claims.ID != 0 {
+			key = "user:" + strconv.Itoa(claims.ID)
+		}
+		if !applyRateLimit(w, limiter, key) {
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// applyRateLimit mengambil token untuk key, menulis header RateLimit-* dan
+// mengirim 429 jika kuota habis
+func applyRateLimit(w http.ResponseWriter, limiter *RateLimiter, key string) bool {
+	result := limiter.Take(key)
+
+	header := w.Header()
+	header.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+	header.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	header.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+
+	if !result.Allowed {
+		sendTooManyRequests(w, result.RetryAfter)
+		return false
+	}
+	return true
+}
+
+func ceilSeconds(duration time.Duration) int {
+	return int(math.Ceil(duration.Seconds()))
+}
+
+func sendTooManyRequests(w http.ResponseWriter, wait time.Duration) {
+	seconds := ceilSeconds(wait)
+	if seconds < 1 {
+		seconds = 1
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	w.WriteHeader(http.StatusTooManyRequests)
+	json.NewEncoder(w).Encode(UnauthorizedResponse{
+		Message: "Too many requests, please try again later",
+		Code:    http.StatusTooManyRequests,
+	})
+}
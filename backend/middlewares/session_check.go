@@ -0,0 +1,149 @@
+package middleware
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Status sesi aktif dan versi token user disimpan sebentar di memori agar JWTAuth
+// tidak query setiap request. Sesi yang dicabut atau user yang diubah lewat server
+// ini langsung dibuang dari cache oleh ForgetSession dan ForgetUser.
+const (
+	sessionCacheTTL      = 30 * time.Second
+	sessionTouchInterval = time.Minute
+)
+
+type sessionState int
+
+const (
+	sessionActive sessionState = iota
+	sessionRevoked
+	// sessionOutdated berarti sesi masih aktif tetapi token diterbitkan sebelum
+	// versi token user dinaikkan
+	sessionOutdated
+)
+
+type sessionCacheEntry struct {
+	userID       int
+	tokenVersion int64
+	checkedAt    time.Time
+	touchedAt    time.Time
+}
+
+var (
+	sessionCacheMu sync.Mutex
+	sessionCache   = map[string]*sessionCacheEntry{}
+)
+
+// ForgetSession membuang status sesi dari cache setelah sesi dicabut
+func ForgetSession(sessionIDs ...string) {
+	sessionCacheMu.Lock()
+	defer sessionCacheMu.Unlock()
+	for _, sessionID := range sessionIDs {
+		delete(sessionCache, sessionID)
+	}
+}
+
+// ForgetUser membuang semua sesi user dari cache setelah role, instansi,
+// password atau status akunnya berubah
+func ForgetUser(userID int) {
+	sessionCacheMu.Lock()
+	defer sessionCacheMu.Unlock()
+	for sessionID, entry := range sessionCache {
+		if entry.userID == userID {
+			delete(sessionCache, sessionID)
+		}
+	}
+}
+
+// checkSession memastikan sesi milik user masih aktif, user belum dihapus, dan
+// token diterbitkan dengan versi token user saat ini. last_seen_at diperbarui
+// paling sering sekali per menit.
+func checkSession(r *http.Request, sessionID string, userID int, tokenVersion int64) (sessionState, error) {
+	now := time.Now()
+
+	sessionCacheMu.Lock()
+	entry, cached := sessionCache[sessionID]
+	if cached && entry.userID == userID && now.Sub(entry.checkedAt) < sessionCacheTTL {
+		if tokenVersion != entry.tokenVersion {
+			sessionCacheMu.Unlock()
+			return sessionOutdated, nil
+		}
+		touch := now.Sub(entry.touchedAt) >= sessionTouchInterval
+		if touch {
+			entry.touchedAt = now
+		}
+		sessionCacheMu.Unlock()
+		if touch {
+			touchSession(r, sessionID)
+		}
+		return sessionActive, nil
+	}
+	sessionCacheMu.Unlock()
+
+	if DB == nil {
+		return sessionRevoked, fmt.Errorf("database connection is not initialized")
+	}
+
+	var current int64
+	err := DB.QueryRow(`
+        SELECT u.token_version
+        FROM sessions s
+        JOIN user u ON u.id = s.user_id
+        WHERE s.id = ? AND s.user_id = ? AND s.revoked_at IS NULL AND u.deleted_at IS NULL`,
+		sessionID, userID).Scan(&current)
+	if err == sql.ErrNoRows {
+		ForgetSession(sessionID)
+		return sessionRevoked, nil
+	}
+	if err != nil {
+		return sessionRevoked, fmt.Errorf("failed to check session: %w", err)
+	}
+
+	sessionCacheMu.Lock()
+	touch := !cached || now.Sub(entry.touchedAt) >= sessionTouchInterval
+	touchedAt := now
+	if !touch {
+		touchedAt = entry.touchedAt
+	}
+	sessionCache[sessionID] = &sessionCacheEntry{userID: userID, tokenVersion: current, checkedAt: now, touchedAt: touchedAt}
+	pruneSessionCache(now)
+	sessionCacheMu.Unlock()
+
+	if tokenVersion != current {
+		return sessionOutdated, nil
+	}
+	if touch {
+		touchSession(r, sessionID)
+	}
+	return sessionActive, nil
+}
+
+// pruneSessionCache membuang entri lama agar map tidak terus membesar; dipanggil dengan lock
+func pruneSessionCache(now time.Time) {
+	if len(sessionCache) < 10000 {
+		return
+	}
+	for sessionID, entry := range sessionCache {
+		if now.Sub(entry.checkedAt) >= sessionCacheTTL {
+			delete(sessionCache, sessionID)
+		}
+	}
+}
+
+// touchSession mencatat waktu dan IP terakhir sesi dipakai
+func touchSession(r *http.Request, sessionID string) {
+	loc, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		loc = time.Local
+	}
+	_, err = DB.Exec("UPDATE sessions SET last_seen_at = ?, ip_address = ? WHERE id = ?",
+		time.Now().In(loc).Format("2006-01-02 15:04:05"), ClientIP(r), sessionID)
+	if err != nil {
+		log.Printf("Failed to update last seen of session %s: %v", sessionID, err)
+	}
+}
@@ -0,0 +1,20 @@
+-- Dokumen sumber (PDF, DOCX, TXT) yang dilampirkan pada sebuah konten.
+-- Teks hasil ekstraksi diindeks FULLTEXT agar ikut dicari oleh /api/content.
+CREATE TABLE IF NOT EXISTS content_attachments (
+    id             BIGINT AUTO_INCREMENT PRIMARY KEY,
+    content_id     BIGINT       NOT NULL,
+    file_name      VARCHAR(255) NOT NULL,
+    mime_type      VARCHAR(100) NOT NULL,
+    size           BIGINT       NOT NULL,
+    storage_path   VARCHAR(512) NOT NULL,
+    extracted_text LONGTEXT,
+    uploaded_by    BIGINT       NOT NULL,
+    created_at     DATETIME     NOT NULL,
+    INDEX idx_content_attachments_content (content_id),
+    FULLTEXT INDEX ft_content_attachments_text (extracted_text)
+);
+
+INSERT INTO permissions (name, description) VALUES
+    ('upload_attachment', 'Upload dokumen lampiran pada konten'),
+    ('view_attachments', 'Melihat dan mengunduh dokumen lampiran konten'),
+    ('delete_attachment', 'Menghapus dokumen lampiran konten');
@@ -0,0 +1,21 @@
+-- Satu baris per pembaca per konten per hari, dipakai untuk menghitung view unik.
+-- viewer_key berisi "u:<user id>" untuk user login atau "g:<session id>" untuk tamu.
+CREATE TABLE IF NOT EXISTS content_views (
+    content_id BIGINT      NOT NULL,
+    viewer_key VARCHAR(80) NOT NULL,
+    view_date  DATE        NOT NULL,
+    PRIMARY KEY (content_id, viewer_key, view_date)
+);
+
+-- Agregat harian view unik per konten
+CREATE TABLE IF NOT EXISTS content_view_daily (
+    content_id  BIGINT NOT NULL,
+    instance_id BIGINT NOT NULL,
+    view_date   DATE   NOT NULL,
+    views       INT    NOT NULL DEFAULT 0,
+    PRIMARY KEY (content_id, view_date),
+    INDEX idx_content_view_daily_instance (instance_id, view_date)
+);
+
+INSERT INTO permissions (name, description) VALUES
+    ('view_analytics', 'Melihat statistik view harian konten dan instansi');
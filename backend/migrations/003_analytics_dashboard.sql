@@ -0,0 +1,2 @@
+INSERT INTO permissions (name, description) VALUES
+    ('view_dashboard', 'Melihat dashboard statistik wiki untuk administrator');
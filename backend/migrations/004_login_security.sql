@@ -0,0 +1,40 @@
+-- Setiap percobaan login, berhasil maupun gagal, untuk audit keamanan.
+-- user_id kosong jika email tidak terdaftar.
+CREATE TABLE IF NOT EXISTS login_attempts (
+    id           BIGINT AUTO_INCREMENT PRIMARY KEY,
+    email        VARCHAR(255) NOT NULL,
+    user_id      BIGINT       NULL,
+    ip_address   VARCHAR(45)  NOT NULL,
+    user_agent   VARCHAR(255) NOT NULL DEFAULT '',
+    success      TINYINT(1)   NOT NULL,
+    reason       VARCHAR(50)  NOT NULL DEFAULT '',
+    attempted_at DATETIME     NOT NULL,
+    INDEX idx_login_attempts_email (email, attempted_at),
+    INDEX idx_login_attempts_user (user_id, attempted_at),
+    INDEX idx_login_attempts_time (attempted_at)
+);
+
+-- Status penguncian akun. failed_count adalah kegagalan berturut-turut sejak login
+-- berhasil atau penguncian terakhir; lockout_count menentukan lama kunci berikutnya.
+CREATE TABLE IF NOT EXISTS account_lockouts (
+    user_id       BIGINT   NOT NULL PRIMARY KEY,
+    failed_count  INT      NOT NULL DEFAULT 0,
+    lockout_count INT      NOT NULL DEFAULT 0,
+    locked_until  DATETIME NULL,
+    updated_at    DATETIME NOT NULL
+);
+
+-- Pemberitahuan untuk administrator, misalnya saat akun dikunci
+CREATE TABLE IF NOT EXISTS admin_notifications (
+    id         BIGINT AUTO_INCREMENT PRIMARY KEY,
+    kind       VARCHAR(50)  NOT NULL,
+    message    VARCHAR(500) NOT NULL,
+    user_id    BIGINT       NULL,
+    created_at DATETIME     NOT NULL,
+    read_at    DATETIME     NULL,
+    INDEX idx_admin_notifications_unread (read_at, created_at)
+);
+
+INSERT INTO permissions (name, description) VALUES
+    ('view_login_attempts', 'Melihat riwayat percobaan login, akun terkunci dan notifikasi keamanan'),
+    ('unlock_account', 'Membuka kunci akun yang terkunci karena gagal login');
@@ -0,0 +1,3 @@
+-- Password disimpan sebagai hash bcrypt (60 karakter). Baris lama yang masih
+-- plaintext tetap bisa login dan otomatis di-hash ulang saat login berhasil.
+ALTER TABLE user MODIFY password VARCHAR(255) NOT NULL;
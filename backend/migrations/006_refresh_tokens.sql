@@ -0,0 +1,17 @@
+-- Refresh token yang diterbitkan saat login. Setiap refresh token hanya bisa dipakai
+-- sekali dan diganti token baru dengan family_id yang sama. Token disimpan sebagai
+-- hash SHA-256, bukan nilai aslinya.
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id         BIGINT AUTO_INCREMENT PRIMARY KEY,
+    user_id    BIGINT   NOT NULL,
+    family_id  CHAR(32) NOT NULL,
+    parent_id  BIGINT   NULL,
+    token_hash CHAR(64) NOT NULL,
+    created_at DATETIME NOT NULL,
+    expires_at DATETIME NOT NULL,
+    used_at    DATETIME NULL,
+    revoked_at DATETIME NULL,
+    UNIQUE INDEX idx_refresh_tokens_hash (token_hash),
+    INDEX idx_refresh_tokens_family (family_id),
+    INDEX idx_refresh_tokens_user (user_id)
+);
@@ -0,0 +1,16 @@
+-- Sesi login per perangkat. id sama dengan family_id refresh token dan dengan
+-- claim "sid" pada access token, sehingga JWTAuth bisa menolak sesi yang dicabut.
+CREATE TABLE IF NOT EXISTS sessions (
+    id           CHAR(32)     NOT NULL PRIMARY KEY,
+    user_id      BIGINT       NOT NULL,
+    device       VARCHAR(100) NOT NULL DEFAULT '',
+    user_agent   VARCHAR(255) NOT NULL DEFAULT '',
+    ip_address   VARCHAR(45)  NOT NULL DEFAULT '',
+    created_at   DATETIME     NOT NULL,
+    last_seen_at DATETIME     NOT NULL,
+    revoked_at   DATETIME     NULL,
+    INDEX idx_sessions_user (user_id, revoked_at)
+);
+
+INSERT INTO permissions (name, description) VALUES
+    ('manage_sessions', 'Melihat dan mencabut sesi login user lain');
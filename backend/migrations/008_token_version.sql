@@ -0,0 +1,4 @@
+-- Versi token per user. Access token membawa versi saat diterbitkan (claim "ver");
+-- JWTAuth menolak token dengan versi lama sehingga perubahan role, instansi,
+-- password dan penghapusan user langsung berlaku pada request berikutnya.
+ALTER TABLE user ADD COLUMN token_version BIGINT NOT NULL DEFAULT 0;
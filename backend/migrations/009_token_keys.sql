@@ -0,0 +1,18 @@
+-- Keyring kunci token. jwt_key dan encryption_key disimpan terenkripsi dengan
+-- ENCRYPTION_KEY dari konfigurasi. Status: active (menerbitkan token, tepat satu),
+-- previous (hanya memverifikasi token lama) dan retired (ditolak).
+-- Saat tabel kosong server mengisi kunci "legacy" dari JWT_KEY/ENCRYPTION_KEY
+-- sehingga token yang sudah beredar tetap berlaku.
+CREATE TABLE IF NOT EXISTS token_keys (
+    kid            VARCHAR(32)  NOT NULL PRIMARY KEY,
+    jwt_key        VARCHAR(255) NOT NULL,
+    encryption_key VARCHAR(255) NOT NULL,
+    status         ENUM('active', 'previous', 'retired') NOT NULL,
+    created_at     DATETIME     NOT NULL,
+    rotated_at     DATETIME     NULL,
+    retired_at     DATETIME     NULL,
+    INDEX idx_token_keys_status (status)
+);
+
+INSERT INTO permissions (name, description) VALUES
+    ('manage_token_keys', 'Melihat, merotasi dan memensiunkan kunci token');
@@ -0,0 +1,43 @@
+-- TOTP dua langkah. secret disimpan terenkripsi dengan ENCRYPTION_KEY;
+-- enabled_at NULL berarti pendaftaran belum dikonfirmasi. last_used_step
+-- mencegah kode yang sama dipakai dua kali.
+CREATE TABLE IF NOT EXISTS user_totp (
+    user_id        BIGINT       NOT NULL PRIMARY KEY,
+    secret         VARCHAR(255) NOT NULL,
+    created_at     DATETIME     NOT NULL,
+    enabled_at     DATETIME     NULL,
+    last_used_step BIGINT       NOT NULL DEFAULT 0
+);
+
+-- Kode pemulihan sekali pakai, hanya hash-nya yang disimpan
+CREATE TABLE IF NOT EXISTS user_recovery_codes (
+    id         BIGINT   NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    user_id    BIGINT   NOT NULL,
+    code_hash  CHAR(64) NOT NULL,
+    used_at    DATETIME NULL,
+    INDEX idx_recovery_codes_user (user_id, code_hash)
+);
+
+-- Langkah kedua login: token challenge diberikan setelah password benar
+CREATE TABLE IF NOT EXISTS two_factor_challenges (
+    token_hash CHAR(64) NOT NULL PRIMARY KEY,
+    user_id    BIGINT   NOT NULL,
+    created_at DATETIME NOT NULL,
+    expires_at DATETIME NOT NULL,
+    attempts   INT      NOT NULL DEFAULT 0,
+    used_at    DATETIME NULL,
+    INDEX idx_two_factor_challenges_user (user_id)
+);
+
+-- Role yang memiliki salah satu permission ini wajib memakai 2FA
+CREATE TABLE IF NOT EXISTS two_factor_policy (
+    permission_name VARCHAR(100) NOT NULL PRIMARY KEY
+);
+
+INSERT INTO two_factor_policy (permission_name) VALUES
+    ('manage_role'),
+    ('delete_user'),
+    ('approve_content');
+
+INSERT INTO permissions (name, description) VALUES
+    ('manage_two_factor', 'Mengatur kebijakan 2FA dan mereset 2FA user');
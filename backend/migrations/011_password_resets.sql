@@ -0,0 +1,11 @@
+-- Token reset password sekali pakai yang dikirim lewat email. Hanya hash
+-- token yang disimpan; token berlaku 30 menit.
+CREATE TABLE IF NOT EXISTS password_resets (
+    token_hash CHAR(64)    NOT NULL PRIMARY KEY,
+    user_id    BIGINT      NOT NULL,
+    ip_address VARCHAR(45) NOT NULL DEFAULT '',
+    created_at DATETIME    NOT NULL,
+    expires_at DATETIME    NOT NULL,
+    used_at    DATETIME    NULL,
+    INDEX idx_password_resets_user (user_id, created_at)
+);
@@ -0,0 +1,9 @@
+-- Hash password lama per user untuk mencegah password yang sama dipakai ulang.
+-- Jumlah yang disimpan mengikuti password_policy.history di konfigurasi.
+CREATE TABLE IF NOT EXISTS password_history (
+    id            BIGINT       NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    user_id       BIGINT       NOT NULL,
+    password_hash VARCHAR(255) NOT NULL,
+    created_at    DATETIME     NOT NULL,
+    INDEX idx_password_history_user (user_id, id)
+);
@@ -0,0 +1,7 @@
+-- Sumber akun user: 'local' memakai password di tabel ini, selain itu (mis. 'ldap')
+-- password diperiksa oleh sumber tersebut dan kolom password dibiarkan kosong.
+-- external_id adalah id user di sumber tersebut (DN LDAP atau subject SSO).
+ALTER TABLE user
+    ADD COLUMN auth_provider VARCHAR(20)  NOT NULL DEFAULT 'local',
+    ADD COLUMN external_id   VARCHAR(255) NULL,
+    ADD UNIQUE INDEX idx_user_external (auth_provider, external_id);
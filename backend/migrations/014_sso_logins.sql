@@ -0,0 +1,21 @@
+-- Login SSO (OpenID Connect) yang sedang berjalan. state, nonce dan code_verifier
+-- PKCE dibuat saat user diarahkan ke identity provider dan hanya bisa dipakai
+-- sekali saat callback; hanya hash state yang disimpan.
+CREATE TABLE IF NOT EXISTS oidc_logins (
+    state_hash    CHAR(64)     NOT NULL PRIMARY KEY,
+    nonce         VARCHAR(64)  NOT NULL,
+    code_verifier VARCHAR(128) NOT NULL,
+    created_at    DATETIME     NOT NULL,
+    expires_at    DATETIME     NOT NULL,
+    used_at       DATETIME     NULL
+);
+
+-- Kode sekali pakai yang dikirim ke frontend setelah callback SSO berhasil,
+-- ditukar dengan token wiki lewat POST /api/sso/exchange
+CREATE TABLE IF NOT EXISTS sso_login_codes (
+    code_hash  CHAR(64) NOT NULL PRIMARY KEY,
+    user_id    BIGINT   NOT NULL,
+    created_at DATETIME NOT NULL,
+    expires_at DATETIME NOT NULL,
+    used_at    DATETIME NULL
+);
@@ -0,0 +1,22 @@
+-- Identitas LDAP/SSO yang tertaut ke user wiki. Satu user bisa punya beberapa
+-- identitas (mis. DN LDAP dan subject OIDC); user.auth_provider/external_id tetap
+-- mencatat sumber yang membuat akun dan tidak berubah saat login lewat sumber lain.
+CREATE TABLE IF NOT EXISTS user_identities (
+    id          BIGINT       NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    user_id     BIGINT       NOT NULL,
+    provider    VARCHAR(20)  NOT NULL,
+    external_id VARCHAR(255) NOT NULL,
+    created_at  DATETIME     NOT NULL,
+    UNIQUE INDEX idx_user_identities_external (provider, external_id),
+    INDEX idx_user_identities_user (user_id)
+);
+
+INSERT IGNORE INTO user_identities (user_id, provider, external_id, created_at)
+SELECT id, auth_provider, external_id, NOW()
+FROM user
+WHERE auth_provider <> 'local' AND external_id IS NOT NULL;
+
+-- Akun yang sudah ada hanya ditautkan ke login LDAP/SSO dengan email yang sama jika
+-- admin mengizinkannya untuk akun tersebut, atau link_existing_accounts diaktifkan
+ALTER TABLE user
+    ADD COLUMN external_link_allowed TINYINT(1) NOT NULL DEFAULT 0;
@@ -0,0 +1,231 @@
+package models
+
+import (
+	"backend/config"
+	"backend/entities"
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+type AnalyticsModel struct {
+	conn *sql.DB
+}
+
+func NewAnalyticsModel() *AnalyticsModel {
+	conn, err := config.DBConnection()
+	if err != nil {
+		panic(err)
+	}
+	return &AnalyticsModel{conn: conn}
+}
+
+// Dashboard menghitung semua statistik untuk rentang tanggal [from, to] (inklusif per hari)
+func (p *AnalyticsModel) Dashboard(from, to time.Time, topN int) (*entities.Dashboard, error) {
+	start := from.Format("2006-01-02 15:04:05")
+	end := to.AddDate(0, 0, 1).Format("2006-01-02 15:04:05")
+
+	dashboard := &entities.Dashboard{
+		From: from.Format("2006-01-02"),
+		To:   to.Format("2006-01-02"),
+	}
+
+	var err error
+	if dashboard.ContentByStatus, err = p.contentByStatus(start, end); err != nil {
+		return nil, err
+	}
+	if dashboard.TopViewed, err = p.topViewed(from, to, topN); err != nil {
+		return nil, err
+	}
+	if dashboard.ApprovalTurnaround, err = p.approvalTurnaround(start, end); err != nil {
+		return nil, err
+	}
+	if dashboard.TopContributors, err = p.topContributors(start, end, topN); err != nil {
+		return nil, err
+	}
+	if dashboard.CreatedPerMonth, err = p.createdPerMonth(from, to, start, end); err != nil {
+		return nil, err
+	}
+	return dashboard, nil
+}
+
+func (p *AnalyticsModel) contentByStatus(start, end string) ([]entities.StatusInstanceCount, error) {
+	query := `
+        SELECT c.status, c.instance_id, COALESCE(i.name, ''), COUNT(*)
+        FROM content c
+        LEFT JOIN instance i ON i.id = c.instance_id
+        WHERE c.deleted_at IS NULL AND c.created_at >= ? AND c.created_at < ?
+        GROUP BY c.status, c.instance_id, i.name
+        ORDER BY c.status, c.instance_id`
+
+	rows, err := p.conn.Query(query, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count content by status: %w", err)
+	}
+	defer rows.Close()
+
+	counts := []entities.StatusInstanceCount{}
+	for rows.Next() {
+		var count entities.StatusInstanceCount
+		if err := rows.Scan(&count.Status, &count.InstanceID, &count.InstanceName, &count.Total); err != nil {
+			return nil, fmt.Errorf("failed to scan content count: %w", err)
+		}
+		counts = append(counts, count)
+	}
+	return counts, rows.Err()
+}
+
+func (p *AnalyticsModel) topViewed(from, to time.Time, limit int) ([]entities.TopContent, error) {
+	query := `
+        SELECT c.id, c.title, COALESCE(i.name, ''), SUM(v.views) AS total_views
+        FROM content_view_daily v
+        JOIN content c ON c.id = v.content_id
+        LEFT JOIN instance i ON i.id = c.instance_id
+        WHERE v.view_date BETWEEN ? AND ? AND c.deleted_at IS NULL
+        GROUP BY c.id, c.title, i.name
+        ORDER BY total_views DESC
+        LIMIT ?`
+
+	rows, err := p.conn.Query(query, from.Format("2006-01-02"), to.Format("2006-01-02"), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch top viewed content: %w", err)
+	}
+	defer rows.Close()
+
+	contents := []entities.TopContent{}
+	for rows.Next() {
+		var content entities.TopContent
+		if err := rows.Scan(&content.ContentID, &content.Title, &content.InstanceName, &content.Views); err != nil {
+			return nil, fmt.Errorf("failed to scan top content: %w", err)
+		}
+		contents = append(contents, content)
+	}
+	return contents, rows.Err()
+}
+
+// approvalTurnaround mengukur jarak antara created_at konten dan aksi 'Approving' pertama di riwayat
+func (p *AnalyticsModel) approvalTurnaround(start, end string) (entities.ApprovalTurnaround, error) {
+	query := `
+        SELECT TIMESTAMPDIFF(SECOND, c.created_at, MIN(h.edited_at))
+        FROM content c
+        JOIN content_edit_history h ON h.content_id = c.id AND h.action = 'Approving'
+        GROUP BY c.id, c.created_at
+        HAVING MIN(h.edited_at) >= ? AND MIN(h.edited_at) < ?`
+
+	var summary entities.ApprovalTurnaround
+	rows, err := p.conn.Query(query, start, end)
+	if err != nil {
+		return summary, fmt.Errorf("failed to fetch approval turnaround: %w", err)
+	}
+	defer rows.Close()
+
+	var hours []float64
+	for rows.Next() {
+		var seconds sql.NullInt64
+		if err := rows.Scan(&seconds); err != nil {
+			return summary, fmt.Errorf("failed to scan approval turnaround: %w", err)
+		}
+		if seconds.Valid && seconds.Int64 >= 0 {
+			hours = append(hours, float64(seconds.Int64)/3600)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return summary, err
+	}
+	if len(hours) == 0 {
+		return summary, nil
+	}
+
+	sort.Float64s(hours)
+	total := 0.0
+	for _, h := range hours {
+		total += h
+	}
+
+	summary.Approved = len(hours)
+	summary.AverageHour = roundHours(total / float64(len(hours)))
+	summary.MedianHour = roundHours(percentile(hours, 0.5))
+	summary.P90Hour = roundHours(percentile(hours, 0.9))
+	summary.MaxHour = roundHours(hours[len(hours)-1])
+	return summary, nil
+}
+
+func (p *AnalyticsModel) topContributors(start, end string, limit int) ([]entities.Contributor, error) {
+	query := `
+        SELECT h.editor_id, u.name, COUNT(*),
+               SUM(h.action = 'Creating'), SUM(h.action = 'Editing')
+        FROM content_edit_history h
+        JOIN user u ON u.id = h.editor_id
+        WHERE h.edited_at >= ? AND h.edited_at < ?
+        GROUP BY h.editor_id, u.name
+        ORDER BY COUNT(*) DESC
+        LIMIT ?`
+
+	rows, err := p.conn.Query(query, start, end, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch contributors: %w", err)
+	}
+	defer rows.Close()
+
+	contributors := []entities.Contributor{}
+	for rows.Next() {
+		var contributor entities.Contributor
+		if err := rows.Scan(&contributor.UserID, &contributor.Name, &contributor.Actions,
+			&contributor.Created, &contributor.Edited); err != nil {
+			return nil, fmt.Errorf("failed to scan contributor: %w", err)
+		}
+		contributors = append(contributors, contributor)
+	}
+	return contributors, rows.Err()
+}
+
+func (p *AnalyticsModel) createdPerMonth(from, to time.Time, start, end string) ([]entities.MonthlyCount, error) {
+	query := `
+        SELECT DATE_FORMAT(created_at, '%Y-%m') AS month, COUNT(*)
+        FROM content
+        WHERE deleted_at IS NULL AND created_at >= ? AND created_at < ?
+        GROUP BY month`
+
+	rows, err := p.conn.Query(query, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count content per month: %w", err)
+	}
+	defer rows.Close()
+
+	totals := map[string]int{}
+	for rows.Next() {
+		var month string
+		var total int
+		if err := rows.Scan(&month, &total); err != nil {
+			return nil, fmt.Errorf("failed to scan monthly count: %w", err)
+		}
+		totals[month] = total
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Isi bulan tanpa konten dengan 0
+	months := []entities.MonthlyCount{}
+	last := time.Date(to.Year(), to.Month(), 1, 0, 0, 0, 0, to.Location())
+	for month := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, from.Location()); !month.After(last); month = month.AddDate(0, 1, 0) {
+		key := month.Format("2006-01")
+		months = append(months, entities.MonthlyCount{Month: key, Total: totals[key]})
+	}
+	return months, nil
+}
+
+// percentile mengambil nilai persentil dari data yang sudah terurut (nearest-rank)
+func percentile(sorted []float64, q float64) float64 {
+	index := int(math.Ceil(q*float64(len(sorted)))) - 1
+	if index < 0 {
+		index = 0
+	}
+	return sorted[index]
+}
+
+func roundHours(hours float64) float64 {
+	return math.Round(hours*10) / 10
+}
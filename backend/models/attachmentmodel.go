@@ -0,0 +1,156 @@
+package models
+
+import (
+	"backend/config"
+	"backend/entities"
+	"backend/helpers"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+type AttachmentModel struct {
+	conn *sql.DB
+}
+
+func NewAttachmentModel() *AttachmentModel {
+	conn, err := config.DBConnection()
+	if err != nil {
+		panic(err)
+	}
+	return &AttachmentModel{conn: conn}
+}
+
+func (p *AttachmentModel) CreateAttachment(attachment entities.Attachment) (int64, error) {
+	query := `
+        INSERT INTO content_attachments (
+            content_id, file_name, mime_type, size, storage_path,
+            extracted_text, uploaded_by, created_at
+        )
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	result, err := p.conn.Exec(
+		query,
+		attachment.ContentID,
+		attachment.FileName,
+		attachment.MimeType,
+		attachment.Size,
+		attachment.StoragePath,
+		attachment.ExtractedText,
+		attachment.UploadedBy,
+		attachment.Created_at,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to save attachment: %w", err)
+	}
+
+	return result.LastInsertId()
+}
+
+func (p *AttachmentModel) FindByContentID(contentID int64) ([]entities.Attachment, error) {
+	query := `
+        SELECT id, content_id, file_name, mime_type, size, storage_path, uploaded_by, created_at
+        FROM content_attachments
+        WHERE content_id = ?
+        ORDER BY created_at DESC`
+
+	rows, err := p.conn.Query(query, contentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch attachments: %w", err)
+	}
+	defer rows.Close()
+
+	attachments := []entities.Attachment{}
+	for rows.Next() {
+		var attachment entities.Attachment
+		if err := rows.Scan(&attachment.Id, &attachment.ContentID, &attachment.FileName, &attachment.MimeType,
+			&attachment.Size, &attachment.StoragePath, &attachment.UploadedBy, &attachment.Created_at); err != nil {
+			return nil, fmt.Errorf("failed to scan attachment: %w", err)
+		}
+		attachments = append(attachments, attachment)
+	}
+
+	return attachments, rows.Err()
+}
+
+func (p *AttachmentModel) FindByID(id int64) (*entities.Attachment, error) {
+	query := `
+        SELECT id, content_id, file_name, mime_type, size, storage_path, uploaded_by, created_at
+        FROM content_attachments
+        WHERE id = ?`
+
+	var attachment entities.Attachment
+	err := p.conn.QueryRow(query, id).Scan(&attachment.Id, &attachment.ContentID, &attachment.FileName,
+		&attachment.MimeType, &attachment.Size, &attachment.StoragePath, &attachment.UploadedBy, &attachment.Created_at)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch attachment: %w", err)
+	}
+	return &attachment, nil
+}
+
+func (p *AttachmentModel) DeleteByID(id int64) error {
+	_, err := p.conn.Exec("DELETE FROM content_attachments WHERE id = ?", id)
+	return err
+}
+
+// Search mencari dokumen lampiran yang teksnya mengandung kata kunci,
+// lalu menyertakan potongan teks (snippet) di sekitar kata yang cocok
+func (p *AttachmentModel) Search(searchTerm string) ([]entities.AttachmentMatch, error) {
+	booleanQuery := FulltextBooleanQuery(searchTerm)
+	if booleanQuery == "" {
+		return []entities.AttachmentMatch{}, nil
+	}
+
+	query := `
+        SELECT a.id, a.content_id, a.file_name, a.extracted_text
+        FROM content_attachments a
+        JOIN content c ON c.id = a.content_id
+        WHERE MATCH(a.extracted_text) AGAINST (? IN BOOLEAN MODE)
+          AND c.status = 'approved'`
+
+	rows, err := p.conn.Query(query, booleanQuery)
+	if err != nil {
+		return nil, fmt.Errorf("error executing attachment search query: %v", err)
+	}
+	defer rows.Close()
+
+	terms := strings.Fields(searchTerm)
+	matches := []entities.AttachmentMatch{}
+	for rows.Next() {
+		var match entities.AttachmentMatch
+		var text sql.NullString
+		if err := rows.Scan(&match.AttachmentID, &match.ContentID, &match.FileName, &text); err != nil {
+			return nil, fmt.Errorf("error scanning attachment result: %v", err)
+		}
+		match.Snippet = helpers.Snippet(text.String, terms, 80)
+		matches = append(matches, match)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %v", err)
+	}
+
+	return matches, nil
+}
+
+// FulltextBooleanQuery mengubah input pengguna menjadi query BOOLEAN MODE MySQL
+// di mana setiap kata wajib ada dan boleh berupa awalan kata (prefix)
+func FulltextBooleanQuery(searchTerm string) string {
+	var terms []string
+	for _, word := range strings.Fields(searchTerm) {
+		// Buang operator boolean agar input pengguna tidak mengubah arti query
+		word = strings.Map(func(r rune) rune {
+			if strings.ContainsRune(`+-<>()~*"@`, r) {
+				return -1
+			}
+			return r
+		}, word)
+		if word != "" {
+			terms = append(terms, "+"+word+"*")
+		}
+	}
+	return strings.Join(terms, " ")
+}
@@ -0,0 +1,126 @@
+package models
+
+import (
+	"backend/config"
+	"backend/entities"
+	"backend/helpers"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrExternalUserNotAllowed dikembalikan jika akun eksternal valid tetapi tidak
+// punya grup yang dipetakan ke role dan instansi, atau user-nya sudah dihapus
+var ErrExternalUserNotAllowed = errors.New("external user is not allowed to access the wiki")
+
+// ErrExternalLinkNotAllowed dikembalikan jika email akun eksternal sudah dipakai user
+// wiki yang belum diizinkan untuk ditautkan. Termasuk ErrExternalUserNotAllowed.
+var ErrExternalLinkNotAllowed = fmt.Errorf("%w: an existing account uses the same email", ErrExternalUserNotAllowed)
+
+// AuthProvider memeriksa login (email atau NIP) dan password terhadap satu sumber
+// akun. Login yang tidak cocok menghasilkan ErrInvalidCredentials sehingga provider
+// berikutnya bisa dicoba; error lain berarti sumber akun sedang bermasalah.
+type AuthProvider interface {
+	Name() string
+	Authenticate(login, password string) (*entities.UserDetail, error)
+}
+
+// NewAuthProviders menyusun provider sesuai konfigurasi: password lokal selalu
+// dicoba lebih dulu, lalu LDAP jika diaktifkan
+func NewAuthProviders(users *UserModel) []AuthProvider {
+	providers := []AuthProvider{LocalAuthProvider{users: users}}
+	if ldapConfig := config.Get().LDAP; ldapConfig.Enabled() {
+		providers = append(providers, LDAPAuthProvider{
+			directory: helpers.LDAPDirectory{Config: ldapConfig},
+			users:     users,
+			config:    ldapConfig,
+		})
+	}
+	return providers
+}
+
+// LocalAuthProvider memeriksa password yang tersimpan di tabel user
+type LocalAuthProvider struct {
+	users *UserModel
+}
+
+func (LocalAuthProvider) Name() string { return "local" }
+
+func (p LocalAuthProvider) Authenticate(login, password string) (*entities.UserDetail, error) {
+	return p.users.Authenticate(login, password)
+}
+
+// LDAPAuthProvider memeriksa password ke direktori LDAP lalu membuat atau
+// memperbarui user wiki sesuai data dan grup di direktori
+type LDAPAuthProvider struct {
+	directory helpers.LDAPDirectory
+	users     *UserModel
+	config    config.LDAPConfig
+}
+
+func (LDAPAuthProvider) Name() string { return "ldap" }
+
+func (p LDAPAuthProvider) Authenticate(login, password string) (*entities.UserDetail, error) {
+	external, err := p.directory.Authenticate(login, password)
+	if errors.Is(err, helpers.ErrDirectoryInvalidCredentials) {
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return p.users.ProvisionMappedUser(*external, p.config.RoleMappings, p.config.DefaultRoleID, p.config.DefaultInstanceID,
+		p.config.LinkExistingAccounts)
+}
+
+// ProvisionMappedUser menentukan role dan instansi user eksternal dari grupnya
+// (atau nilai default) lalu membuat atau memperbarui user wiki-nya
+func (p *UserModel) ProvisionMappedUser(external entities.ExternalUser, mappings []config.RoleMapping, defaultRoleID, defaultInstanceID int64, linkExisting bool) (*entities.UserDetail, error) {
+	roleID, instanceID := ResolveRoleMapping(mappings, external.Groups)
+	if roleID == 0 {
+		roleID = defaultRoleID
+	}
+	if instanceID == 0 {
+		instanceID = defaultInstanceID
+	}
+	return p.ProvisionExternalUser(external, roleID, instanceID, linkExisting)
+}
+
+// ResolveRoleMapping mencari role dan instansi dari grup user. Aturan dicek
+// berurutan; role diambil dari aturan pertama yang cocok dan punya role_id, begitu
+// juga instansi. Grup dicocokkan dengan DN lengkap atau CN-nya, tanpa beda huruf besar.
+func ResolveRoleMapping(mappings []config.RoleMapping, groups []string) (roleID int64, instanceID int64) {
+	for _, mapping := range mappings {
+		if !memberOf(groups, mapping.Group) {
+			continue
+		}
+		if roleID == 0 {
+			roleID = mapping.RoleID
+		}
+		if instanceID == 0 {
+			instanceID = mapping.InstanceID
+		}
+	}
+	return roleID, instanceID
+}
+
+func memberOf(groups []string, want string) bool {
+	for _, group := range groups {
+		if strings.EqualFold(group, want) {
+			return true
+		}
+		// "CN=Editor Wiki,OU=Groups,DC=jogjaprov,DC=go,DC=id" cocok dengan "Editor Wiki"
+		first, _, _ := strings.Cut(group, ",")
+		if name, ok := cutPrefixFold(strings.TrimSpace(first), "cn="); ok && strings.EqualFold(name, want) {
+			return true
+		}
+	}
+	return false
+}
+
+func cutPrefixFold(value, prefix string) (string, bool) {
+	if len(value) < len(prefix) || !strings.EqualFold(value[:len(prefix)], prefix) {
+		return value, false
+	}
+	return value[len(prefix):], true
+}
@@ -0,0 +1,251 @@
+package models
+
+import (
+	"backend/config"
+	"backend/entities"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+var testRoleMappings = []config.RoleMapping{
+	{Group: "CN=Diskominfo,OU=Instansi,DC=jogjaprov,DC=go,DC=id", InstanceID: 3},
+	{Group: "Editor Wiki", RoleID: 2},
+	{Group: "Admin Wiki", RoleID: 1, InstanceID: 1},
+}
+
+var testLDAPUser = entities.ExternalUser{
+	Provider: "ldap",
+	Subject:  "uid=budi,ou=people,dc=jogjaprov,dc=go,dc=id",
+	Email:    "budi.santoso@jogjaprov.go.id",
+	Name:     "Budi Santoso",
+	NIP:      198703122010011002,
+	Groups:   []string{"CN=Editor Wiki,OU=Groups,DC=jogjaprov,DC=go,DC=id", "CN=Diskominfo,OU=Instansi,DC=jogjaprov,DC=go,DC=id"},
+}
+
+func TestResolveRoleMapping(t *testing.T) {
+	tests := []struct {
+		name           string
+		groups         []string
+		roleID, instID int64
+	}{
+		{"no groups", nil, 0, 0},
+		{"full DN and CN", testLDAPUser.Groups, 2, 3},
+		{"CN only, case insensitive", []string{"editor wiki"}, 2, 0},
+		{"full DN in the mapping", []string{"cn=diskominfo,ou=instansi,dc=jogjaprov,dc=go,dc=id"}, 0, 3},
+		{"full DN in the mapping needs the full DN", []string{"Diskominfo"}, 0, 0},
+		{"first matching rule wins", []string{"Admin Wiki", "Editor Wiki"}, 2, 1},
+		{"earlier rule keeps its part", []string{"CN=Admin Wiki,OU=Groups", testLDAPUser.Groups[1]}, 1, 3},
+		{"other OU attribute is not a CN", []string{"OU=Diskominfo,DC=jogjaprov"}, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			roleID, instanceID := ResolveRoleMapping(testRoleMappings, tt.groups)
+			if roleID != tt.roleID || instanceID != tt.instID {
+				t.Errorf("got role %d instance %d, want role %d instance %d", roleID, instanceID, tt.roleID, tt.instID)
+			}
+		})
+	}
+}
+
+func newProvisionDB(t *testing.T) (sqlmock.Sqlmock, *UserModel) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+		db.Close()
+	})
+	return mock, &UserModel{conn: db}
+}
+
+func identityRows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id", "deleted", "auth_provider", "external_id"})
+}
+
+func emailRows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id", "deleted", "auth_provider", "external_id", "external_link_allowed"})
+}
+
+func detailRows(id, roleID, instanceID int64, provider string) *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id", "name", "nip", "email", "role_id", "instance_id",
+		"token_version", "auth_provider", "role", "instance"}).
+		AddRow(id, "Budi Santoso", 198703122010011002, "budi.santoso@jogjaprov.go.id", roleID, instanceID, 0, provider, "Role", "Instansi")
+}
+
+func TestProvisionMappedUserCreatesNewUser(t *testing.T) {
+	mock, users := newProvisionDB(t)
+	mock.ExpectBegin()
+	mock.ExpectQuery("FROM user_identities").WithArgs("ldap", testLDAPUser.Subject).WillReturnRows(identityRows())
+	mock.ExpectQuery("FROM user WHERE email").WithArgs(testLDAPUser.Email).WillReturnRows(emailRows())
+	mock.ExpectExec("INSERT INTO user ").
+		WithArgs("Budi Santoso", testLDAPUser.NIP, testLDAPUser.Email, 2, 3, "ldap", testLDAPUser.Subject).
+		WillReturnResult(sqlmock.NewResult(41, 1))
+	mock.ExpectExec("INSERT INTO user_identities").WithArgs(41, "ldap", testLDAPUser.Subject, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("WHERE u.id = ?").WithArgs(41).WillReturnRows(detailRows(41, 2, 3, "ldap"))
+	mock.ExpectCommit()
+
+	user, err := users.ProvisionMappedUser(testLDAPUser, testRoleMappings, 0, 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Id != 41 || user.Role_Id != 2 || user.Instance_Id != 3 {
+		t.Errorf("user = %+v", user)
+	}
+}
+
+func TestProvisionMappedUserWithoutMappingIsNotAllowed(t *testing.T) {
+	_, users := newProvisionDB(t)
+
+	outsider := testLDAPUser
+	outsider.Groups = []string{"CN=Tamu,OU=Groups,DC=jogjaprov,DC=go,DC=id"}
+	_, err := users.ProvisionMappedUser(outsider, testRoleMappings, 0, 3, false)
+	if !errors.Is(err, ErrExternalUserNotAllowed) {
+		t.Fatalf("err = %v, want ErrExternalUserNotAllowed", err)
+	}
+}
+
+func TestProvisionMappedUserUsesDefaults(t *testing.T) {
+	mock, users := newProvisionDB(t)
+	mock.ExpectBegin()
+	mock.ExpectQuery("FROM user_identities").WillReturnRows(identityRows())
+	mock.ExpectQuery("FROM user WHERE email").WillReturnRows(emailRows())
+	mock.ExpectExec("INSERT INTO user ").
+		WithArgs("Budi Santoso", testLDAPUser.NIP, testLDAPUser.Email, 4, 5, "ldap", testLDAPUser.Subject).
+		WillReturnResult(sqlmock.NewResult(42, 1))
+	mock.ExpectExec("INSERT INTO user_identities").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("WHERE u.id = ?").WillReturnRows(detailRows(42, 4, 5, "ldap"))
+	mock.ExpectCommit()
+
+	outsider := testLDAPUser
+	outsider.Groups = nil
+	if _, err := users.ProvisionMappedUser(outsider, testRoleMappings, 4, 5, false); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProvisionMappedUserRefusesToTakeOverLocalAccount(t *testing.T) {
+	mock, users := newProvisionDB(t)
+	mock.ExpectBegin()
+	mock.ExpectQuery("FROM user_identities").WillReturnRows(identityRows())
+	mock.ExpectQuery("FROM user WHERE email").WithArgs(testLDAPUser.Email).
+		WillReturnRows(emailRows().AddRow(7, false, "local", "", false))
+	mock.ExpectRollback()
+
+	_, err := users.ProvisionMappedUser(testLDAPUser, testRoleMappings, 0, 0, false)
+	if !errors.Is(err, ErrExternalLinkNotAllowed) {
+		t.Fatalf("err = %v, want ErrExternalLinkNotAllowed", err)
+	}
+	// Login tetap ditolak sebagai akun eksternal yang tidak diizinkan
+	if !errors.Is(err, ErrExternalUserNotAllowed) {
+		t.Errorf("err = %v does not wrap ErrExternalUserNotAllowed", err)
+	}
+}
+
+func TestProvisionMappedUserLinksAllowedAccountWithoutChangingRole(t *testing.T) {
+	mock, users := newProvisionDB(t)
+	mock.ExpectBegin()
+	mock.ExpectQuery("FROM user_identities").WillReturnRows(identityRows())
+	mock.ExpectQuery("FROM user WHERE email").WillReturnRows(emailRows().AddRow(7, false, "local", "", true))
+	mock.ExpectExec("INSERT INTO user_identities").WithArgs(7, "ldap", testLDAPUser.Subject, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	// Tidak ada UPDATE user: role admin lokal tetap
+	mock.ExpectQuery("WHERE u.id = ?").WithArgs(7).WillReturnRows(detailRows(7, 1, 1, "local"))
+	mock.ExpectCommit()
+
+	user, err := users.ProvisionMappedUser(testLDAPUser, testRoleMappings, 0, 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Role_Id != 1 || user.AuthProvider != "local" {
+		t.Errorf("user = %+v, want the local admin unchanged", user)
+	}
+}
+
+func TestProvisionMappedUserLinksWhenConfigAllows(t *testing.T) {
+	mock, users := newProvisionDB(t)
+	mock.ExpectBegin()
+	mock.ExpectQuery("FROM user_identities").WillReturnRows(identityRows())
+	mock.ExpectQuery("FROM user WHERE email").WillReturnRows(emailRows().AddRow(7, false, "local", "", false))
+	mock.ExpectExec("INSERT INTO user_identities").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("WHERE u.id = ?").WillReturnRows(detailRows(7, 1, 1, "local"))
+	mock.ExpectCommit()
+
+	if _, err := users.ProvisionMappedUser(testLDAPUser, testRoleMappings, 0, 0, true); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProvisionMappedUserSyncsAccountItCreated(t *testing.T) {
+	mock, users := newProvisionDB(t)
+	mock.ExpectBegin()
+	mock.ExpectQuery("FROM user_identities").WithArgs("ldap", testLDAPUser.Subject).
+		WillReturnRows(identityRows().AddRow(41, false, "ldap", testLDAPUser.Subject))
+	mock.ExpectExec("UPDATE user").
+		WithArgs(2, 3, "Budi Santoso", testLDAPUser.NIP, testLDAPUser.NIP, testLDAPUser.Email, 2, 3, 41).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("WHERE u.id = ?").WillReturnRows(detailRows(41, 2, 3, "ldap"))
+	mock.ExpectCommit()
+
+	if _, err := users.ProvisionMappedUser(testLDAPUser, testRoleMappings, 0, 0, false); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProvisionMappedUserDoesNotReviveDeletedUser(t *testing.T) {
+	mock, users := newProvisionDB(t)
+	mock.ExpectBegin()
+	mock.ExpectQuery("FROM user_identities").
+		WillReturnRows(identityRows().AddRow(41, true, "ldap", testLDAPUser.Subject))
+	mock.ExpectRollback()
+
+	_, err := users.ProvisionMappedUser(testLDAPUser, testRoleMappings, 0, 0, false)
+	if !errors.Is(err, ErrExternalUserNotAllowed) || errors.Is(err, ErrExternalLinkNotAllowed) {
+		t.Fatalf("err = %v, want ErrExternalUserNotAllowed", err)
+	}
+}
+
+func TestProvisionMappedUserDoesNotFlipRoleBetweenProviders(t *testing.T) {
+	mock, users := newProvisionDB(t)
+	oidcUser := entities.ExternalUser{
+		Provider: "oidc",
+		Subject:  "3f0c9a52-budi",
+		Email:    testLDAPUser.Email,
+		Name:     "Budi S.",
+		Groups:   []string{"wiki-viewer"},
+	}
+	mappings := []config.RoleMapping{{Group: "wiki-viewer", RoleID: 4, InstanceID: 9}}
+
+	// SSO sudah ditautkan ke akun yang dibuat oleh LDAP: tidak ada UPDATE user,
+	// role editor dari LDAP tidak diganti role viewer dari SSO
+	mock.ExpectBegin()
+	mock.ExpectQuery("FROM user_identities").WithArgs("oidc", oidcUser.Subject).
+		WillReturnRows(identityRows().AddRow(41, false, "ldap", testLDAPUser.Subject))
+	mock.ExpectQuery("WHERE u.id = ?").WithArgs(41).WillReturnRows(detailRows(41, 2, 3, "ldap"))
+	mock.ExpectCommit()
+
+	user, err := users.ProvisionMappedUser(oidcUser, mappings, 0, 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Role_Id != 2 || user.Instance_Id != 3 {
+		t.Errorf("user = %+v, want the LDAP role kept", user)
+	}
+
+	// SSO yang belum ditautkan tidak mengambil alih akun LDAP dengan email yang sama
+	mock.ExpectBegin()
+	mock.ExpectQuery("FROM user_identities").WillReturnRows(identityRows())
+	mock.ExpectQuery("FROM user WHERE email").
+		WillReturnRows(emailRows().AddRow(41, false, "ldap", testLDAPUser.Subject, false))
+	mock.ExpectRollback()
+
+	if _, err := users.ProvisionMappedUser(oidcUser, mappings, 0, 0, false); !errors.Is(err, ErrExternalLinkNotAllowed) {
+		t.Fatalf("err = %v, want ErrExternalLinkNotAllowed", err)
+	}
+}
@@ -0,0 +1,164 @@
+package models
+
+import (
+	"backend/entities"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Batas cache baca konten; detail dan subjudul jarang berubah, list lebih sering
+const (
+	contentCacheCapacity = 2000
+	contentDetailTTL     = 5 * time.Minute
+	contentListTTL       = time.Minute
+)
+
+// Jenis perubahan konten yang dikirim ke subscriber
+const (
+	ContentCreated       = "content_created"
+	ContentUpdated       = "content_updated"
+	ContentStatusChanged = "content_status_changed"
+	ContentDeleted       = "content_deleted"
+	SubheadingChanged    = "subheading_changed"
+)
+
+// ContentEvent dikirim setiap kali model menulis ke tabel content atau subheadings
+type ContentEvent struct {
+	Kind      string
+	ContentID int64
+}
+
+var (
+	contentSubscribersMu sync.RWMutex
+	contentSubscribers   []func(ContentEvent)
+)
+
+// SubscribeContentEvents mendaftarkan fungsi yang dipanggil setelah konten berubah
+func SubscribeContentEvents(subscriber func(ContentEvent)) {
+	contentSubscribersMu.Lock()
+	defer contentSubscribersMu.Unlock()
+	contentSubscribers = append(contentSubscribers, subscriber)
+}
+
+func publishContentEvent(kind string, contentID int64) {
+	contentSubscribersMu.RLock()
+	subscribers := contentSubscribers
+	contentSubscribersMu.RUnlock()
+
+	event := ContentEvent{Kind: kind, ContentID: contentID}
+	for _, subscriber := range subscribers {
+		subscriber(event)
+	}
+}
+
+// contentCache dipakai bersama oleh semua instance ContentModel dan SubheadingModel
+var contentCache = newReadCache("content", contentCacheCapacity)
+
+func init() {
+	SubscribeContentEvents(invalidateContentCache)
+}
+
+// invalidateContentCache hanya membuang entri yang terdampak perubahan:
+// subjudul tidak muncul di list, sehingga list hanya dibuang saat kontennya berubah
+func invalidateContentCache(event ContentEvent) {
+	switch event.Kind {
+	case SubheadingChanged:
+		contentCache.invalidate(subheadingsCacheKey(event.ContentID))
+	case ContentCreated:
+		contentCache.invalidatePrefix("list:")
+	default:
+		contentCache.invalidate(contentCacheKey(event.ContentID))
+		contentCache.invalidatePrefix("list:")
+	}
+}
+
+// ContentCacheStats mengembalikan metrik hit/miss cache konten
+func ContentCacheStats() CacheStats {
+	return contentCache.stats()
+}
+
+func contentCacheKey(contentID int64) string {
+	return fmt.Sprintf("content:%d", contentID)
+}
+
+func subheadingsCacheKey(contentID int64) string {
+	return fmt.Sprintf("subheadings:%d", contentID)
+}
+
+// listCacheKey menyusun key yang stabil dari ListOptions (urutan filter diurutkan)
+func listCacheKey(name string, opts ListOptions, scope ...interface{}) string {
+	fields := make([]string, 0, len(opts.Filters))
+	for field, value := range opts.Filters {
+		fields = append(fields, field+"="+value)
+	}
+	sort.Strings(fields)
+
+	return fmt.Sprintf("list:%s:%v:%d:%d:%s:%t:%s", name, scope, opts.Limit, opts.Offset,
+		opts.SortBy, opts.Desc, strings.Join(fields, "&"))
+}
+
+type cachedContent struct {
+	content      entities.Content
+	authorName   string
+	instanceName string
+}
+
+type cachedContentList struct {
+	contents []entities.Content
+	total    int
+}
+
+// FindByIDWithAuthorName mengambil konten beserta nama penulis dan instansinya,
+// dari cache jika masih ada
+func (p *ContentModel) FindByIDWithAuthorName(id int64) (*entities.Content, string, string, error) {
+	key := contentCacheKey(id)
+	if value, ok := contentCache.get(key); ok {
+		cached := value.(cachedContent)
+		content := cached.content
+		return &content, cached.authorName, cached.instanceName, nil
+	}
+
+	generation := contentCache.currentGeneration()
+	content, authorName, instanceName, err := p.findByIDWithAuthorName(id)
+	if err != nil || content == nil {
+		return content, authorName, instanceName, err
+	}
+	contentCache.set(key, cachedContent{content: *content, authorName: authorName, instanceName: instanceName}, contentDetailTTL, generation)
+	return content, authorName, instanceName, nil
+}
+
+// FindNotDelete mengambil list konten aktif yang boleh dilihat pemanggil, dari cache jika masih ada
+func (p *ContentModel) FindNotDelete(instanceID int, roleID int64, opts ListOptions) ([]entities.Content, int, error) {
+	key := listCacheKey("active", opts, instanceID, roleID)
+	if value, ok := contentCache.get(key); ok {
+		cached := value.(cachedContentList)
+		return append([]entities.Content{}, cached.contents...), cached.total, nil
+	}
+
+	generation := contentCache.currentGeneration()
+	contents, total, err := p.findNotDelete(instanceID, roleID, opts)
+	if err != nil {
+		return contents, total, err
+	}
+	contentCache.set(key, cachedContentList{contents: append([]entities.Content{}, contents...), total: total}, contentListTTL, generation)
+	return contents, total, nil
+}
+
+// FindByContentID mengambil subjudul sebuah konten, dari cache jika masih ada
+func (p *SubheadingModel) FindByContentID(contentID int64) ([]entities.Subheading, error) {
+	key := subheadingsCacheKey(contentID)
+	if value, ok := contentCache.get(key); ok {
+		return append([]entities.Subheading(nil), value.([]entities.Subheading)...), nil
+	}
+
+	generation := contentCache.currentGeneration()
+	subheadings, err := p.findByContentID(contentID)
+	if err != nil {
+		return nil, err
+	}
+	contentCache.set(key, append([]entities.Subheading(nil), subheadings...), contentDetailTTL, generation)
+	return subheadings, nil
+}
@@ -5,6 +5,7 @@ import (
 	"backend/entities"
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -22,41 +23,89 @@ func NewContentModel() *ContentModel {
 	}
 }
 
-func (p *ContentModel) FindAll() ([]entities.Content, error) {
-    query := "SELECT id, title FROM content WHERE status = 'approved'"
-    rows, err := p.conn.Query(query)
+// contentListSpec adalah kolom sort dan filter yang berlaku untuk semua list konten
+var contentListSpec = ListSpec{
+    Sortable: map[string]string{
+        "id":         "c.id",
+        "title":      "c.title",
+        "created_at": "c.created_at",
+        "updated_at": "c.updated_at",
+        "view_count": "c.view_count",
+    },
+    Filterable: map[string]string{
+        "status":        "c.status",
+        "tag":           "c.tag",
+        "instance_id":   "c.instance_id",
+        "author_id":     "c.author_id",
+        "accessibility": "c.accessibility",
+    },
+    Searchable:  []string{"c.title", "c.tag"},
+    DefaultSort: "id",
+    DefaultDesc: true,
+}
+
+// ContentListSpec dipakai controller untuk memvalidasi parameter list konten
+func ContentListSpec() ListSpec {
+    return contentListSpec
+}
+
+// count menjalankan query COUNT(*) hasil buildListQuery
+func (p *ContentModel) count(query string, args []interface{}) (int, error) {
+    var total int
+    err := p.conn.QueryRow(query, args...).Scan(&total)
+    return total, err
+}
+
+func (p *ContentModel) FindAll(opts ListOptions) ([]entities.Content, int, error) {
+    query, countQuery, args, countArgs := buildListQuery(
+        "SELECT c.id, c.title FROM content c WHERE c.status = 'approved'",
+        nil, contentListSpec, opts)
+
+    total, err := p.count(countQuery, countArgs)
+    if err != nil {
+        return []entities.Content{}, 0, err
+    }
+
+    rows, err := p.conn.Query(query, args...)
     if err != nil {
-        return []entities.Content{}, err
+        return []entities.Content{}, 0, err
     }
     defer rows.Close()
 
-    var dataContent []entities.Content
+    dataContent := []entities.Content{}
     for rows.Next() {
         var content entities.Content
         err := rows.Scan(&content.Id, &content.Title)
         if err != nil {
-            return []entities.Content{}, err
+            return []entities.Content{}, 0, err
         }
         dataContent = append(dataContent, content)
     }
-    return dataContent, nil
+    return dataContent, total, nil
 }
 
-func (p *ContentModel) FindNotRejected() ([]entities.Content, error) {
-    query := `
+func (p *ContentModel) FindNotRejected(opts ListOptions) ([]entities.Content, int, error) {
+    baseQuery := `
         SELECT c.id, c.title, c.description, c.author_id, c.instance_id, 
                c.created_at, c.updated_at, c.tag, c.status, c.view_count, 
                c.rejection_reason, u.name as author_name
         FROM content c 
         LEFT JOIN user u ON c.author_id = u.id 
-    `
-    rows, err := p.conn.Query(query)
+        WHERE 1 = 1`
+    query, countQuery, args, countArgs := buildListQuery(baseQuery, nil, contentListSpec, opts)
+
+    total, err := p.count(countQuery, countArgs)
+    if err != nil {
+        return []entities.Content{}, 0, err
+    }
+
+    rows, err := p.conn.Query(query, args...)
     if err != nil {
-        return []entities.Content{}, err
+        return []entities.Content{}, 0, err
     }
     defer rows.Close()
 
-    var dataContent []entities.Content
+    dataContent := []entities.Content{}
     for rows.Next() {
         var content entities.Content
         err := rows.Scan(
@@ -66,111 +115,123 @@ func (p *ContentModel) FindNotRejected() ([]entities.Content, error) {
             &content.Author_name,
         )
         if err != nil {
-            return []entities.Content{}, err
+            return []entities.Content{}, 0, err
         }
         dataContent = append(dataContent, content)
     }
-    return dataContent, nil
+    return dataContent, total, nil
 }
 
-
-
-func (p *ContentModel) FindNotDelete(instanceID int, roleID int64) ([]entities.Content, error) {
-	var query string
-	var rows *sql.Rows
-	var err error
+func (p *ContentModel) findNotDelete(instanceID int, roleID int64, opts ListOptions) ([]entities.Content, int, error) {
+	var baseQuery string
+	var baseArgs []interface{}
 
 	if instanceID == 0 {
 		// Jika tidak memiliki instanceID, hanya bisa melihat 'public'
-		query = `
-			SELECT id, title 
-			FROM content 
-			WHERE status = 'approved' 
-			AND accessibility = 'public'
-			AND deleted_at IS NULL`
-		rows, err = p.conn.Query(query)
+		baseQuery = `
+			SELECT c.id, c.title 
+			FROM content c 
+			WHERE c.status = 'approved' 
+			AND c.accessibility = 'public'
+			AND c.deleted_at IS NULL`
 	} else {
 		if roleID == 5 {
 			// Jika role_id = 5, bisa melihat semua 'private_instance' tanpa instance_id
-			query = `
-				SELECT id, title 
-				FROM content 
-				WHERE status = 'approved' 
-				AND (accessibility = 'public' 
-					OR accessibility = 'all_instance'
-					OR accessibility = 'private_instance')
-                    AND deleted_at IS NULL`
-                    
-			rows, err = p.conn.Query(query)
+			baseQuery = `
+				SELECT c.id, c.title 
+				FROM content c 
+				WHERE c.status = 'approved' 
+				AND (c.accessibility = 'public' 
+					OR c.accessibility = 'all_instance'
+					OR c.accessibility = 'private_instance')
+                    AND c.deleted_at IS NULL`
 		} else {
 			// Role biasa, hanya bisa melihat 'private_instance' dari instance mereka sendiri
-			query = `
-				SELECT id, title 
-				FROM content 
-				WHERE status = 'approved' 
-				AND (accessibility = 'public' 
-					OR accessibility = 'all_instance'
-					OR (accessibility = 'private_instance' AND instance_id = ?))
-                    AND deleted_at IS NULL`
-			rows, err = p.conn.Query(query, instanceID)
+			baseQuery = `
+				SELECT c.id, c.title 
+				FROM content c 
+				WHERE c.status = 'approved' 
+				AND (c.accessibility = 'public' 
+					OR c.accessibility = 'all_instance'
+					OR (c.accessibility = 'private_instance' AND c.instance_id = ?))
+                    AND c.deleted_at IS NULL`
+			baseArgs = append(baseArgs, instanceID)
 		}
 	}
 
+	query, countQuery, args, countArgs := buildListQuery(baseQuery, baseArgs, contentListSpec, opts)
+
+	total, err := p.count(countQuery, countArgs)
 	if err != nil {
-		return []entities.Content{}, err
+		return []entities.Content{}, 0, err
+	}
+
+	rows, err := p.conn.Query(query, args...)
+	if err != nil {
+		return []entities.Content{}, 0, err
 	}
 	defer rows.Close()
 
-	var dataContent []entities.Content
+	dataContent := []entities.Content{}
 	for rows.Next() {
 		var content entities.Content
 		err := rows.Scan(&content.Id, &content.Title)
 		if err != nil {
-			return []entities.Content{}, err
+			return []entities.Content{}, 0, err
 		}
 		dataContent = append(dataContent, content)
 	}
-	return dataContent, nil
+	return dataContent, total, nil
 }
 
-
-
-func (p *ContentModel) FindDrafts() ([]entities.Content, error) {
-	query := `
+func (p *ContentModel) FindDrafts(opts ListOptions) ([]entities.Content, int, error) {
+	baseQuery := `
         SELECT c.id, c.description, c.title, c.author_id, u.name as author_name 
         FROM content c 
         LEFT JOIN user u ON c.author_id = u.id 
         WHERE c.status = 'pending'`
-	rows, err := p.conn.Query(query)
+	query, countQuery, args, countArgs := buildListQuery(baseQuery, nil, contentListSpec, opts)
+
+	total, err := p.count(countQuery, countArgs)
+	if err != nil {
+		return []entities.Content{}, 0, err
+	}
+
+	rows, err := p.conn.Query(query, args...)
 	if err != nil {
-		return []entities.Content{}, err
+		return []entities.Content{}, 0, err
 	}
 	defer rows.Close()
 
-	var dataContent []entities.Content
+	dataContent := []entities.Content{}
 	for rows.Next() {
 		var content entities.Content
-		var authorName string
+		var authorName sql.NullString
 		err := rows.Scan(&content.Id, &content.Description, &content.Title, &content.Author_id, &authorName)
 		if err != nil {
-			return []entities.Content{}, err
+			return []entities.Content{}, 0, err
 		}
-		content.Author_name = authorName // Add the author name to content
+		content.Author_name = authorName.String // Add the author name to content
 		dataContent = append(dataContent, content)
 	}
-	return dataContent, nil
+	return dataContent, total, nil
 }
 
 func (model *ContentModel) Search(searchTerm string) ([]entities.Content, error) {
 	var contents []entities.Content
 
+	// Konten juga ikut ditemukan jika salah satu dokumen lampirannya cocok
 	query := `SELECT id, title, description, author_id, instance_id, created_at, updated_at, tag, status 
               FROM content 
-              WHERE (title LIKE ? OR tag LIKE ?) AND status = 'approved'`
+              WHERE (title LIKE ? OR tag LIKE ? OR id IN (
+                  SELECT content_id FROM content_attachments
+                  WHERE MATCH(extracted_text) AGAINST (? IN BOOLEAN MODE)
+              )) AND status = 'approved'`
 
+	booleanQuery := FulltextBooleanQuery(searchTerm)
 	searchTerm = "%" + searchTerm + "%"
 
-	rows, err := model.conn.Query(query, searchTerm, searchTerm)
+	rows, err := model.conn.Query(query, searchTerm, searchTerm, booleanQuery)
 	if err != nil {
 		return nil, fmt.Errorf("error executing search query: %v", err)
 	}
@@ -198,7 +259,11 @@ func (p *ContentModel) UpdateByID(content entities.Content) error {
 		SET title = ?, description = ?, instance_id = ?, tag = ?, accessibility = ?, updated_at = ?
 		WHERE id = ?`
 	_, err := p.conn.Exec(query, content.Title, content.Description.String, content.Instance_id, content.Tag, content.Accessibility, content.Updated_at, content.Id)
-	return err
+	if err != nil {
+		return err
+	}
+	publishContentEvent(ContentUpdated, content.Id)
+	return nil
 }
 
 func (p *ContentModel) CreateContent(content entities.Content) (int64, error) {
@@ -229,6 +294,7 @@ func (p *ContentModel) CreateContent(content entities.Content) (int64, error) {
     if err != nil {
         return 0, err
     }
+    publishContentEvent(ContentCreated, contentID)
     return contentID, nil
 }
 
@@ -245,9 +311,9 @@ func (p *ContentModel) DeleteByID(contentID int64) error {
     // Debug: Log the WIB time
     fmt.Printf("Waktu WIB yang dikirim: %v\n", nowWIB)
 
-    // Prepare the query to update the deleted_at column
-    query := "UPDATE content SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL"
-    result, err := p.conn.Exec(query, nowWIB, contentID)
+    // updated_at ikut diubah supaya validator cache (ETag/Last-Modified) ikut berubah
+    query := "UPDATE content SET deleted_at = ?, updated_at = ? WHERE id = ? AND deleted_at IS NULL"
+    result, err := p.conn.Exec(query, nowWIB, nowWIB, contentID)
     if err != nil {
         return fmt.Errorf("error soft deleting content: %w", err)
     }
@@ -261,15 +327,16 @@ func (p *ContentModel) DeleteByID(contentID int64) error {
         return fmt.Errorf("no rows were updated, possibly contentID does not exist or already deleted")
     }
 
+    publishContentEvent(ContentDeleted, contentID)
     return nil
 }
 
 
-func (p *ContentModel) FindByIDWithAuthorName(id int64) (*entities.Content, string, string, error) {
+func (p *ContentModel) findByIDWithAuthorName(id int64) (*entities.Content, string, string, error) {
     query := `
         SELECT c.id, c.title, c.description, c.author_id, c.instance_id, 
-               c.created_at, c.updated_at, c.tag, u.name AS author_name, 
-               i.name AS instance_name
+               c.created_at, c.updated_at, c.tag, c.status, c.deleted_at,
+               u.name AS author_name, i.name AS instance_name
         FROM content c
         LEFT JOIN user u ON c.author_id = u.id
         LEFT JOIN instance i ON c.instance_id = i.id
@@ -277,44 +344,61 @@ func (p *ContentModel) FindByIDWithAuthorName(id int64) (*entities.Content, stri
 
     row := p.conn.QueryRow(query, id)
     var content entities.Content
+    var deletedAt sql.NullString
     var authorName, instanceName string
     err := row.Scan(&content.Id, &content.Title, &content.Description, &content.Author_id,
         &content.Instance_id, &content.Created_at, &content.Updated_at, &content.Tag,
-        &authorName, &instanceName)
+        &content.Status, &deletedAt, &authorName, &instanceName)
     if err != nil {
         if err == sql.ErrNoRows {
             return nil, "", "", nil
         }
         return nil, "", "", err
     }
+    // DATETIME dibaca sebagai string oleh driver MySQL
+    if deletedAt.Valid {
+        deletedTime, _ := time.Parse("2006-01-02 15:04:05", deletedAt.String)
+        content.Deleted_at = sql.NullTime{Time: deletedTime, Valid: true}
+    }
     return &content, authorName, instanceName, nil
 }
 
-func (s *ContentModel) GetContentsByAuthorId(authorId int64) ([]entities.Content, error) {
-	var contents []entities.Content
-	query := `SELECT id, title, created_at, updated_at, author_id, status, rejection_reason FROM content WHERE author_id = ?`
-	rows, err := s.conn.Query(query, authorId)
+func (s *ContentModel) GetContentsByAuthorId(authorId int64, opts ListOptions) ([]entities.Content, int, error) {
+	contents := []entities.Content{}
+	baseQuery := `SELECT c.id, c.title, c.created_at, c.updated_at, c.author_id, c.status, c.rejection_reason FROM content c WHERE c.author_id = ?`
+	query, countQuery, args, countArgs := buildListQuery(baseQuery, []interface{}{authorId}, contentListSpec, opts)
+
+	total, err := s.count(countQuery, countArgs)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := s.conn.Query(query, args...)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	defer rows.Close()
 
 	for rows.Next() {
 		var content entities.Content
 		if err := rows.Scan(&content.Id, &content.Title, &content.Created_at, &content.Updated_at, &content.Author_id, &content.Status, &content.Rejection_reason); err != nil {
-			return nil, err
+			return nil, 0, err
 		}
 		contents = append(contents, content)
 	}
-	return contents, nil
+	return contents, total, nil
 }
 
-
-
+// UpdateStatus mengubah status konten; updated_at ikut diperbarui supaya klien
+// yang memakai If-Modified-Since tidak menerima 304 untuk status lama
 func (m *ContentModel) UpdateStatus(contentID int, status string) error {
-	query := "UPDATE content SET status = ? WHERE id = ?"
-	_, err := m.conn.Exec(query, status, contentID)
-	return err
+	query := "UPDATE content SET status = ?, updated_at = ? WHERE id = ?"
+	_, err := m.conn.Exec(query, status, wibNow().Format(dateTimeLayout), contentID)
+	if err != nil {
+		return err
+	}
+	publishContentEvent(ContentStatusChanged, int64(contentID))
+	return nil
 }
 
 func (p *ContentModel) GetViewCountByContentID(contentID int) (int, error) {
@@ -327,22 +411,17 @@ func (p *ContentModel) GetViewCountByContentID(contentID int) (int, error) {
     return viewCount, nil
 }
 
-func (p *ContentModel) IncrementViewCount(contentID int) error {
-    query := "UPDATE content SET view_count = view_count + 1 WHERE id = ?"
-    _, err := p.conn.Exec(query, contentID)
-    return err
-}
-
 func (p *ContentModel) RejectContent(contentID int, reason string) error {
     query := `
         UPDATE content 
         SET status = 'rejected', 
             rejection_reason = ?, 
-            updated_at = CURRENT_TIMESTAMP 
+            updated_at = ? 
         WHERE id = ?
     `
     
-    result, err := p.conn.Exec(query, reason, contentID)
+    // Waktu WIB dari aplikasi, bukan CURRENT_TIMESTAMP yang mengikuti zona server DB
+    result, err := p.conn.Exec(query, reason, wibNow().Format(dateTimeLayout), contentID)
     if err != nil {
         return fmt.Errorf("failed to execute query: %v", err)
     }
@@ -356,6 +435,7 @@ func (p *ContentModel) RejectContent(contentID int, reason string) error {
         return fmt.Errorf("no content found with ID %d", contentID)
     }
 
+    publishContentEvent(ContentStatusChanged, int64(contentID))
     return nil
 }
 func (p *ContentModel) UpdateRejectByID(content entities.Content) error {
@@ -379,5 +459,245 @@ func (p *ContentModel) UpdateRejectByID(content entities.Content) error {
         return fmt.Errorf("no rows updated: content is not in 'rejected' status or does not exist")
     }
 
+    publishContentEvent(ContentUpdated, content.Id)
     return nil
 }
+
+// ContentVisibleTo menerapkan aturan accessibility yang sama dengan FindNotDelete
+// untuk konten yang sudah diambil ke memori (misalnya dari cache).
+func ContentVisibleTo(accessibility string, contentInstanceID int64, instanceID int, roleID int64) bool {
+    switch accessibility {
+    case "public":
+        return true
+    case "all_instance":
+        return instanceID != 0
+    case "private_instance":
+        return instanceID != 0 && (roleID == 5 || contentInstanceID == int64(instanceID))
+    }
+    return false
+}
+
+// RankTrending menghitung skor trending dari view unik harian sejak tanggal since.
+// Setiap view diberi bobot 0.5^(umur hari / halfLifeDays) sehingga view terbaru lebih berpengaruh.
+func (p *ContentModel) RankTrending(since time.Time, halfLifeDays float64, limit int) ([]entities.RankedContent, error) {
+    query := `
+        SELECT c.id, c.title, c.tag, c.instance_id, c.accessibility, c.view_count,
+               SUM(v.views * POW(0.5, DATEDIFF(CURDATE(), v.view_date) / ?)) AS score
+        FROM content_view_daily v
+        JOIN content c ON c.id = v.content_id
+        WHERE v.view_date >= ? AND c.status = 'approved' AND c.deleted_at IS NULL
+        GROUP BY c.id, c.title, c.tag, c.instance_id, c.accessibility, c.view_count
+        ORDER BY score DESC
+        LIMIT ?`
+    return p.rank(query, halfLifeDays, since.Format("2006-01-02"), limit)
+}
+
+// RankPopular mengurutkan konten berdasarkan total view sepanjang waktu
+func (p *ContentModel) RankPopular(limit int) ([]entities.RankedContent, error) {
+    query := `
+        SELECT id, title, tag, instance_id, accessibility, view_count, view_count AS score
+        FROM content
+        WHERE status = 'approved' AND deleted_at IS NULL AND view_count > 0
+        ORDER BY view_count DESC, id DESC
+        LIMIT ?`
+    return p.rank(query, limit)
+}
+
+func (p *ContentModel) rank(query string, args ...interface{}) ([]entities.RankedContent, error) {
+    rows, err := p.conn.Query(query, args...)
+    if err != nil {
+        return nil, fmt.Errorf("error ranking content: %v", err)
+    }
+    defer rows.Close()
+
+    ranked := []entities.RankedContent{}
+    for rows.Next() {
+        var content entities.RankedContent
+        if err := rows.Scan(&content.Id, &content.Title, &content.Tag, &content.InstanceID,
+            &content.Accessibility, &content.ViewCount, &content.Score); err != nil {
+            return nil, fmt.Errorf("error scanning ranked content: %v", err)
+        }
+        ranked = append(ranked, content)
+    }
+    return ranked, rows.Err()
+}
+
+// FindDocuments mengambil teks semua konten yang disetujui (judul, deskripsi, tag, serta
+// judul dan isi subjudul) untuk membangun indeks rekomendasi artikel terkait.
+// Teks subjudul digabung di Go karena GROUP_CONCAT terpotong di 1024 byte secara default.
+func (p *ContentModel) FindDocuments() ([]entities.ContentDocument, error) {
+    query := `
+        SELECT c.id, c.title, COALESCE(c.description, ''), c.tag, c.instance_id, c.accessibility
+        FROM content c
+        WHERE c.status = 'approved' AND c.deleted_at IS NULL`
+
+    rows, err := p.conn.Query(query)
+    if err != nil {
+        return nil, fmt.Errorf("error fetching content documents: %v", err)
+    }
+    defer rows.Close()
+
+    documents := []entities.ContentDocument{}
+    positions := map[int64]int{}
+    for rows.Next() {
+        var document entities.ContentDocument
+        if err := rows.Scan(&document.Id, &document.Title, &document.Description, &document.Tag,
+            &document.InstanceID, &document.Accessibility); err != nil {
+            return nil, fmt.Errorf("error scanning content document: %v", err)
+        }
+        positions[document.Id] = len(documents)
+        documents = append(documents, document)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, fmt.Errorf("error fetching content documents: %v", err)
+    }
+
+    subheadingQuery := `
+        SELECT s.content_id, s.subheading, COALESCE(s.subheading_description, '')
+        FROM subheadings s
+        JOIN content c ON c.id = s.content_id
+        WHERE c.status = 'approved' AND c.deleted_at IS NULL
+        ORDER BY s.content_id, s.id`
+
+    subheadingRows, err := p.conn.Query(subheadingQuery)
+    if err != nil {
+        return nil, fmt.Errorf("error fetching content subheadings: %v", err)
+    }
+    defer subheadingRows.Close()
+
+    texts := make([][]string, len(documents))
+    for subheadingRows.Next() {
+        var contentID int64
+        var subheading, description string
+        if err := subheadingRows.Scan(&contentID, &subheading, &description); err != nil {
+            return nil, fmt.Errorf("error scanning content subheading: %v", err)
+        }
+        // Konten yang disetujui setelah query pertama dilewati
+        position, ok := positions[contentID]
+        if !ok {
+            continue
+        }
+        texts[position] = append(texts[position], subheading, description)
+    }
+    if err := subheadingRows.Err(); err != nil {
+        return nil, fmt.Errorf("error fetching content subheadings: %v", err)
+    }
+
+    for i := range documents {
+        documents[i].Subheadings = strings.Join(texts[i], " ")
+    }
+    return documents, nil
+}
+
+// FindPublicFeed mengambil konten publik yang disetujui, terbaru lebih dulu.
+// instanceID 0 dan tag kosong berarti tanpa filter; limit 0 berarti semua konten.
+// PublishedAt adalah waktu persetujuan terakhir di riwayat, atau created_at bila tidak ada.
+func (p *ContentModel) FindPublicFeed(instanceID int64, tag string, limit int) ([]entities.FeedItem, error) {
+    query := `
+        SELECT c.id, c.title, COALESCE(c.description, ''), c.tag, COALESCE(i.name, ''),
+               COALESCE(u.name, ''), f.published_at, c.updated_at
+        FROM content c
+        JOIN (
+            SELECT c2.id, COALESCE(MAX(h.edited_at), c2.created_at) AS published_at
+            FROM content c2
+            LEFT JOIN content_edit_history h ON h.content_id = c2.id AND h.action = 'Approving'
+            GROUP BY c2.id, c2.created_at
+        ) f ON f.id = c.id
+        LEFT JOIN instance i ON i.id = c.instance_id
+        LEFT JOIN user u ON u.id = c.author_id
+        WHERE c.status = 'approved' AND c.accessibility = 'public' AND c.deleted_at IS NULL`
+    var args []interface{}
+
+    if instanceID != 0 {
+        query += " AND c.instance_id = ?"
+        args = append(args, instanceID)
+    }
+    if tag != "" {
+        // Tag disimpan dipisah koma, cocokkan satu tag utuh tanpa peduli spasi dan huruf besar
+        query += " AND CONCAT(',', LOWER(REPLACE(c.tag, ' ', '')), ',') LIKE ?"
+        args = append(args, "%,"+strings.ToLower(strings.ReplaceAll(tag, " ", ""))+",%")
+    }
+
+    query += " ORDER BY GREATEST(f.published_at, c.updated_at) DESC, c.id DESC"
+    if limit > 0 {
+        query += " LIMIT ?"
+        args = append(args, limit)
+    }
+
+    rows, err := p.conn.Query(query, args...)
+    if err != nil {
+        return nil, fmt.Errorf("error fetching public feed: %v", err)
+    }
+    defer rows.Close()
+
+    items := []entities.FeedItem{}
+    for rows.Next() {
+        var item entities.FeedItem
+        if err := rows.Scan(&item.Id, &item.Title, &item.Description, &item.Tag, &item.InstanceName,
+            &item.AuthorName, &item.PublishedAt, &item.Updated_at); err != nil {
+            return nil, fmt.Errorf("error scanning feed item: %v", err)
+        }
+        items = append(items, item)
+    }
+    return items, rows.Err()
+}
+
+// FindPublic mengambil daftar konten yang boleh dibaca tanpa login:
+// disetujui, tidak dihapus, dan accessibility 'public'
+func (p *ContentModel) FindPublic(opts ListOptions) ([]entities.Content, int, error) {
+    baseQuery := `
+        SELECT c.id, c.title, c.description, c.instance_id, c.created_at, c.updated_at, c.tag, c.accessibility
+        FROM content c
+        WHERE c.status = 'approved' AND c.accessibility = 'public' AND c.deleted_at IS NULL`
+    query, countQuery, args, countArgs := buildListQuery(baseQuery, nil, contentListSpec, opts)
+
+    total, err := p.count(countQuery, countArgs)
+    if err != nil {
+        return []entities.Content{}, 0, err
+    }
+
+    rows, err := p.conn.Query(query, args...)
+    if err != nil {
+        return []entities.Content{}, 0, err
+    }
+    defer rows.Close()
+
+    dataContent := []entities.Content{}
+    for rows.Next() {
+        var content entities.Content
+        if err := rows.Scan(&content.Id, &content.Title, &content.Description, &content.Instance_id,
+            &content.Created_at, &content.Updated_at, &content.Tag, &content.Accessibility); err != nil {
+            return []entities.Content{}, 0, err
+        }
+        content.Status = "approved"
+        dataContent = append(dataContent, content)
+    }
+    return dataContent, total, rows.Err()
+}
+
+// FindPublicByID sama seperti FindByIDWithAuthorName tetapi hanya untuk konten publik
+// yang disetujui dan tidak dihapus; konten lain dianggap tidak ada (nil)
+func (p *ContentModel) FindPublicByID(id int64) (*entities.Content, string, string, error) {
+    query := `
+        SELECT c.id, c.title, c.description, c.author_id, c.instance_id, 
+               c.created_at, c.updated_at, c.tag, c.accessibility,
+               COALESCE(u.name, ''), COALESCE(i.name, '')
+        FROM content c
+        LEFT JOIN user u ON c.author_id = u.id
+        LEFT JOIN instance i ON c.instance_id = i.id
+        WHERE c.id = ? AND c.status = 'approved' AND c.accessibility = 'public' AND c.deleted_at IS NULL`
+
+    var content entities.Content
+    var authorName, instanceName string
+    err := p.conn.QueryRow(query, id).Scan(&content.Id, &content.Title, &content.Description, &content.Author_id,
+        &content.Instance_id, &content.Created_at, &content.Updated_at, &content.Tag, &content.Accessibility,
+        &authorName, &instanceName)
+    if err == sql.ErrNoRows {
+        return nil, "", "", nil
+    }
+    if err != nil {
+        return nil, "", "", err
+    }
+    content.Status = "approved"
+    return &content, authorName, instanceName, nil
+}
@@ -0,0 +1,114 @@
+package models
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestFindDocumentsJoinsSubheadingText(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// Isi subjudul yang panjang tidak boleh terpotong seperti GROUP_CONCAT
+	longDescription := strings.Repeat("kepegawaian ", 200) + "penutup"
+	mock.ExpectQuery("SELECT c.id, c.title").WillReturnRows(
+		sqlmock.NewRows([]string{"id", "title", "description", "tag", "instance_id", "accessibility"}).
+			AddRow(1, "Cuti Tahunan", "Panduan cuti", "cuti", 2, "public").
+			AddRow(2, "Tanpa Subjudul", "", "umum", 2, "internal"))
+	mock.ExpectQuery("FROM subheadings s").WillReturnRows(
+		sqlmock.NewRows([]string{"content_id", "subheading", "subheading_description"}).
+			AddRow(1, "Syarat", "Masa kerja satu tahun").
+			AddRow(1, "Prosedur", longDescription).
+			AddRow(3, "Baru disetujui", "dilewati"))
+
+	documents, err := (&ContentModel{conn: db}).FindDocuments()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(documents) != 2 {
+		t.Fatalf("got %d documents, want 2", len(documents))
+	}
+	want := "Syarat Masa kerja satu tahun Prosedur " + longDescription
+	if documents[0].Subheadings != want {
+		t.Errorf("subheading text = %q..., want %q...", documents[0].Subheadings[:40], want[:40])
+	}
+	if documents[1].Subheadings != "" {
+		t.Errorf("content without subheadings got %q", documents[1].Subheadings)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// freshTimestamp cocok dengan DATETIME WIB yang baru saja dibuat aplikasi
+type freshTimestamp struct{}
+
+func (freshTimestamp) Match(value driver.Value) bool {
+	text, ok := value.(string)
+	if !ok {
+		return false
+	}
+	parsed, err := time.ParseInLocation(dateTimeLayout, text, wibNow().Location())
+	return err == nil && time.Since(parsed) < time.Minute && time.Since(parsed) > -time.Minute
+}
+
+// Perubahan status dan soft delete harus memperbarui updated_at, karena detail
+// konten menjawab If-Modified-Since dari kolom itu
+func TestContentChangesTouchUpdatedAt(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	model := &ContentModel{conn: db}
+
+	mock.ExpectExec("UPDATE content SET status = \\?, updated_at = \\? WHERE id = \\?").
+		WithArgs("approved", freshTimestamp{}, 7).WillReturnResult(sqlmock.NewResult(0, 1))
+	if err := model.UpdateStatus(7, "approved"); err != nil {
+		t.Fatal(err)
+	}
+
+	mock.ExpectExec("SET status = 'rejected'").
+		WithArgs("judul kurang jelas", freshTimestamp{}, 7).WillReturnResult(sqlmock.NewResult(0, 1))
+	if err := model.RejectContent(7, "judul kurang jelas"); err != nil {
+		t.Fatal(err)
+	}
+
+	mock.ExpectExec("UPDATE content SET deleted_at = \\?, updated_at = \\?").
+		WithArgs(freshTimestamp{}, freshTimestamp{}, int64(7)).WillReturnResult(sqlmock.NewResult(0, 1))
+	if err := model.DeleteByID(7); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDeleteSubheadingTouchesContent(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT content_id FROM subheadings").WithArgs(int64(12)).
+		WillReturnRows(sqlmock.NewRows([]string{"content_id"}).AddRow(7))
+	mock.ExpectExec("DELETE FROM subheadings").WithArgs(int64(12)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE content SET updated_at = \\? WHERE id = \\?").
+		WithArgs(freshTimestamp{}, int64(7)).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := (&SubheadingModel{conn: db}).DeleteByID(12); err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -36,7 +36,12 @@ func (p *HistoryModel) AddHistoryRecord(history entities.History) error {
 
 func (p *HistoryModel) GetHistoriesByUserId(userId string) ([]entities.History, error) {
     var histories []entities.History
-    rows, err := p.conn.Query("SELECT id, content_id, editor_id, edited_at, action FROM content_edit_history WHERE editor_id = ?", userId)
+    // Judul konten ikut diambil supaya klien tidak perlu memuat seluruh tabel konten
+    rows, err := p.conn.Query(`
+        SELECT h.id, h.content_id, h.editor_id, h.edited_at, h.action, COALESCE(c.title, '')
+        FROM content_edit_history h
+        LEFT JOIN content c ON c.id = h.content_id
+        WHERE h.editor_id = ?`, userId)
     if err != nil {
         fmt.Println("Error querying database:", err)  // Debugging output
         return nil, err
@@ -46,7 +51,7 @@ func (p *HistoryModel) GetHistoriesByUserId(userId string) ([]entities.History,
     for rows.Next() {
         var history entities.History
         // Pastikan melakukan Scan dengan benar sesuai urutan kolom yang diambil
-        if err := rows.Scan(&history.Id, &history.Content_Id, &history.Editor_Id, &history.Edited_at, &history.Action); err != nil {
+        if err := rows.Scan(&history.Id, &history.Content_Id, &history.Editor_Id, &history.Edited_at, &history.Action, &history.Content_Title); err != nil {
             fmt.Println("Error scanning row:", err)  // Debugging output
             return nil, err
         }
@@ -0,0 +1,269 @@
+package models
+
+import (
+	"backend/config"
+	"backend/entities"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Akun dikunci setelah MaxFailedLogins kegagalan berturut-turut. Lama kunci dimulai
+// dari baseLockout dan berlipat dua setiap kali akun dikunci lagi, maksimal maxLockout.
+const (
+	MaxFailedLogins = 5
+	baseLockout     = time.Minute
+	maxLockout      = 24 * time.Hour
+)
+
+const dateTimeLayout = "2006-01-02 15:04:05"
+
+// wibNow mengembalikan waktu sekarang dalam zona WIB seperti kolom DATETIME lainnya
+func wibNow() time.Time {
+	loc, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		loc = time.Local
+	}
+	return time.Now().In(loc)
+}
+
+func parseWIB(value string) time.Time {
+	loc, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		loc = time.Local
+	}
+	parsed, err := time.ParseInLocation(dateTimeLayout, value, loc)
+	if err != nil {
+		return time.Time{}
+	}
+	return parsed
+}
+
+// lockoutDuration menghitung lama kunci ke-n (mulai dari 1) dengan backoff eksponensial
+func lockoutDuration(lockoutCount int) time.Duration {
+	duration := baseLockout
+	for i := 1; i < lockoutCount; i++ {
+		duration *= 2
+		if duration >= maxLockout {
+			return maxLockout
+		}
+	}
+	return duration
+}
+
+type LoginSecurityModel struct {
+	conn *sql.DB
+}
+
+func NewLoginSecurityModel() *LoginSecurityModel {
+	conn, err := config.DBConnection()
+	if err != nil {
+		panic(err)
+	}
+	return &LoginSecurityModel{conn: conn}
+}
+
+// loginAttemptListSpec adalah kolom sort dan filter untuk riwayat percobaan login
+var loginAttemptListSpec = ListSpec{
+	Sortable: map[string]string{
+		"id":           "a.id",
+		"attempted_at": "a.attempted_at",
+		"email":        "a.email",
+	},
+	Filterable: map[string]string{
+		"email":      "a.email",
+		"user_id":    "a.user_id",
+		"ip_address": "a.ip_address",
+		"success":    "a.success",
+		"reason":     "a.reason",
+	},
+	Searchable:  []string{"a.email", "a.ip_address"},
+	DefaultSort: "id",
+	DefaultDesc: true,
+}
+
+// LoginAttemptListSpec dipakai controller untuk memvalidasi parameter list percobaan login
+func LoginAttemptListSpec() ListSpec {
+	return loginAttemptListSpec
+}
+
+// LockoutState mencari user aktif dengan login tersebut beserta batas waktu kuncinya.
+// Login berupa angka juga dicocokkan dengan NIP karena provider LDAP menerima login
+// dengan NIP. userID 0 berarti login tidak terdaftar; lockedUntil nol berarti akun
+// tidak terkunci.
+func (p *LoginSecurityModel) LockoutState(login string) (int64, time.Time, error) {
+	match := "u.email = ?"
+	args := []interface{}{login}
+	if nip, err := strconv.ParseInt(strings.TrimSpace(login), 10, 64); err == nil && nip > 0 {
+		match = "(u.email = ? OR u.nip = ?)"
+		args = append(args, nip)
+	}
+	query := `
+        SELECT u.id, COALESCE(l.locked_until, '')
+        FROM user u
+        LEFT JOIN account_lockouts l ON l.user_id = u.id
+        WHERE ` + match + ` AND u.deleted_at IS NULL
+        LIMIT 1`
+
+	var userID int64
+	var lockedUntil string
+	err := p.conn.QueryRow(query, args...).Scan(&userID, &lockedUntil)
+	if err == sql.ErrNoRows {
+		return 0, time.Time{}, nil
+	}
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+
+	until := parseWIB(lockedUntil)
+	if !until.After(wibNow()) {
+		until = time.Time{}
+	}
+	return userID, until, nil
+}
+
+// RecordAttempt menyimpan satu percobaan login ke tabel audit
+func (p *LoginSecurityModel) RecordAttempt(attempt entities.LoginAttempt) error {
+	query := `
+        INSERT INTO login_attempts (email, user_id, ip_address, user_agent, success, reason, attempted_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+	userAgent := attempt.UserAgent
+	if len(userAgent) > 255 {
+		userAgent = userAgent[:255]
+	}
+	_, err := p.conn.Exec(query, attempt.Email, attempt.UserID, attempt.IPAddress, userAgent,
+		attempt.Success, attempt.Reason, wibNow().Format(dateTimeLayout))
+	if err != nil {
+		return fmt.Errorf("failed to record login attempt: %w", err)
+	}
+	return nil
+}
+
+// RegisterFailure menambah hitungan gagal login berturut-turut. Jika mencapai
+// MaxFailedLogins, akun dikunci dan hasilnya berisi waktu berakhirnya kunci
+// serta urutan penguncian (untuk notifikasi admin).
+func (p *LoginSecurityModel) RegisterFailure(userID int64) (time.Time, int, error) {
+	tx, err := p.conn.Begin()
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	defer tx.Rollback()
+
+	now := wibNow()
+	_, err = tx.Exec(`
+        INSERT INTO account_lockouts (user_id, failed_count, lockout_count, updated_at)
+        VALUES (?, 1, 0, ?)
+        ON DUPLICATE KEY UPDATE failed_count = failed_count + 1, updated_at = VALUES(updated_at)`,
+		userID, now.Format(dateTimeLayout))
+	if err != nil {
+		return time.Time{}, 0, fmt.Errorf("failed to count login failure: %w", err)
+	}
+
+	var failedCount, lockoutCount int
+	err = tx.QueryRow("SELECT failed_count, lockout_count FROM account_lockouts WHERE user_id = ? FOR UPDATE", userID).
+		Scan(&failedCount, &lockoutCount)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+
+	var lockedUntil time.Time
+	if failedCount >= MaxFailedLogins {
+		lockoutCount++
+		lockedUntil = now.Add(lockoutDuration(lockoutCount))
+		_, err = tx.Exec(`
+            UPDATE account_lockouts
+            SET failed_count = 0, lockout_count = ?, locked_until = ?
+            WHERE user_id = ?`,
+			lockoutCount, lockedUntil.Format(dateTimeLayout), userID)
+		if err != nil {
+			return time.Time{}, 0, fmt.Errorf("failed to lock account: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return time.Time{}, 0, err
+	}
+	return lockedUntil, lockoutCount, nil
+}
+
+// ResetFailures menghapus hitungan gagal dan backoff setelah login berhasil
+func (p *LoginSecurityModel) ResetFailures(userID int64) error {
+	_, err := p.conn.Exec("DELETE FROM account_lockouts WHERE user_id = ?", userID)
+	return err
+}
+
+// Unlock membuka kunci akun oleh admin; hasilnya false jika akun tidak punya status kunci
+func (p *LoginSecurityModel) Unlock(userID int64) (bool, error) {
+	result, err := p.conn.Exec("DELETE FROM account_lockouts WHERE user_id = ?", userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to unlock account: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// FindAttempts mengambil riwayat percobaan login dengan pagination dan filter
+func (p *LoginSecurityModel) FindAttempts(opts ListOptions) ([]entities.LoginAttempt, int, error) {
+	baseQuery := `
+        SELECT a.id, a.email, a.user_id, a.ip_address, a.user_agent, a.success, a.reason, a.attempted_at
+        FROM login_attempts a
+        WHERE 1 = 1`
+	query, countQuery, args, countArgs := buildListQuery(baseQuery, nil, loginAttemptListSpec, opts)
+
+	var total int
+	if err := p.conn.QueryRow(countQuery, countArgs...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := p.conn.Query(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	attempts := []entities.LoginAttempt{}
+	for rows.Next() {
+		var attempt entities.LoginAttempt
+		var userID sql.NullInt64
+		if err := rows.Scan(&attempt.Id, &attempt.Email, &userID, &attempt.IPAddress, &attempt.UserAgent,
+			&attempt.Success, &attempt.Reason, &attempt.AttemptedAt); err != nil {
+			return nil, 0, err
+		}
+		if userID.Valid {
+			attempt.UserID = &userID.Int64
+		}
+		attempts = append(attempts, attempt)
+	}
+	return attempts, total, rows.Err()
+}
+
+// FindLocked mengambil akun yang saat ini masih terkunci
+func (p *LoginSecurityModel) FindLocked() ([]entities.LockedAccount, error) {
+	query := `
+        SELECT l.user_id, COALESCE(u.name, ''), COALESCE(u.email, ''), l.failed_count, l.lockout_count,
+               l.locked_until
+        FROM account_lockouts l
+        LEFT JOIN user u ON u.id = l.user_id
+        WHERE l.locked_until > ?
+        ORDER BY l.locked_until DESC`
+
+	rows, err := p.conn.Query(query, wibNow().Format(dateTimeLayout))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	accounts := []entities.LockedAccount{}
+	for rows.Next() {
+		var account entities.LockedAccount
+		if err := rows.Scan(&account.UserID, &account.Name, &account.Email, &account.FailedCount,
+			&account.LockoutCount, &account.LockedUntil); err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+	return accounts, rows.Err()
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// Login LDAP boleh memakai NIP, jadi kunci akun harus ditemukan lewat NIP juga;
+// kalau tidak, kegagalan login dengan NIP tidak pernah dihitung
+func TestLockoutStateMatchesNIP(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	model := &LoginSecurityModel{conn: db}
+	lockedUntil := wibNow().Add(10 * time.Minute).Format(dateTimeLayout)
+
+	mock.ExpectQuery("WHERE \\(u.email = \\? OR u.nip = \\?\\) AND u.deleted_at IS NULL").
+		WithArgs("198703122010011002", int64(198703122010011002)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "locked_until"}).AddRow(42, lockedUntil))
+	userID, until, err := model.LockoutState("198703122010011002")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if userID != 42 || until.IsZero() {
+		t.Errorf("NIP login: userID = %d, lockedUntil = %v, want 42 and locked", userID, until)
+	}
+
+	mock.ExpectQuery("WHERE u.email = \\? AND u.deleted_at IS NULL").
+		WithArgs("budi@jogjaprov.go.id").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "locked_until"}).AddRow(42, ""))
+	userID, until, err = model.LockoutState("budi@jogjaprov.go.id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if userID != 42 || !until.IsZero() {
+		t.Errorf("email login: userID = %d, lockedUntil = %v, want 42 and unlocked", userID, until)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
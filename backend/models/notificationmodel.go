@@ -0,0 +1,91 @@
+package models
+
+import (
+	"backend/config"
+	"backend/entities"
+	"database/sql"
+	"fmt"
+)
+
+// Jenis notifikasi admin
+const (
+	NotificationAccountLocked = "account_locked"
+	NotificationTokenReuse    = "refresh_token_reuse"
+)
+
+type NotificationModel struct {
+	conn *sql.DB
+}
+
+func NewNotificationModel() *NotificationModel {
+	conn, err := config.DBConnection()
+	if err != nil {
+		panic(err)
+	}
+	return &NotificationModel{conn: conn}
+}
+
+// Notify menyimpan notifikasi untuk administrator; userID boleh 0 jika tidak terkait user
+func (p *NotificationModel) Notify(kind, message string, userID int64) error {
+	var relatedUser sql.NullInt64
+	if userID != 0 {
+		relatedUser = sql.NullInt64{Int64: userID, Valid: true}
+	}
+
+	_, err := p.conn.Exec(
+		"INSERT INTO admin_notifications (kind, message, user_id, created_at) VALUES (?, ?, ?, ?)",
+		kind, message, relatedUser, wibNow().Format(dateTimeLayout))
+	if err != nil {
+		return fmt.Errorf("failed to save notification: %w", err)
+	}
+	return nil
+}
+
+// FindNotifications mengambil notifikasi terbaru, bisa dibatasi hanya yang belum dibaca
+func (p *NotificationModel) FindNotifications(unreadOnly bool, limit int) ([]entities.AdminNotification, error) {
+	query := "SELECT id, kind, message, user_id, created_at, read_at FROM admin_notifications"
+	if unreadOnly {
+		query += " WHERE read_at IS NULL"
+	}
+	query += " ORDER BY created_at DESC, id DESC LIMIT ?"
+
+	rows, err := p.conn.Query(query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifications := []entities.AdminNotification{}
+	for rows.Next() {
+		var notification entities.AdminNotification
+		var userID sql.NullInt64
+		var readAt sql.NullString
+		if err := rows.Scan(&notification.Id, &notification.Kind, &notification.Message, &userID,
+			&notification.CreatedAt, &readAt); err != nil {
+			return nil, err
+		}
+		if userID.Valid {
+			notification.UserID = &userID.Int64
+		}
+		if readAt.Valid {
+			notification.ReadAt = &readAt.String
+		}
+		notifications = append(notifications, notification)
+	}
+	return notifications, rows.Err()
+}
+
+// MarkRead menandai notifikasi sudah dibaca; hasilnya false jika id tidak ada
+func (p *NotificationModel) MarkRead(id int64) (bool, error) {
+	_, err := p.conn.Exec(
+		"UPDATE admin_notifications SET read_at = ? WHERE id = ? AND read_at IS NULL",
+		wibNow().Format(dateTimeLayout), id)
+	if err != nil {
+		return false, err
+	}
+
+	// Notifikasi yang sudah dibaca sebelumnya tidak terhitung sebagai baris berubah
+	var exists bool
+	err = p.conn.QueryRow("SELECT EXISTS(SELECT 1 FROM admin_notifications WHERE id = ?)", id).Scan(&exists)
+	return exists, err
+}
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ListOptions berisi parameter pagination, sorting dan filter untuk endpoint list
+type ListOptions struct {
+	Limit   int
+	Offset  int
+	SortBy  string
+	Desc    bool
+	Filters map[string]string
+}
+
+// ListSpec mendefinisikan kolom yang boleh dipakai untuk sort dan filter pada satu query list.
+// Key adalah nama parameter dari request, value adalah nama kolom di SQL.
+type ListSpec struct {
+	Sortable    map[string]string
+	Filterable  map[string]string
+	Searchable  []string
+	DefaultSort string
+	DefaultDesc bool
+}
+
+// Validate memastikan parameter sort dan filter dikenal oleh spec
+func (o ListOptions) Validate(spec ListSpec) error {
+	if o.SortBy != "" {
+		if _, ok := spec.Sortable[o.SortBy]; !ok {
+			return fmt.Errorf("cannot sort by %q", o.SortBy)
+		}
+	}
+	for field := range o.Filters {
+		if field == "q" && len(spec.Searchable) > 0 {
+			continue
+		}
+		if _, ok := spec.Filterable[field]; !ok {
+			return fmt.Errorf("cannot filter by %q", field)
+		}
+	}
+	return nil
+}
+
+// buildListQuery menambahkan filter, ORDER BY dan LIMIT/OFFSET pada query dasar.
+// baseQuery harus sudah memiliki klausa WHERE (boleh "WHERE 1 = 1").
+// Hasilnya adalah query data dan query COUNT(*) dengan argumen masing-masing.
+func buildListQuery(baseQuery string, args []interface{}, spec ListSpec, opts ListOptions) (string, string, []interface{}, []interface{}) {
+	var where strings.Builder
+	filterArgs := append([]interface{}{}, args...)
+
+	for field, value := range opts.Filters {
+		if field == "q" && len(spec.Searchable) > 0 {
+			conditions := make([]string, len(spec.Searchable))
+			for i, column := range spec.Searchable {
+				conditions[i] = column + " LIKE ?"
+				filterArgs = append(filterArgs, "%"+value+"%")
+			}
+			where.WriteString(" AND (" + strings.Join(conditions, " OR ") + ")")
+			continue
+		}
+		column, ok := spec.Filterable[field]
+		if !ok {
+			continue
+		}
+		where.WriteString(" AND " + column + " = ?")
+		filterArgs = append(filterArgs, value)
+	}
+
+	filtered := baseQuery + where.String()
+	countQuery := "SELECT COUNT(*) FROM (" + filtered + ") AS list_total"
+
+	sortColumn, ok := spec.Sortable[opts.SortBy]
+	desc := opts.Desc
+	if !ok {
+		sortColumn = spec.Sortable[spec.DefaultSort]
+		desc = spec.DefaultDesc
+	}
+	direction := "ASC"
+	if desc {
+		direction = "DESC"
+	}
+
+	// Tambahkan id sebagai pemecah urutan yang sama agar halaman tidak saling tumpang tindih
+	orderBy := sortColumn + " " + direction
+	if idColumn, ok := spec.Sortable["id"]; ok && idColumn != sortColumn {
+		orderBy += ", " + idColumn + " " + direction
+	}
+
+	dataQuery := filtered + " ORDER BY " + orderBy + " LIMIT ? OFFSET ?"
+	dataArgs := append(append([]interface{}{}, filterArgs...), opts.Limit, opts.Offset)
+
+	return dataQuery, countQuery, dataArgs, filterArgs
+}
@@ -0,0 +1,119 @@
+package models
+
+import (
+	"backend/config"
+	"backend/helpers"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type PasswordResetModel struct {
+	conn *sql.DB
+}
+
+func NewPasswordResetModel() *PasswordResetModel {
+	conn, err := config.DBConnection()
+	if err != nil {
+		panic(err)
+	}
+	return &PasswordResetModel{conn: conn}
+}
+
+// FindActiveUserByEmail mengambil id user lokal yang belum dihapus; 0 jika email tidak
+// terdaftar atau akunnya dikelola LDAP/SSO
+func (p *PasswordResetModel) FindActiveUserByEmail(email string) (int64, error) {
+	var userID int64
+	err := p.conn.QueryRow(
+		"SELECT id FROM user WHERE email = ? AND deleted_at IS NULL AND auth_provider = 'local'",
+		email).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return userID, err
+}
+
+// RecentlyRequested memeriksa apakah user sudah meminta reset dalam rentang within,
+// agar satu alamat email tidak dibanjiri email reset
+func (p *PasswordResetModel) RecentlyRequested(userID int64, within time.Duration) (bool, error) {
+	var recent bool
+	err := p.conn.QueryRow(
+		"SELECT EXISTS(SELECT 1 FROM password_resets WHERE user_id = ? AND created_at > ?)",
+		userID, wibNow().Add(-within).Format(dateTimeLayout)).Scan(&recent)
+	return recent, err
+}
+
+// Create menyimpan hash token reset baru yang berlaku selama ttl
+func (p *PasswordResetModel) Create(userID int64, tokenHash string, ipAddress string, ttl time.Duration) error {
+	now := wibNow()
+	_, err := p.conn.Exec(`
+        INSERT INTO password_resets (token_hash, user_id, ip_address, created_at, expires_at)
+        VALUES (?, ?, ?, ?, ?)`,
+		tokenHash, userID, ipAddress, now.Format(dateTimeLayout), now.Add(ttl).Format(dateTimeLayout))
+	if err != nil {
+		return fmt.Errorf("failed to save password reset: %w", err)
+	}
+	return nil
+}
+
+// FindUser mengambil user pemilik token reset yang masih berlaku; 0 jika token
+// tidak ada, kedaluwarsa, atau sudah dipakai
+func (p *PasswordResetModel) FindUser(tokenHash string) (int64, error) {
+	var userID int64
+	err := p.conn.QueryRow(`
+        SELECT user_id FROM password_resets
+        WHERE token_hash = ? AND used_at IS NULL AND expires_at > ?`,
+		tokenHash, wibNow().Format(dateTimeLayout)).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return userID, err
+}
+
+// Reset memakai token reset untuk mengganti password dalam satu transaksi. Semua
+// token reset user yang lain ikut dibatalkan dan versi token user dinaikkan.
+// Hasilnya 0 jika token tidak ada, kedaluwarsa, atau sudah dipakai.
+func (p *PasswordResetModel) Reset(tokenHash string, password string) (int64, error) {
+	hash, err := helpers.HashPassword(password)
+	if err != nil {
+		return 0, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	tx, err := p.conn.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	now := wibNow().Format(dateTimeLayout)
+	var userID int64
+	err = tx.QueryRow(`
+        SELECT user_id FROM password_resets
+        WHERE token_hash = ? AND used_at IS NULL AND expires_at > ?
+        FOR UPDATE`, tokenHash, now).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	// Token ini dan token reset lain milik user dibatalkan bersama password lama
+	if err := recordPasswordChange(tx, userID); err != nil {
+		return 0, err
+	}
+
+	result, err := tx.Exec(
+		"UPDATE user SET password = ?, token_version = token_version + 1 WHERE id = ? AND deleted_at IS NULL",
+		hash, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to update password: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return 0, err
+	} else if affected == 0 {
+		// User sudah dihapus setelah meminta reset
+		return 0, tx.Commit()
+	}
+	return userID, tx.Commit()
+}
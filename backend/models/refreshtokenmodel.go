@@ -0,0 +1,117 @@
+package models
+
+import (
+	"backend/config"
+	"backend/entities"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type RefreshTokenModel struct {
+	conn *sql.DB
+}
+
+func NewRefreshTokenModel() *RefreshTokenModel {
+	conn, err := config.DBConnection()
+	if err != nil {
+		panic(err)
+	}
+	return &RefreshTokenModel{conn: conn}
+}
+
+// Create menyimpan refresh token baru (token pertama dari sebuah family) yang berlaku selama ttl
+func (p *RefreshTokenModel) Create(token entities.RefreshToken, ttl time.Duration) (int64, error) {
+	return insertRefreshToken(p.conn, token, ttl)
+}
+
+type sqlExecer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+func insertRefreshToken(conn sqlExecer, token entities.RefreshToken, ttl time.Duration) (int64, error) {
+	now := wibNow()
+	result, err := conn.Exec(`
+        INSERT INTO refresh_tokens (user_id, family_id, parent_id, token_hash, created_at, expires_at)
+        VALUES (?, ?, ?, ?, ?, ?)`,
+		token.UserID, token.FamilyID, token.ParentID, token.TokenHash,
+		now.Format(dateTimeLayout), now.Add(ttl).Format(dateTimeLayout))
+	if err != nil {
+		return 0, fmt.Errorf("failed to save refresh token: %w", err)
+	}
+	return result.LastInsertId()
+}
+
+// FindByHash mencari refresh token dari hash-nya; nil jika tidak ada
+func (p *RefreshTokenModel) FindByHash(tokenHash string) (*entities.RefreshToken, error) {
+	var token entities.RefreshToken
+	var parentID sql.NullInt64
+	var usedAt, revokedAt sql.NullString
+	err := p.conn.QueryRow(`
+        SELECT id, user_id, family_id, parent_id, token_hash, created_at, expires_at, used_at, revoked_at
+        FROM refresh_tokens
+        WHERE token_hash = ?`, tokenHash).Scan(
+		&token.Id, &token.UserID, &token.FamilyID, &parentID, &token.TokenHash,
+		&token.CreatedAt, &token.ExpiresAt, &usedAt, &revokedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if parentID.Valid {
+		token.ParentID = &parentID.Int64
+	}
+	if usedAt.Valid {
+		token.UsedAt = &usedAt.String
+	}
+	if revokedAt.Valid {
+		token.RevokedAt = &revokedAt.String
+	}
+	return &token, nil
+}
+
+// RefreshTokenExpired memeriksa expires_at refresh token
+func RefreshTokenExpired(token *entities.RefreshToken) bool {
+	expiresAt := parseWIB(token.ExpiresAt)
+	return expiresAt.IsZero() || !expiresAt.After(wibNow())
+}
+
+// Rotate menandai token lama sudah dipakai dan menyimpan penggantinya dalam satu
+// transaksi. Hasilnya false jika token lama ternyata sudah dipakai atau dicabut
+// oleh request lain (indikasi replay).
+func (p *RefreshTokenModel) Rotate(currentID int64, next entities.RefreshToken, ttl time.Duration) (bool, error) {
+	tx, err := p.conn.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(
+		"UPDATE refresh_tokens SET used_at = ? WHERE id = ? AND used_at IS NULL AND revoked_at IS NULL",
+		wibNow().Format(dateTimeLayout), currentID)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark refresh token as used: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return false, err
+	}
+
+	next.ParentID = &currentID
+	if _, err := insertRefreshToken(tx, next, ttl); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// RevokeFamily mencabut semua refresh token dalam satu sesi login
+func (p *RefreshTokenModel) RevokeFamily(familyID string) error {
+	_, err := p.conn.Exec(
+		"UPDATE refresh_tokens SET revoked_at = ? WHERE family_id = ? AND revoked_at IS NULL",
+		wibNow().Format(dateTimeLayout), familyID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+	return nil
+}
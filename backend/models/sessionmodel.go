@@ -0,0 +1,145 @@
+package models
+
+import (
+	"backend/config"
+	"backend/entities"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type SessionModel struct {
+	conn *sql.DB
+}
+
+func NewSessionModel() *SessionModel {
+	conn, err := config.DBConnection()
+	if err != nil {
+		panic(err)
+	}
+	return &SessionModel{conn: conn}
+}
+
+// Create mencatat sesi baru saat login
+func (p *SessionModel) Create(session entities.Session) error {
+	userAgent := session.UserAgent
+	if len(userAgent) > 255 {
+		userAgent = userAgent[:255]
+	}
+
+	now := wibNow().Format(dateTimeLayout)
+	_, err := p.conn.Exec(`
+        INSERT INTO sessions (id, user_id, device, user_agent, ip_address, created_at, last_seen_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		session.Id, session.UserID, session.Device, userAgent, session.IPAddress, now, now)
+	if err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+	return nil
+}
+
+// FindActiveByUser mengambil sesi user yang belum dicabut dan masih dipakai
+// dalam rentang idle (sesi yang lebih lama sudah tidak punya refresh token berlaku)
+func (p *SessionModel) FindActiveByUser(userID int64, idle time.Duration) ([]entities.Session, error) {
+	rows, err := p.conn.Query(`
+        SELECT id, user_id, device, user_agent, ip_address, created_at, last_seen_at
+        FROM sessions
+        WHERE user_id = ? AND revoked_at IS NULL AND last_seen_at >= ?
+        ORDER BY last_seen_at DESC`,
+		userID, wibNow().Add(-idle).Format(dateTimeLayout))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []entities.Session{}
+	for rows.Next() {
+		var session entities.Session
+		if err := rows.Scan(&session.Id, &session.UserID, &session.Device, &session.UserAgent,
+			&session.IPAddress, &session.CreatedAt, &session.LastSeenAt); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
+
+// Revoke mencabut satu sesi milik user beserta seluruh refresh token-nya.
+// Hasilnya false jika sesi tidak ada, milik user lain, atau sudah dicabut.
+func (p *SessionModel) Revoke(userID int64, sessionID string) (bool, error) {
+	return p.revoke("user_id = ? AND id = ?", userID, sessionID)
+}
+
+// RevokeByID mencabut sesi tanpa memeriksa pemiliknya (logout, reuse refresh token)
+func (p *SessionModel) RevokeByID(sessionID string) error {
+	_, err := p.revoke("id = ?", sessionID)
+	return err
+}
+
+// RevokeAll mencabut semua sesi user kecuali exceptID (boleh kosong) dan
+// mengembalikan id sesi yang dicabut
+func (p *SessionModel) RevokeAll(userID int64, exceptID string) ([]string, error) {
+	rows, err := p.conn.Query(
+		"SELECT id FROM sessions WHERE user_id = ? AND revoked_at IS NULL AND id <> ?", userID, exceptID)
+	if err != nil {
+		return nil, err
+	}
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if _, err := p.revoke("user_id = ? AND id <> ?", userID, exceptID); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// revoke menandai sesi dan refresh token dengan family yang sama sebagai dicabut
+func (p *SessionModel) revoke(condition string, args ...interface{}) (bool, error) {
+	tx, err := p.conn.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	now := wibNow().Format(dateTimeLayout)
+	result, err := tx.Exec("UPDATE sessions SET revoked_at = ? WHERE revoked_at IS NULL AND "+condition,
+		append([]interface{}{now}, args...)...)
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke session: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	_, err = tx.Exec(`
+        UPDATE refresh_tokens SET revoked_at = ?
+        WHERE revoked_at IS NULL AND family_id IN (SELECT id FROM sessions WHERE `+condition+`)`,
+		append([]interface{}{now}, args...)...)
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+
+	return affected > 0, tx.Commit()
+}
+
+// IsActive memeriksa bahwa sesi ada, milik user tersebut, belum dicabut, dan belum
+// melewati maxAge sejak login meskipun refresh token-nya terus dirotasi
+func (p *SessionModel) IsActive(sessionID string, userID int64, maxAge time.Duration) (bool, error) {
+	var active bool
+	err := p.conn.QueryRow(
+		"SELECT EXISTS(SELECT 1 FROM sessions WHERE id = ? AND user_id = ? AND revoked_at IS NULL AND created_at >= ?)",
+		sessionID, userID, wibNow().Add(-maxAge).Format(dateTimeLayout)).Scan(&active)
+	return active, err
+}
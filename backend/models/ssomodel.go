@@ -0,0 +1,86 @@
+package models
+
+import (
+	"backend/config"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type SSOModel struct {
+	conn *sql.DB
+}
+
+func NewSSOModel() *SSOModel {
+	conn, err := config.DBConnection()
+	if err != nil {
+		panic(err)
+	}
+	return &SSOModel{conn: conn}
+}
+
+// CreateLogin menyimpan state, nonce dan code_verifier login SSO yang berlaku selama ttl
+func (p *SSOModel) CreateLogin(stateHash, nonce, codeVerifier string, ttl time.Duration) error {
+	now := wibNow()
+	_, err := p.conn.Exec(`
+        INSERT INTO oidc_logins (state_hash, nonce, code_verifier, created_at, expires_at)
+        VALUES (?, ?, ?, ?, ?)`,
+		stateHash, nonce, codeVerifier, now.Format(dateTimeLayout), now.Add(ttl).Format(dateTimeLayout))
+	if err != nil {
+		return fmt.Errorf("failed to save SSO login: %w", err)
+	}
+	return nil
+}
+
+// TakeLogin memakai state login SSO dan mengembalikan nonce serta code_verifier-nya.
+// ok bernilai false jika state tidak ada, kedaluwarsa, atau sudah dipakai.
+func (p *SSOModel) TakeLogin(stateHash string) (nonce string, codeVerifier string, ok bool, err error) {
+	now := wibNow().Format(dateTimeLayout)
+	result, err := p.conn.Exec(
+		"UPDATE oidc_logins SET used_at = ? WHERE state_hash = ? AND used_at IS NULL AND expires_at > ?",
+		now, stateHash, now)
+	if err != nil {
+		return "", "", false, err
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return "", "", false, err
+	}
+
+	err = p.conn.QueryRow("SELECT nonce, code_verifier FROM oidc_logins WHERE state_hash = ?", stateHash).Scan(&nonce, &codeVerifier)
+	if err != nil {
+		return "", "", false, err
+	}
+	return nonce, codeVerifier, true, nil
+}
+
+// CreateLoginCode menyimpan kode sekali pakai untuk menukar login SSO dengan token wiki
+func (p *SSOModel) CreateLoginCode(userID int64, codeHash string, ttl time.Duration) error {
+	now := wibNow()
+	_, err := p.conn.Exec(`
+        INSERT INTO sso_login_codes (code_hash, user_id, created_at, expires_at)
+        VALUES (?, ?, ?, ?)`,
+		codeHash, userID, now.Format(dateTimeLayout), now.Add(ttl).Format(dateTimeLayout))
+	if err != nil {
+		return fmt.Errorf("failed to save SSO login code: %w", err)
+	}
+	return nil
+}
+
+// UseLoginCode memakai kode login SSO dan mengembalikan user pemiliknya; 0 jika
+// kode tidak ada, kedaluwarsa, atau sudah dipakai
+func (p *SSOModel) UseLoginCode(codeHash string) (int64, error) {
+	now := wibNow().Format(dateTimeLayout)
+	result, err := p.conn.Exec(
+		"UPDATE sso_login_codes SET used_at = ? WHERE code_hash = ? AND used_at IS NULL AND expires_at > ?",
+		now, codeHash, now)
+	if err != nil {
+		return 0, err
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		return 0, err
+	}
+
+	var userID int64
+	err = p.conn.QueryRow("SELECT user_id FROM sso_login_codes WHERE code_hash = ?", codeHash).Scan(&userID)
+	return userID, err
+}
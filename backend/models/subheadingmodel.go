@@ -20,7 +20,7 @@ func NewSubheadingModel() *SubheadingModel {
 	}
 }
 
-func (p *SubheadingModel) FindByContentID(contentID int64) ([]entities.Subheading, error) {
+func (p *SubheadingModel) findByContentID(contentID int64) ([]entities.Subheading, error) {
 	query := "SELECT * FROM subheadings WHERE content_id = ?"
 	rows, err := p.conn.Query(query, contentID)
 	if err != nil {
@@ -60,6 +60,13 @@ func (p *SubheadingModel) FindByContentID(contentID int64) ([]entities.Subheadin
 //     return err
 // }
 
+// contentIDOf mencari konten pemilik subjudul untuk invalidasi cache
+func (p *SubheadingModel) contentIDOf(id int64) int64 {
+	var contentID int64
+	p.conn.QueryRow("SELECT content_id FROM subheadings WHERE id = ?", id).Scan(&contentID)
+	return contentID
+}
+
 func (p *SubheadingModel) UpdateByID(subheading entities.Subheading) error {
     query := `
         UPDATE subheadings 
@@ -73,7 +80,11 @@ func (p *SubheadingModel) UpdateByID(subheading entities.Subheading) error {
 		subheading.Subheading_Description, 
 		subheading.Updated_at,
 		subheading.Id)
-    return err
+    if err != nil {
+        return err
+    }
+    publishContentEvent(SubheadingChanged, p.contentIDOf(subheading.Id))
+    return nil
 }
 
 
@@ -98,11 +109,25 @@ func (p *SubheadingModel) CreateSubheading(subheading entities.Subheading) (int6
     if err != nil {
         return 0, err
     }
+    publishContentEvent(SubheadingChanged, subheading.ContentID)
     return subheadingID, nil
 }
 
 func (p *SubheadingModel) DeleteByID(id int64) error {
+	// content_id harus dibaca sebelum barisnya hilang
+	contentID := p.contentIDOf(id)
 	query := "DELETE FROM subheadings WHERE id = ?"
 	_, err := p.conn.Exec(query, id)
-	return err
+	if err != nil {
+		return err
+	}
+	// Subjudul yang dihapus tidak meninggalkan updated_at, jadi konten induknya
+	// yang ditandai berubah agar If-Modified-Since tidak menjawab 304
+	if contentID != 0 {
+		if _, err := p.conn.Exec("UPDATE content SET updated_at = ? WHERE id = ?", wibNow().Format(dateTimeLayout), contentID); err != nil {
+			return err
+		}
+	}
+	publishContentEvent(SubheadingChanged, contentID)
+	return nil
 }
\ No newline at end of file
@@ -0,0 +1,211 @@
+package models
+
+import (
+	"backend/config"
+	"backend/entities"
+	"backend/helpers"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+)
+
+// Status kunci di tabel token_keys
+const (
+	TokenKeyActive   = "active"
+	TokenKeyPrevious = "previous"
+	TokenKeyRetired  = "retired"
+)
+
+var (
+	ErrTokenKeyNotFound = errors.New("token key not found")
+	ErrTokenKeyActive   = errors.New("the active token key cannot be retired, rotate first")
+)
+
+type TokenKeyModel struct {
+	conn *sql.DB
+}
+
+func NewTokenKeyModel() *TokenKeyModel {
+	conn, err := config.DBConnection()
+	if err != nil {
+		panic(err)
+	}
+	return &TokenKeyModel{conn: conn}
+}
+
+// LoadKeys membaca kunci yang belum dipensiunkan untuk keyring. Jika tabel masih
+// kosong, kunci dari konfigurasi disimpan dulu sebagai kunci "legacy" yang aktif.
+// Jika ternyata ada lebih dari satu kunci aktif, yang terbaru dipakai untuk
+// menerbitkan token dan sisanya hanya memverifikasi, agar server tetap bisa jalan.
+func (p *TokenKeyModel) LoadKeys() ([]helpers.TokenKey, error) {
+	if err := p.seedLegacy(); err != nil {
+		return nil, err
+	}
+
+	rows, err := p.conn.Query(
+		// kid ikut diurutkan supaya semua server memilih kunci aktif yang sama
+		"SELECT kid, jwt_key, encryption_key, status FROM token_keys WHERE status <> ? ORDER BY created_at DESC, kid DESC",
+		TokenKeyRetired)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []helpers.TokenKey{}
+	activeKid := ""
+	for rows.Next() {
+		var kid, sealedJWT, sealedEncryption, status string
+		if err := rows.Scan(&kid, &sealedJWT, &sealedEncryption, &status); err != nil {
+			return nil, err
+		}
+		jwtKey, err := helpers.OpenSecret(sealedJWT)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unseal token key %s (was ENCRYPTION_KEY changed?): %w", kid, err)
+		}
+		encryptionKey, err := helpers.OpenSecret(sealedEncryption)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unseal token key %s (was ENCRYPTION_KEY changed?): %w", kid, err)
+		}
+		active := status == TokenKeyActive && activeKid == ""
+		if active {
+			activeKid = kid
+		} else if status == TokenKeyActive {
+			log.Printf("Token key %s is also marked active; issuing tokens with newer key %s only", kid, activeKid)
+		}
+		keys = append(keys, helpers.TokenKey{
+			ID:            kid,
+			JWTKey:        jwtKey,
+			EncryptionKey: encryptionKey,
+			Active:        active,
+		})
+	}
+	return keys, rows.Err()
+}
+
+func (p *TokenKeyModel) seedLegacy() error {
+	var exists bool
+	if err := p.conn.QueryRow("SELECT EXISTS(SELECT 1 FROM token_keys)").Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	cfg := config.Get()
+	// INSERT IGNORE agar server lain yang mengisi bersamaan tidak gagal
+	return p.insert(p.conn, "INSERT IGNORE", helpers.TokenKey{
+		ID:            helpers.LegacyKeyID,
+		JWTKey:        []byte(cfg.JWTKey),
+		EncryptionKey: []byte(cfg.EncryptionKey),
+	}, TokenKeyActive)
+}
+
+func (p *TokenKeyModel) insert(conn sqlExecer, verb string, key helpers.TokenKey, status string) error {
+	sealedJWT, err := helpers.SealSecret(key.JWTKey)
+	if err != nil {
+		return err
+	}
+	sealedEncryption, err := helpers.SealSecret(key.EncryptionKey)
+	if err != nil {
+		return err
+	}
+	_, err = conn.Exec(verb+` INTO token_keys (kid, jwt_key, encryption_key, status, created_at)
+        VALUES (?, ?, ?, ?, ?)`,
+		key.ID, sealedJWT, sealedEncryption, status, wibNow().Format(dateTimeLayout))
+	if err != nil {
+		return fmt.Errorf("failed to save token key: %w", err)
+	}
+	return nil
+}
+
+// FindAll menampilkan semua kunci, terbaru lebih dulu
+func (p *TokenKeyModel) FindAll() ([]entities.TokenKey, error) {
+	rows, err := p.conn.Query(`
+        SELECT kid, status, created_at, rotated_at, retired_at
+        FROM token_keys
+        ORDER BY created_at DESC, kid`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []entities.TokenKey{}
+	for rows.Next() {
+		var key entities.TokenKey
+		var rotatedAt, retiredAt sql.NullString
+		if err := rows.Scan(&key.Kid, &key.Status, &key.CreatedAt, &rotatedAt, &retiredAt); err != nil {
+			return nil, err
+		}
+		if rotatedAt.Valid {
+			key.RotatedAt = &rotatedAt.String
+		}
+		if retiredAt.Valid {
+			key.RetiredAt = &retiredAt.String
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// Rotate membuat kunci aktif baru; kunci aktif sebelumnya menjadi previous dan
+// tetap memverifikasi token yang sudah diterbitkan sampai dipensiunkan
+func (p *TokenKeyModel) Rotate() (string, error) {
+	if err := p.seedLegacy(); err != nil {
+		return "", err
+	}
+
+	key, err := helpers.GenerateTokenKey()
+	if err != nil {
+		return "", err
+	}
+
+	tx, err := p.conn.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	// Kunci aktif dikunci dulu agar rotasi yang berjalan bersamaan menunggu di sini;
+	// tanpa ini keduanya menurunkan kunci lama dan masing-masing menyisipkan kunci aktif
+	rows, err := tx.Query("SELECT kid FROM token_keys WHERE status = ? FOR UPDATE", TokenKeyActive)
+	if err != nil {
+		return "", fmt.Errorf("failed to lock active token key: %w", err)
+	}
+	rows.Close()
+
+	_, err = tx.Exec("UPDATE token_keys SET status = ?, rotated_at = ? WHERE status = ?",
+		TokenKeyPrevious, wibNow().Format(dateTimeLayout), TokenKeyActive)
+	if err != nil {
+		return "", fmt.Errorf("failed to demote active token key: %w", err)
+	}
+	if err := p.insert(tx, "INSERT", key, TokenKeyActive); err != nil {
+		return "", err
+	}
+	return key.ID, tx.Commit()
+}
+
+// Retire memensiunkan kunci previous; token yang ditandatangani dengannya langsung ditolak
+func (p *TokenKeyModel) Retire(kid string) error {
+	var status string
+	err := p.conn.QueryRow("SELECT status FROM token_keys WHERE kid = ?", kid).Scan(&status)
+	if err == sql.ErrNoRows {
+		return ErrTokenKeyNotFound
+	}
+	if err != nil {
+		return err
+	}
+	switch status {
+	case TokenKeyActive:
+		return ErrTokenKeyActive
+	case TokenKeyRetired:
+		return nil
+	}
+
+	_, err = p.conn.Exec("UPDATE token_keys SET status = ?, retired_at = ? WHERE kid = ? AND status = ?",
+		TokenKeyRetired, wibNow().Format(dateTimeLayout), kid, TokenKeyPrevious)
+	if err != nil {
+		return fmt.Errorf("failed to retire token key: %w", err)
+	}
+	return nil
+}
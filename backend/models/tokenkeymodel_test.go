@@ -0,0 +1,74 @@
+package models
+
+import (
+	"backend/helpers"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// Rotasi harus mengunci kunci aktif sebelum menurunkannya, supaya dua rotasi
+// bersamaan berjalan bergantian dan hanya menyisakan satu kunci aktif
+func TestRotateLocksActiveKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT EXISTS").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT kid FROM token_keys WHERE status = \\? FOR UPDATE").WithArgs(TokenKeyActive).
+		WillReturnRows(sqlmock.NewRows([]string{"kid"}).AddRow("lama"))
+	mock.ExpectExec("UPDATE token_keys SET status = \\?").
+		WithArgs(TokenKeyPrevious, sqlmock.AnyArg(), TokenKeyActive).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO token_keys").
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), TokenKeyActive, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if _, err := (&TokenKeyModel{conn: db}).Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// Data lama bisa saja berisi dua kunci aktif; keyring tetap terpasang dengan kunci
+// terbaru sebagai penerbit dan kunci lainnya hanya untuk verifikasi
+func TestLoadKeysPicksNewestActiveKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	seal := func(secret string) string {
+		sealed, err := helpers.SealSecret([]byte(secret))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sealed
+	}
+	mock.ExpectQuery("SELECT EXISTS").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery("FROM token_keys WHERE status <> \\? ORDER BY created_at DESC, kid DESC").WithArgs(TokenKeyRetired).
+		WillReturnRows(sqlmock.NewRows([]string{"kid", "jwt_key", "encryption_key", "status"}).
+			AddRow("baru", seal("jwt-baru"), seal("0123456789abcdef0123456789abcdef"), TokenKeyActive).
+			AddRow("kembar", seal("jwt-kembar"), seal("fedcba9876543210fedcba9876543210"), TokenKeyActive).
+			AddRow("lama", seal("jwt-lama"), seal("00112233445566778899aabbccddeeff"), TokenKeyPrevious))
+
+	keys, err := (&TokenKeyModel{conn: db}).LoadKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 3 || !keys[0].Active || keys[1].Active || keys[2].Active {
+		t.Fatalf("keys = %+v, want only %q active", keys, "baru")
+	}
+	if err := helpers.InstallTokenKeys(keys); err != nil {
+		t.Fatalf("InstallTokenKeys: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,296 @@
+package models
+
+import (
+	"backend/config"
+	"backend/entities"
+	"backend/helpers"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MaxTwoFactorAttempts adalah jumlah kode salah sebelum challenge login harus diulang dari password
+const MaxTwoFactorAttempts = 5
+
+type TwoFactorModel struct {
+	conn *sql.DB
+}
+
+func NewTwoFactorModel() *TwoFactorModel {
+	conn, err := config.DBConnection()
+	if err != nil {
+		panic(err)
+	}
+	return &TwoFactorModel{conn: conn}
+}
+
+// FindTOTP mengambil secret TOTP user yang sudah dibuka; nil jika user belum mendaftar
+func (p *TwoFactorModel) FindTOTP(userID int64) (*entities.UserTOTP, error) {
+	var totp entities.UserTOTP
+	var sealed string
+	var enabledAt sql.NullString
+	err := p.conn.QueryRow(
+		"SELECT user_id, secret, enabled_at, last_used_step FROM user_totp WHERE user_id = ?", userID).Scan(
+		&totp.UserID, &sealed, &enabledAt, &totp.LastUsedStep)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	totp.Secret, err = helpers.OpenSecret(sealed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unseal TOTP secret of user %d: %w", userID, err)
+	}
+	totp.Enabled = enabledAt.Valid
+	return &totp, nil
+}
+
+// SavePending menyimpan secret baru yang belum aktif; pendaftaran sebelumnya
+// yang belum dikonfirmasi diganti. Gagal (false) jika 2FA user sudah aktif.
+func (p *TwoFactorModel) SavePending(userID int64, secret []byte) (bool, error) {
+	sealed, err := helpers.SealSecret(secret)
+	if err != nil {
+		return false, err
+	}
+
+	now := wibNow().Format(dateTimeLayout)
+	result, err := p.conn.Exec(`
+        INSERT INTO user_totp (user_id, secret, created_at, enabled_at, last_used_step)
+        VALUES (?, ?, ?, NULL, 0)
+        ON DUPLICATE KEY UPDATE
+            secret = IF(enabled_at IS NULL, VALUES(secret), secret),
+            created_at = IF(enabled_at IS NULL, VALUES(created_at), created_at)`,
+		userID, sealed, now)
+	if err != nil {
+		return false, fmt.Errorf("failed to save TOTP secret: %w", err)
+	}
+	// 1 = baris baru, 2 = baris lama diganti, 0 = 2FA sudah aktif sehingga tidak berubah
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// Enable mengaktifkan 2FA dan mengganti kode pemulihan dalam satu transaksi
+func (p *TwoFactorModel) Enable(userID int64, step int64, recoveryCodeHashes []string) error {
+	tx, err := p.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(
+		"UPDATE user_totp SET enabled_at = ?, last_used_step = ? WHERE user_id = ? AND enabled_at IS NULL",
+		wibNow().Format(dateTimeLayout), step, userID)
+	if err != nil {
+		return fmt.Errorf("failed to enable two-factor: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return fmt.Errorf("no pending two-factor enrollment for user %d", userID)
+	}
+
+	if err := replaceRecoveryCodes(tx, userID, recoveryCodeHashes); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Disable menghapus secret dan kode pemulihan user
+func (p *TwoFactorModel) Disable(userID int64) (bool, error) {
+	tx, err := p.conn.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("DELETE FROM user_totp WHERE user_id = ?", userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to disable two-factor: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if _, err := tx.Exec("DELETE FROM user_recovery_codes WHERE user_id = ?", userID); err != nil {
+		return false, fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+	return affected > 0, tx.Commit()
+}
+
+// UseStep mencatat periode TOTP yang baru dipakai. Hasilnya false jika periode
+// tersebut (atau yang lebih baru) sudah pernah dipakai, sehingga kode tidak bisa diputar ulang.
+func (p *TwoFactorModel) UseStep(userID int64, step int64) (bool, error) {
+	result, err := p.conn.Exec(
+		"UPDATE user_totp SET last_used_step = ? WHERE user_id = ? AND last_used_step < ?",
+		step, userID, step)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// ReplaceRecoveryCodes membuang kode pemulihan lama dan menyimpan yang baru
+func (p *TwoFactorModel) ReplaceRecoveryCodes(userID int64, codeHashes []string) error {
+	tx, err := p.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := replaceRecoveryCodes(tx, userID, codeHashes); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func replaceRecoveryCodes(tx *sql.Tx, userID int64, codeHashes []string) error {
+	if _, err := tx.Exec("DELETE FROM user_recovery_codes WHERE user_id = ?", userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+	for _, hash := range codeHashes {
+		if _, err := tx.Exec("INSERT INTO user_recovery_codes (user_id, code_hash) VALUES (?, ?)", userID, hash); err != nil {
+			return fmt.Errorf("failed to save recovery code: %w", err)
+		}
+	}
+	return nil
+}
+
+// UseRecoveryCode menandai kode pemulihan sebagai terpakai; false jika tidak ada atau sudah dipakai
+func (p *TwoFactorModel) UseRecoveryCode(userID int64, codeHash string) (bool, error) {
+	result, err := p.conn.Exec(
+		"UPDATE user_recovery_codes SET used_at = ? WHERE user_id = ? AND code_hash = ? AND used_at IS NULL LIMIT 1",
+		wibNow().Format(dateTimeLayout), userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// RemainingRecoveryCodes menghitung kode pemulihan yang belum dipakai
+func (p *TwoFactorModel) RemainingRecoveryCodes(userID int64) (int, error) {
+	var remaining int
+	err := p.conn.QueryRow(
+		"SELECT COUNT(*) FROM user_recovery_codes WHERE user_id = ? AND used_at IS NULL", userID).Scan(&remaining)
+	return remaining, err
+}
+
+// RequiredForRole memeriksa apakah role memiliki permission yang diwajibkan memakai 2FA
+func (p *TwoFactorModel) RequiredForRole(roleID int64) (bool, error) {
+	var required bool
+	err := p.conn.QueryRow(`
+        SELECT EXISTS(
+            SELECT 1
+            FROM role_permissions rp
+            INNER JOIN permissions pm ON pm.id = rp.permission_id
+            INNER JOIN two_factor_policy tp ON tp.permission_name = pm.name
+            WHERE rp.role_id = ?)`, roleID).Scan(&required)
+	return required, err
+}
+
+// FindPolicy mengambil nama permission yang mewajibkan 2FA
+func (p *TwoFactorModel) FindPolicy() ([]string, error) {
+	rows, err := p.conn.Query("SELECT permission_name FROM two_factor_policy ORDER BY permission_name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	names := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+// SetPolicy mengganti daftar permission yang mewajibkan 2FA. Nama yang tidak ada
+// di tabel permissions dikembalikan sebagai unknown dan tidak ada yang disimpan.
+func (p *TwoFactorModel) SetPolicy(names []string) ([]string, error) {
+	unknown := []string{}
+	for _, name := range names {
+		var exists bool
+		if err := p.conn.QueryRow("SELECT EXISTS(SELECT 1 FROM permissions WHERE name = ?)", name).Scan(&exists); err != nil {
+			return nil, err
+		}
+		if !exists {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		return unknown, nil
+	}
+
+	tx, err := p.conn.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM two_factor_policy"); err != nil {
+		return nil, fmt.Errorf("failed to clear two-factor policy: %w", err)
+	}
+	if len(names) > 0 {
+		placeholders := strings.TrimSuffix(strings.Repeat("(?),", len(names)), ",")
+		args := make([]interface{}, len(names))
+		for i, name := range names {
+			args[i] = name
+		}
+		if _, err := tx.Exec("INSERT IGNORE INTO two_factor_policy (permission_name) VALUES "+placeholders, args...); err != nil {
+			return nil, fmt.Errorf("failed to save two-factor policy: %w", err)
+		}
+	}
+	return nil, tx.Commit()
+}
+
+// CreateChallenge menyimpan challenge login langkah kedua yang berlaku selama ttl
+func (p *TwoFactorModel) CreateChallenge(userID int64, tokenHash string, ttl time.Duration) error {
+	now := wibNow()
+	_, err := p.conn.Exec(`
+        INSERT INTO two_factor_challenges (token_hash, user_id, created_at, expires_at)
+        VALUES (?, ?, ?, ?)`,
+		tokenHash, userID, now.Format(dateTimeLayout), now.Add(ttl).Format(dateTimeLayout))
+	if err != nil {
+		return fmt.Errorf("failed to save two-factor challenge: %w", err)
+	}
+	return nil
+}
+
+// FindChallenge mengambil user pemilik challenge yang masih berlaku; 0 jika
+// challenge tidak ada, kedaluwarsa, sudah dipakai, atau terlalu banyak percobaan
+func (p *TwoFactorModel) FindChallenge(tokenHash string) (int64, error) {
+	var userID int64
+	err := p.conn.QueryRow(`
+        SELECT user_id FROM two_factor_challenges
+        WHERE token_hash = ? AND used_at IS NULL AND expires_at > ? AND attempts < ?`,
+		tokenHash, wibNow().Format(dateTimeLayout), MaxTwoFactorAttempts).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return userID, err
+}
+
+// FailChallenge menambah hitungan kode salah pada challenge
+func (p *TwoFactorModel) FailChallenge(tokenHash string) error {
+	_, err := p.conn.Exec("UPDATE two_factor_challenges SET attempts = attempts + 1 WHERE token_hash = ?", tokenHash)
+	return err
+}
+
+// ConsumeChallenge menandai challenge sudah dipakai; false jika request lain sudah memakainya
+func (p *TwoFactorModel) ConsumeChallenge(tokenHash string) (bool, error) {
+	result, err := p.conn.Exec(
+		"UPDATE two_factor_challenges SET used_at = ? WHERE token_hash = ? AND used_at IS NULL",
+		wibNow().Format(dateTimeLayout), tokenHash)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
@@ -3,6 +3,7 @@ package models
 import (
 	"backend/config"
 	"backend/entities"
+	"backend/helpers"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -27,87 +28,369 @@ func NewUserModel() *UserModel {
 }
 
 
-func (p *UserModel) Authenticate(email, password string) (*entities.User, error) {
-	user := &entities.User{}
-	// query := "SELECT id, name, email, password, role_id, instance_id FROM user WHERE email = ? AND password = ?"
-	query := "SELECT * FROM user WHERE email = ? AND password = ? AND deleted_at IS NULL"
+// ErrInvalidCredentials dikembalikan Authenticate jika email atau password salah
+var ErrInvalidCredentials = errors.New("invalid email or password")
 
-
-	log.Printf("Querying with email: %s and password: %s", email, password)
-
-	err := p.conn.QueryRow(query, email, password).Scan(
-		&user.Id, 
-		&user.Name, 
-		&user.NIP,
-		&user.Email, 
-		&user.Password, 
-		&user.Role_Id, 
-		&user.Instance_Id,
-		&user.Deleted_at,
-	)
+// Authenticate memverifikasi password di Go terhadap hash bcrypt di database.
+// Password lama yang masih plaintext (atau hash dengan cost lama) langsung
+// di-hash ulang setelah login berhasil.
+func (p *UserModel) Authenticate(email, password string) (*entities.UserDetail, error) {
+	var id int64
+	var stored string
+	// Akun dari LDAP/SSO tidak punya password lokal dan diperiksa oleh provider-nya
+	err := p.conn.QueryRow(
+		"SELECT id, password FROM user WHERE email = ? AND deleted_at IS NULL AND auth_provider = 'local'",
+		email).Scan(&id, &stored)
 	if err == sql.ErrNoRows {
-		return nil, errors.New("invalid email or password")
+		helpers.BurnPasswordCheck(password)
+		return nil, ErrInvalidCredentials
 	} else if err != nil {
 		log.Println("Database error:", err)
 		return nil, err
 	}
 
+	matches, needsRehash := helpers.VerifyPassword(stored, password)
+	if !matches {
+		return nil, ErrInvalidCredentials
+	}
+
+	if needsRehash {
+		if err := p.rehashPassword(id, stored, password); err != nil {
+			// Login tetap berhasil; rehash dicoba lagi pada login berikutnya
+			log.Printf("Failed to rehash password for user %d: %v", id, err)
+		}
+	}
+
+	user, err := scanUserDetail(p.conn.QueryRow(userDetailSelect+" WHERE u.id = ?", id))
+	if err != nil {
+		log.Println("Database error:", err)
+		return nil, err
+	}
+
 	log.Println("User authenticated successfully:", user.Email)
-	return user, nil
+	return &user, nil
+}
+
+// rehashPassword mengganti nilai password lama dengan hash baru, hanya jika nilai
+// di database belum diubah oleh request lain sejak dibaca
+func (p *UserModel) rehashPassword(id int64, previous, password string) error {
+	hash, err := helpers.HashPassword(password)
+	if err != nil {
+		return err
+	}
+	_, err = p.conn.Exec("UPDATE user SET password = ? WHERE id = ? AND password = ?", hash, id, previous)
+	return err
+}
+
+// CheckPassword mencocokkan password user yang sedang login, mis. sebelum mengganti password
+func (p *UserModel) CheckPassword(id int64, password string) (bool, error) {
+	var stored string
+	err := p.conn.QueryRow("SELECT password FROM user WHERE id = ? AND deleted_at IS NULL", id).Scan(&stored)
+	if err == sql.ErrNoRows {
+		helpers.BurnPasswordCheck(password)
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	matches, _ := helpers.VerifyPassword(stored, password)
+	return matches, nil
+}
+
+// PasswordReused memeriksa apakah password sama dengan password user saat ini atau
+// salah satu password sebelumnya sesuai batas history di policy
+func (p *UserModel) PasswordReused(id int64, password string) (bool, error) {
+	history := config.Get().PasswordPolicy.History
+	if history == 0 {
+		return false, nil
+	}
+
+	rows, err := p.conn.Query(`
+        (SELECT password FROM user WHERE id = ?)
+        UNION ALL
+        (SELECT password_hash FROM password_history WHERE user_id = ? ORDER BY id DESC LIMIT ?)`,
+		id, id, history-1)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var stored string
+		if err := rows.Scan(&stored); err != nil {
+			return false, err
+		}
+		if matches, _ := helpers.VerifyPassword(stored, password); matches {
+			return true, nil
+		}
+	}
+	return false, rows.Err()
+}
+
+// ChangePassword mengganti password user dan menaikkan versi token sehingga
+// access token lama ditolak
+func (p *UserModel) ChangePassword(id int64, password string) error {
+	hash, err := helpers.HashPassword(password)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	tx, err := p.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := recordPasswordChange(tx, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(
+		"UPDATE user SET password = ?, token_version = token_version + 1 WHERE id = ? AND deleted_at IS NULL",
+		hash, id); err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+	return tx.Commit()
 }
 
-func (p *UserModel) FindUserByID(id int64) (*entities.User, error) {
-    var user entities.User
-    query := `SELECT id, name, nip, email, password, role_id, instance_id 
-              FROM user WHERE id = ? AND deleted_at IS NULL`
-    err := p.conn.QueryRow(query, id).Scan(
+// ProvisionExternalUser membuat atau memperbarui user dari sumber akun eksternal
+// (LDAP/SSO) setiap kali login. User dicari dari identitas yang sudah tertaut, lalu
+// dari email. Akun yang sudah ada dengan email yang sama hanya ditautkan jika
+// linkExisting aktif atau admin mengizinkannya untuk akun tersebut; selain itu
+// login ditolak agar akun tidak diambil alih. Nama, NIP, role dan instansi hanya
+// mengikuti sumber yang membuat akun, sehingga akun lokal yang ditautkan dan user
+// yang login lewat LDAP maupun SSO tidak berubah-ubah.
+func (p *UserModel) ProvisionExternalUser(external entities.ExternalUser, roleID, instanceID int64, linkExisting bool) (*entities.UserDetail, error) {
+	if roleID == 0 || instanceID == 0 {
+		return nil, ErrExternalUserNotAllowed
+	}
+
+	tx, err := p.conn.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var id int64
+	var deleted bool
+	var origin, originID string
+	err = tx.QueryRow(`
+        SELECT u.id, u.deleted_at IS NOT NULL, u.auth_provider, COALESCE(u.external_id, '')
+        FROM user_identities ui
+        JOIN user u ON u.id = ui.user_id
+        WHERE ui.provider = ? AND ui.external_id = ? FOR UPDATE`,
+		external.Provider, external.Subject).Scan(&id, &deleted, &origin, &originID)
+	linked := err == nil
+	if err == sql.ErrNoRows {
+		var linkAllowed bool
+		err = tx.QueryRow(`
+            SELECT id, deleted_at IS NOT NULL, auth_provider, COALESCE(external_id, ''), external_link_allowed
+            FROM user WHERE email = ? ORDER BY deleted_at IS NULL DESC LIMIT 1 FOR UPDATE`,
+			external.Email).Scan(&id, &deleted, &origin, &originID, &linkAllowed)
+		if err == nil && !deleted && !linkExisting && !linkAllowed {
+			log.Printf("Refused %s login %s: user %d already uses email %s and is not allowed to be linked",
+				external.Provider, external.Subject, id, external.Email)
+			return nil, ErrExternalLinkNotAllowed
+		}
+	}
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	// User yang dihapus admin tidak dihidupkan kembali lewat login
+	if deleted {
+		return nil, ErrExternalUserNotAllowed
+	}
+
+	existing := id != 0
+	if !existing {
+		// Password kosong tidak pernah cocok sehingga akun ini tidak bisa login lokal
+		result, err := tx.Exec(`
+            INSERT INTO user (name, nip, email, password, role_id, instance_id, auth_provider, external_id)
+            VALUES (?, ?, ?, '', ?, ?, ?, ?)`,
+			external.Name, external.NIP, external.Email, roleID, instanceID, external.Provider, external.Subject)
+		if err != nil {
+			return nil, fmt.Errorf("failed to provision %s user: %w", external.Provider, err)
+		}
+		if id, err = result.LastInsertId(); err != nil {
+			return nil, err
+		}
+		log.Printf("Provisioned %s user %d (%s)", external.Provider, id, external.Email)
+	}
+
+	if !linked {
+		_, err := tx.Exec(
+			"INSERT INTO user_identities (user_id, provider, external_id, created_at) VALUES (?, ?, ?, ?)",
+			id, external.Provider, external.Subject, wibNow().Format(dateTimeLayout))
+		if err != nil {
+			return nil, fmt.Errorf("failed to link %s identity to user %d: %w", external.Provider, id, err)
+		}
+		if existing {
+			log.Printf("Linked %s login %s to existing %s user %d (%s)", external.Provider, external.Subject, origin, id, external.Email)
+		}
+	}
+
+	if existing && origin == external.Provider && originID == external.Subject {
+		// Sama seperti UpdateUserById, token_version dihitung sebelum role/instansi diganti
+		_, err := tx.Exec(`
+            UPDATE user
+            SET token_version = token_version + IF(role_id <> ? OR instance_id <> ?, 1, 0),
+                name = ?, nip = IF(? = 0, nip, ?), email = ?, role_id = ?, instance_id = ?
+            WHERE id = ?`,
+			roleID, instanceID,
+			external.Name, external.NIP, external.NIP, external.Email, roleID, instanceID, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update %s user %d: %w", external.Provider, id, err)
+		}
+	}
+
+	user, err := scanUserDetail(tx.QueryRow(userDetailSelect+" WHERE u.id = ?", id))
+	if err != nil {
+		return nil, err
+	}
+	return &user, tx.Commit()
+}
+
+// SetExternalLinkAllowed mengizinkan (atau melarang) akun yang sudah ada ditautkan ke
+// login LDAP/SSO dengan email yang sama. Hasilnya false jika user tidak ditemukan.
+func (p *UserModel) SetExternalLinkAllowed(userID int64, allowed bool) (bool, error) {
+	var exists bool
+	err := p.conn.QueryRow("SELECT EXISTS(SELECT 1 FROM user WHERE id = ? AND deleted_at IS NULL)", userID).Scan(&exists)
+	if err != nil || !exists {
+		return false, err
+	}
+	_, err = p.conn.Exec("UPDATE user SET external_link_allowed = ? WHERE id = ?", allowed, userID)
+	return err == nil, err
+}
+
+// recordPasswordChange dipanggil dalam transaksi sebelum password user diganti:
+// password lama masuk history (dipangkas sesuai policy) dan link reset yang
+// belum dipakai dibatalkan
+func recordPasswordChange(conn sqlExecer, userID int64) error {
+	now := wibNow().Format(dateTimeLayout)
+	// Password saat ini ikut dihitung dalam history, jadi yang disimpan history-1
+	keep := config.Get().PasswordPolicy.History - 1
+	if keep > 0 {
+		_, err := conn.Exec(`
+            INSERT INTO password_history (user_id, password_hash, created_at)
+            SELECT id, password, ? FROM user WHERE id = ? AND password <> ''`, now, userID)
+		if err != nil {
+			return fmt.Errorf("failed to save password history: %w", err)
+		}
+	} else {
+		keep = 0
+	}
+	// Subquery dibungkus sekali lagi karena MySQL tidak mengizinkan LIMIT langsung di IN
+	_, err := conn.Exec(`
+        DELETE FROM password_history
+        WHERE user_id = ? AND id NOT IN (
+            SELECT id FROM (
+                SELECT id FROM password_history WHERE user_id = ? ORDER BY id DESC LIMIT ?
+            ) AS kept)`, userID, userID, keep)
+	if err != nil {
+		return fmt.Errorf("failed to prune password history: %w", err)
+	}
+
+	if _, err := conn.Exec("UPDATE password_resets SET used_at = ? WHERE user_id = ? AND used_at IS NULL", now, userID); err != nil {
+		return fmt.Errorf("failed to cancel password resets: %w", err)
+	}
+	return nil
+}
+
+
+func (p *UserModel) FindUserByID(id int64) (*entities.UserDetail, error) {
+    query := userDetailSelect + " WHERE u.id = ? AND u.deleted_at IS NULL"
+    user, err := scanUserDetail(p.conn.QueryRow(query, id))
+    if err != nil {
+        return nil, err
+    }
+    return &user, nil
+}
+
+
+
+// userListSpec adalah kolom sort dan filter untuk list user
+var userListSpec = ListSpec{
+    Sortable: map[string]string{
+        "id":    "u.id",
+        "name":  "u.name",
+        "nip":   "u.nip",
+        "email": "u.email",
+    },
+    Filterable: map[string]string{
+        "role_id":     "u.role_id",
+        "instance_id": "u.instance_id",
+    },
+    Searchable:  []string{"u.name", "u.email"},
+    DefaultSort: "id",
+}
+
+// userDetailSelect mengambil user beserta nama role dan instansi dalam satu query
+const userDetailSelect = `
+    SELECT u.id, u.name, u.nip, u.email, u.role_id, u.instance_id, u.token_version,
+           u.auth_provider, COALESCE(r.name, ''), COALESCE(i.name, '')
+    FROM user u
+    LEFT JOIN role r ON r.id = u.role_id
+    LEFT JOIN instance i ON i.id = u.instance_id`
+
+type rowScanner interface {
+    Scan(dest ...interface{}) error
+}
+
+func scanUserDetail(row rowScanner) (entities.UserDetail, error) {
+    var user entities.UserDetail
+    err := row.Scan(
         &user.Id,
         &user.Name,
         &user.NIP,
         &user.Email,
-        &user.Password,
         &user.Role_Id,
         &user.Instance_Id,
+        &user.TokenVersion,
+        &user.AuthProvider,
+        &user.RoleName,
+        &user.InstanceName,
     )
-    if err != nil {
-        return nil, err
-    }
-    return &user, nil
+    return user, err
 }
 
+// UserListSpec dipakai controller untuk memvalidasi parameter list user
+func UserListSpec() ListSpec {
+    return userListSpec
+}
+
+func (p *UserModel) FindAllUsers(opts ListOptions) ([]entities.UserDetail, int, error) {
+    baseQuery := userDetailSelect + " WHERE u.deleted_at IS NULL"
+    query, countQuery, args, countArgs := buildListQuery(baseQuery, nil, userListSpec, opts)
+
+    var total int
+    if err := p.conn.QueryRow(countQuery, countArgs...).Scan(&total); err != nil {
+        return nil, 0, err
+    }
 
-func (p *UserModel) FindAllUsers() ([]entities.User, error) {
-    query := `SELECT id, name, nip, email, password, role_id, instance_id 
-              FROM user WHERE deleted_at IS NULL`
-    rows, err := p.conn.Query(query)
+    rows, err := p.conn.Query(query, args...)
     if err != nil {
-        return nil, err
+        return nil, 0, err
     }
     defer rows.Close()
 
-    var users []entities.User
+    users := []entities.UserDetail{}
     for rows.Next() {
-        var user entities.User
-        err := rows.Scan(
-            &user.Id,
-            &user.Name,
-            &user.NIP,
-            &user.Email,
-            &user.Password,
-            &user.Role_Id,
-            &user.Instance_Id,
-        )
+        user, err := scanUserDetail(rows)
         if err != nil {
-            return nil, err
+            return nil, 0, err
         }
         users = append(users, user)
     }
 
-    return users, nil
+    return users, total, rows.Err()
 }
 
 
 func (u *UserModel) AddUser(user entities.User) (entities.User, error) {
+    hash, err := helpers.HashPassword(user.Password)
+    if err != nil {
+        return entities.User{}, fmt.Errorf("failed to hash password: %w", err)
+    }
+
     query := `
         INSERT INTO user (name, nip, email, password, role_id, instance_id)
         VALUES (?, ?, ?, ?, ?, ?)`
@@ -116,7 +399,7 @@ func (u *UserModel) AddUser(user entities.User) (entities.User, error) {
 		user.Name, 
 		user.NIP, 
 		user.Email, 
-		user.Password, 
+		hash, 
 		user.Role_Id, 
 		user.Instance_Id)
 		
@@ -130,21 +413,62 @@ func (u *UserModel) AddUser(user entities.User) (entities.User, error) {
     }
 
     user.Id = lastInsertID
+    user.Password = ""
     return user, nil
 }
 
+// UpdateUserById mengubah data user. Versi token dinaikkan jika role atau instansi
+// berpindah, atau password diganti, sehingga token lama user ditolak JWTAuth.
+// token_version harus diisi paling awal karena MySQL mengevaluasi SET dari kiri
+// dan perbandingannya harus memakai nilai role/instansi yang lama.
 func (u *UserModel) UpdateUserById(id int64, user entities.User) (entities.User, error) {
+	// Password kosong berarti tidak diubah
+	if user.Password == "" {
+		query := `
+            UPDATE user
+            SET token_version = token_version + IF(role_id <> ? OR instance_id <> ?, 1, 0),
+                name = ?, nip = ?, email = ?, role_id = ?, instance_id = ?
+            WHERE id = ?`
+		_, err := u.conn.Exec(query, user.Role_Id, user.Instance_Id,
+			user.Name, user.NIP, user.Email, user.Role_Id, user.Instance_Id, id)
+		if err != nil {
+			return entities.User{}, err
+		}
+		user.Id = id
+		return user, nil
+	}
+
+	hash, err := helpers.HashPassword(user.Password)
+	if err != nil {
+		return entities.User{}, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	tx, err := u.conn.Begin()
+	if err != nil {
+		return entities.User{}, err
+	}
+	defer tx.Rollback()
+
+	if err := recordPasswordChange(tx, id); err != nil {
+		return entities.User{}, err
+	}
+
 	query := `
         UPDATE user
-        SET name = ?, nip = ?, email = ?, password = ?, role_id = ?, instance_id = ?
+        SET token_version = token_version + 1,
+            name = ?, nip = ?, email = ?, password = ?, role_id = ?, instance_id = ?
         WHERE id = ?`
 
-	_, err := u.conn.Exec(query, user.Name, user.NIP, user.Email, user.Password, user.Role_Id, user.Instance_Id, id)
+	_, err = tx.Exec(query, user.Name, user.NIP, user.Email, hash, user.Role_Id, user.Instance_Id, id)
 	if err != nil {
 		return entities.User{}, err
 	}
+	if err := tx.Commit(); err != nil {
+		return entities.User{}, err
+	}
 
 	user.Id = id
+	user.Password = ""
 	return user, nil
 }
 
@@ -168,7 +492,7 @@ func (m *UserModel) SoftDeleteUserById(id int) error {
     nowWIB := time.Now().In(loc).Format("2006-01-02 15:04:05")
 
     // Jalankan query untuk soft delete dengan waktu yang sudah dikonversi
-    query := "UPDATE user SET deleted_at = ? WHERE id = ?"
+    query := "UPDATE user SET deleted_at = ?, token_version = token_version + 1 WHERE id = ?"
     _, err = m.conn.Exec(query, nowWIB, id)
     return err
 }
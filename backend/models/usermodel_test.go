@@ -0,0 +1,97 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// benchRoundTrip mensimulasikan waktu bolak-balik satu query ke MySQL di jaringan lokal
+const benchRoundTrip = 200 * time.Microsecond
+
+// benchPageSize sama dengan batas maksimum satu halaman list
+const benchPageSize = 100
+
+// newBenchDB membuat koneksi sqlmock yang menerima query apa pun; urutan
+// expectation tetap diperiksa sehingga jumlah query ikut terhitung
+func newBenchDB(b *testing.B) (sqlmock.Sqlmock, *UserModel, *RoleModel, *InstanceModel) {
+	b.Helper()
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherFunc(
+		func(expectedSQL, actualSQL string) error { return nil })))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { db.Close() })
+	return mock, &UserModel{conn: db}, &RoleModel{conn: db}, &InstanceModel{conn: db}
+}
+
+func expectUserPage(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery("COUNT").WillDelayFor(benchRoundTrip).
+		WillReturnRows(sqlmock.NewRows([]string{"total"}).AddRow(benchPageSize))
+	rows := sqlmock.NewRows([]string{"id", "name", "nip", "email", "role_id", "instance_id",
+		"token_version", "auth_provider", "role", "instance"})
+	for i := 1; i <= benchPageSize; i++ {
+		rows.AddRow(i, "User", 1987654321, "user@example.go.id", 2, 3, 0, "local", "Editor", "Dinas Kominfo")
+	}
+	mock.ExpectQuery("SELECT").WillDelayFor(benchRoundTrip).WillReturnRows(rows)
+}
+
+// BenchmarkFindAllUsersJoined memuat satu halaman user dengan nama role dan
+// instansi dari userDetailSelect (dua query: count dan list)
+func BenchmarkFindAllUsersJoined(b *testing.B) {
+	mock, users, _, _ := newBenchDB(b)
+	opts := ListOptions{Limit: benchPageSize}
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		expectUserPage(mock)
+		b.StartTimer()
+
+		page, _, err := users.FindAllUsers(opts)
+		if err != nil {
+			b.Fatal(err)
+		}
+		for _, user := range page {
+			_, _ = user.RoleName, user.InstanceName
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		b.Fatal(err)
+	}
+}
+
+// BenchmarkFindAllUsersPerUserLookups meniru GetAllUsers sebelum join: setelah
+// list user, role dan instansi dicari satu per satu untuk setiap user
+func BenchmarkFindAllUsersPerUserLookups(b *testing.B) {
+	mock, users, roles, instances := newBenchDB(b)
+	opts := ListOptions{Limit: benchPageSize}
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		expectUserPage(mock)
+		for j := 0; j < benchPageSize; j++ {
+			mock.ExpectQuery("instance").WillDelayFor(benchRoundTrip).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(3, "Dinas Kominfo"))
+			mock.ExpectQuery("role").WillDelayFor(benchRoundTrip).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(2, "Editor"))
+		}
+		b.StartTimer()
+
+		page, _, err := users.FindAllUsers(opts)
+		if err != nil {
+			b.Fatal(err)
+		}
+		for _, user := range page {
+			if _, err := instances.FindInstanceById(user.Instance_Id); err != nil {
+				b.Fatal(err)
+			}
+			if _, err := roles.FindRoleById(user.Role_Id); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		b.Fatal(err)
+	}
+}
@@ -0,0 +1,327 @@
+package models
+
+import (
+	"backend/config"
+	"backend/entities"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Jumlah baris maksimal per INSERT saat flush
+const viewInsertBatchSize = 500
+
+// viewGroup adalah kunci buffer: satu konten pada satu tanggal
+type viewGroup struct {
+	contentID int64
+	date      string
+}
+
+// pendingViews adalah pembaca baru yang belum ditulis ke database
+type pendingViews struct {
+	instanceID int64
+	viewers    map[string]struct{}
+}
+
+// ViewModel menampung view unik di memori lalu menulisnya ke database secara batch,
+// sehingga membuka artikel tidak lagi memicu UPDATE baris content secara langsung.
+type ViewModel struct {
+	conn *sql.DB
+
+	mu      sync.Mutex
+	pending map[viewGroup]*pendingViews
+	// flushing berisi batch yang sedang ditulis, tetap dihitung sebagai view hidup
+	flushing map[viewGroup]*pendingViews
+	// seen berisi pembaca yang sudah ditulis ke database, agar tidak dibuffer ulang
+	seen map[viewGroup]map[string]struct{}
+
+	// flushMu mencegah dua flush berjalan bersamaan (ticker dan shutdown)
+	flushMu sync.Mutex
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+func NewViewModel() *ViewModel {
+	conn, err := config.DBConnection()
+	if err != nil {
+		panic(err)
+	}
+	return &ViewModel{
+		conn:    conn,
+		pending: map[viewGroup]*pendingViews{},
+		seen:    map[viewGroup]map[string]struct{}{},
+	}
+}
+
+// RecordView mencatat view unik seorang pembaca pada hari tertentu ke buffer.
+// Hasilnya true jika view ini baru (belum pernah tercatat hari itu oleh proses ini).
+func (p *ViewModel) RecordView(contentID, instanceID int64, viewerKey string, day time.Time) bool {
+	group := viewGroup{contentID: contentID, date: day.Format("2006-01-02")}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.seen[group][viewerKey]; ok {
+		return false
+	}
+	if views, ok := p.flushing[group]; ok {
+		if _, ok := views.viewers[viewerKey]; ok {
+			return false
+		}
+	}
+
+	views, ok := p.pending[group]
+	if !ok {
+		views = &pendingViews{instanceID: instanceID, viewers: map[string]struct{}{}}
+		p.pending[group] = views
+	}
+	if _, ok := views.viewers[viewerKey]; ok {
+		return false
+	}
+	views.viewers[viewerKey] = struct{}{}
+	return true
+}
+
+// PendingViews mengembalikan jumlah view yang masih di buffer untuk satu konten
+func (p *ViewModel) PendingViews(contentID int64) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	total := 0
+	for _, batch := range []map[viewGroup]*pendingViews{p.pending, p.flushing} {
+		for group, views := range batch {
+			if group.contentID == contentID {
+				total += len(views.viewers)
+			}
+		}
+	}
+	return total
+}
+
+// StartFlusher menulis buffer ke database setiap interval sampai StopFlusher dipanggil
+func (p *ViewModel) StartFlusher(interval time.Duration) {
+	p.stop = make(chan struct{})
+	p.done = make(chan struct{})
+
+	go func() {
+		defer close(p.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := p.Flush(); err != nil {
+					log.Printf("Failed to flush view counts: %v", err)
+				}
+			case <-p.stop:
+				return
+			}
+		}
+	}()
+}
+
+// StopFlusher menghentikan flush berkala lalu menulis sisa buffer (dipakai saat shutdown)
+func (p *ViewModel) StopFlusher() error {
+	if p.stop != nil {
+		close(p.stop)
+		<-p.done
+		p.stop = nil
+	}
+	return p.Flush()
+}
+
+// Flush menulis semua view di buffer ke database, satu transaksi per konten per tanggal.
+// Grup yang gagal dikembalikan ke buffer untuk dicoba lagi pada flush berikutnya.
+func (p *ViewModel) Flush() error {
+	p.flushMu.Lock()
+	defer p.flushMu.Unlock()
+
+	p.mu.Lock()
+	batch := p.pending
+	p.pending = map[viewGroup]*pendingViews{}
+	p.flushing = batch
+	p.mu.Unlock()
+
+	var firstErr error
+	for group, views := range batch {
+		if err := p.flushGroup(group, views); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			p.requeue(group, views)
+			continue
+		}
+		p.markSeen(group, views)
+	}
+
+	p.pruneSeen()
+	return firstErr
+}
+
+func (p *ViewModel) flushGroup(group viewGroup, views *pendingViews) error {
+	if len(views.viewers) == 0 {
+		return nil
+	}
+
+	viewers := make([]string, 0, len(views.viewers))
+	for viewer := range views.viewers {
+		viewers = append(viewers, viewer)
+	}
+
+	tx, err := p.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// INSERT IGNORE membuang pembaca yang sudah tercatat (misalnya oleh server lain),
+	// sehingga RowsAffected adalah jumlah view unik yang benar-benar baru
+	var inserted int64
+	for start := 0; start < len(viewers); start += viewInsertBatchSize {
+		end := start + viewInsertBatchSize
+		if end > len(viewers) {
+			end = len(viewers)
+		}
+
+		placeholders := make([]string, 0, end-start)
+		args := make([]interface{}, 0, (end-start)*3)
+		for _, viewer := range viewers[start:end] {
+			placeholders = append(placeholders, "(?, ?, ?)")
+			args = append(args, group.contentID, viewer, group.date)
+		}
+
+		result, err := tx.Exec(
+			"INSERT IGNORE INTO content_views (content_id, viewer_key, view_date) VALUES "+strings.Join(placeholders, ", "),
+			args...)
+		if err != nil {
+			return fmt.Errorf("failed to record views: %w", err)
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to record views: %w", err)
+		}
+		inserted += affected
+	}
+	if inserted == 0 {
+		return tx.Commit()
+	}
+
+	_, err = tx.Exec(`
+        INSERT INTO content_view_daily (content_id, instance_id, view_date, views)
+        VALUES (?, ?, ?, ?)
+        ON DUPLICATE KEY UPDATE views = views + VALUES(views)`,
+		group.contentID, views.instanceID, group.date, inserted)
+	if err != nil {
+		return fmt.Errorf("failed to update daily views: %w", err)
+	}
+
+	_, err = tx.Exec("UPDATE content SET view_count = view_count + ? WHERE id = ?", inserted, group.contentID)
+	if err != nil {
+		return fmt.Errorf("failed to update view count: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit views: %w", err)
+	}
+	return nil
+}
+
+func (p *ViewModel) requeue(group viewGroup, views *pendingViews) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.flushing, group)
+
+	current, ok := p.pending[group]
+	if !ok {
+		p.pending[group] = views
+		return
+	}
+	for viewer := range views.viewers {
+		current.viewers[viewer] = struct{}{}
+	}
+}
+
+func (p *ViewModel) markSeen(group viewGroup, views *pendingViews) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.flushing, group)
+
+	seen, ok := p.seen[group]
+	if !ok {
+		seen = map[string]struct{}{}
+		p.seen[group] = seen
+	}
+	for viewer := range views.viewers {
+		seen[viewer] = struct{}{}
+	}
+}
+
+// pruneSeen membuang daftar pembaca dari hari-hari sebelumnya
+func (p *ViewModel) pruneSeen() {
+	yesterday := time.Now().AddDate(0, 0, -1).Format("2006-01-02")
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for group := range p.seen {
+		if group.date < yesterday {
+			delete(p.seen, group)
+		}
+	}
+}
+
+// DailyViewsByContent mengembalikan view unik per hari untuk satu konten, termasuk hari tanpa view
+func (p *ViewModel) DailyViewsByContent(contentID int64, from, to time.Time) ([]entities.DailyViews, error) {
+	query := `
+        SELECT view_date, views
+        FROM content_view_daily
+        WHERE content_id = ? AND view_date BETWEEN ? AND ?`
+	return p.dailySeries(query, contentID, from, to)
+}
+
+// DailyViewsByInstance mengembalikan total view unik per hari untuk semua konten milik satu instansi
+func (p *ViewModel) DailyViewsByInstance(instanceID int64, from, to time.Time) ([]entities.DailyViews, error) {
+	query := `
+        SELECT view_date, SUM(views)
+        FROM content_view_daily
+        WHERE instance_id = ? AND view_date BETWEEN ? AND ?
+        GROUP BY view_date`
+	return p.dailySeries(query, instanceID, from, to)
+}
+
+func (p *ViewModel) dailySeries(query string, id int64, from, to time.Time) ([]entities.DailyViews, error) {
+	rows, err := p.conn.Query(query, id, from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch daily views: %w", err)
+	}
+	defer rows.Close()
+
+	viewsByDate := map[string]int{}
+	for rows.Next() {
+		var date string
+		var views int
+		if err := rows.Scan(&date, &views); err != nil {
+			return nil, fmt.Errorf("failed to scan daily views: %w", err)
+		}
+		// Driver mysql mengembalikan DATE sebagai "2006-01-02" (tanpa parseTime)
+		viewsByDate[date[:10]] = views
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Isi tanggal yang kosong dengan 0 agar deret waktunya utuh
+	series := []entities.DailyViews{}
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		date := day.Format("2006-01-02")
+		series = append(series, entities.DailyViews{Date: date, Views: viewsByDate[date]})
+	}
+	return series, nil
+}
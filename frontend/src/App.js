@@ -10,6 +10,8 @@ import Footer from './component/Footer';
 import './styles/index.css'; // Pastikan ini adalah file yang benar
 import Edit from './pages/Edit';
 import Login from './pages/Login';
+import ForgotPassword from './pages/ForgotPassword';
+import ResetPassword from './pages/ResetPassword';
 import Profile from './pages/Profile';
 import AddContent from './pages/AddContent';
 import AddSubheading from './pages/AddSubheading';
@@ -73,6 +75,8 @@ const App = () => {
                             } />
                             <Route path="/edit/:id" element={<Edit />} />
                             <Route path="/login" element={<Login />} />
+                            <Route path="/forgot-password" element={<ForgotPassword />} />
+                            <Route path="/reset-password" element={<ResetPassword />} />
                             <Route path="/profile" element={<Profile />} />
                             <Route path="/addcontent" element={<AddContent />} />
                             <Route path="/editrejectcontent/:id" element={<EditRejectContent />} />
@@ -0,0 +1,37 @@
+import React from "react";
+
+const pageSizes = [10, 20, 50, 100];
+
+// ListPager menampilkan navigasi halaman untuk hasil usePagedList. Pilihan jumlah
+// baris hanya tampil jika onLimitChange diberikan.
+const ListPager = ({ list, limit, onLimitChange }) => {
+  const busy = list.loading;
+
+  return (
+    <div className="pagination">
+      <button onClick={list.firstPage} disabled={!list.hasPrevious || busy}>
+        &lt;&lt;
+      </button>
+      <button onClick={list.previousPage} disabled={!list.hasPrevious || busy}>
+        &lt;
+      </button>
+      <span className="pagination-info">
+        Page {list.page} of {list.pageCount} ({list.total} total)
+      </span>
+      <button onClick={list.nextPage} disabled={!list.hasNext || busy}>
+        &gt;
+      </button>
+      {onLimitChange && (
+        <select className="pagination-size" value={limit} onChange={(e) => onLimitChange(Number(e.target.value))}>
+          {pageSizes.map((size) => (
+            <option key={size} value={size}>
+              {size} / page
+            </option>
+          ))}
+        </select>
+      )}
+    </div>
+  );
+};
+
+export default ListPager;
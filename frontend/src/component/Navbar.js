@@ -4,6 +4,7 @@ import logo from "../assets/logojogja.png";
 import profile from "../assets/user.png";
 import search from "../assets/search.png";
 import ModalLogout from "./ModalLogout";
+import { logoutSession } from "../services/ApiService";
 import Sidebar2 from "./Sidebar2";
 import "font-awesome/css/font-awesome.min.css";
 
@@ -114,6 +115,9 @@ const Navbar = ({ searchTerm, setSearchTerm }) => {
 
   const handleLogout = async () => {
     try {
+      // Cabut sesi login di server sebelum beralih ke token guest
+      await logoutSession();
+
       // Fetch guest token
       const response = await fetch("/api/guest", {
         method: "GET",
@@ -0,0 +1,85 @@
+import { useCallback, useEffect, useState } from 'react';
+
+const firstPosition = (queryKey) => ({ queryKey, cursors: [null], index: 0 });
+
+// usePagedList memuat endpoint list satu halaman per request. Halaman berikutnya
+// diambil lewat next_cursor dari envelope; cursor halaman yang sudah dibuka
+// disimpan supaya bisa kembali ke halaman sebelumnya. Mengganti limit, sort atau
+// filter kembali ke halaman pertama. fetchPage harus stabil (mis. apiService.getDrafts
+// atau dibungkus useCallback) agar tidak memuat ulang di setiap render.
+const usePagedList = (fetchPage, { limit = 10, sort = '', filters = {}, enabled = true } = {}) => {
+  const queryKey = JSON.stringify({ limit, sort, filters });
+  const [position, setPosition] = useState(() => firstPosition(queryKey));
+  const [page, setPage] = useState({ items: [], total: 0, nextCursor: null });
+  const [loading, setLoading] = useState(false);
+  const [reloadCount, setReloadCount] = useState(0);
+
+  const current = position.queryKey === queryKey ? position : firstPosition(queryKey);
+  const cursor = current.cursors[current.index];
+  const index = current.index;
+
+  useEffect(() => {
+    if (!enabled) return undefined;
+
+    let cancelled = false;
+    const query = JSON.parse(queryKey);
+    const params = { ...query.filters, limit: query.limit };
+    if (query.sort) params.sort = query.sort;
+    if (cursor) params.cursor = cursor;
+
+    setLoading(true);
+    fetchPage(params)
+      .then(({ data }) => {
+        if (cancelled) return;
+        const items = data.items || [];
+        // Item terakhir di halaman ini baru dihapus/disetujui: mundur satu halaman
+        if (items.length === 0 && index > 0) {
+          setPosition((previous) => ({ ...previous, queryKey, index: index - 1 }));
+          return;
+        }
+        setPage({ items, total: data.total || 0, nextCursor: data.next_cursor || null });
+      })
+      .catch((error) => console.error('Failed to fetch list:', error))
+      .finally(() => {
+        if (!cancelled) setLoading(false);
+      });
+
+    return () => {
+      cancelled = true;
+    };
+  }, [fetchPage, queryKey, cursor, index, reloadCount, enabled]);
+
+  const nextPage = useCallback(() => {
+    if (!page.nextCursor) return;
+    setPosition({
+      queryKey,
+      cursors: [...current.cursors.slice(0, index + 1), page.nextCursor],
+      index: index + 1,
+    });
+  }, [current.cursors, index, page.nextCursor, queryKey]);
+
+  const previousPage = useCallback(() => {
+    if (index > 0) setPosition({ ...current, index: index - 1 });
+  }, [current, index]);
+
+  const firstPage = useCallback(() => setPosition(firstPosition(queryKey)), [queryKey]);
+
+  // reload memuat ulang halaman yang sedang dibuka, mis. setelah item dihapus
+  const reload = useCallback(() => setReloadCount((count) => count + 1), []);
+
+  return {
+    items: page.items,
+    total: page.total,
+    page: index + 1,
+    pageCount: Math.max(1, Math.ceil(page.total / limit)),
+    hasPrevious: index > 0,
+    hasNext: Boolean(page.nextCursor) && position.queryKey === queryKey,
+    loading,
+    nextPage,
+    previousPage,
+    firstPage,
+    reload,
+  };
+};
+
+export default usePagedList;
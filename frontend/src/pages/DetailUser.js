@@ -20,7 +20,6 @@ const DetailUser = () => {
   });
   const [histories, setHistories] = useState([]);
   const [showHistory, setShowHistory] = useState(false);
-  const [contents, setContents] = useState([]);
   const { id } = useParams();
   const [passwordVisible, setPasswordVisible] = useState(false);
   const [currentUser, setCurrentUser] = useState(null);
@@ -52,11 +51,10 @@ const DetailUser = () => {
 
     const fetchData = async () => {
       try {
-        const [userResponse, rolesResponse, instancesResponse, contentsResponse] = await Promise.all([
+        const [userResponse, rolesResponse, instancesResponse] = await Promise.all([
           apiService.getUserById(id),
           apiService.getRoles(),
           apiService.getInstances(),
-          apiService.getNotRejectedContents(),
         ]);
 
         setUser(userResponse.data);
@@ -65,13 +63,12 @@ const DetailUser = () => {
           name: userResponse.data.name,
           nip: userResponse.data.nip,
           email: userResponse.data.email,
-          password: userResponse.data.password,
+          password: "", // Password tidak dikirim backend; kosong berarti tidak diubah
           role_id: userResponse.data.role_id,
           instance_id: userResponse.data.instance_id,
         });
         setRoles(rolesResponse.data);
         setInstances(instancesResponse.data);
-        setContents(contentsResponse.data || []);
       } catch (error) {
         console.error("Failed to fetch data:", error);
       }
@@ -165,18 +162,13 @@ const DetailUser = () => {
   };
 
   useEffect(() => {
-    if (contents && histories) {
-      const contentMap = contents.reduce((map, content) => {
-        map[content.id] = content.title;
-        return map;
-      }, {});
-
+    if (histories) {
+      // Judul konten sudah disertakan backend di setiap riwayat
       const newMergedData = histories.map((history) => {
-        const title = contentMap[history.content_id];
         return {
           type: "history",
           id: history.id,
-          title: title || `Unknown Content (ID: ${history.content_id})`,
+          title: history.content_title || `Unknown Content (ID: ${history.content_id})`,
           created_at: history.edited_at,
           action: history.action || "Edit",
         };
@@ -185,7 +177,7 @@ const DetailUser = () => {
       newMergedData.sort((a, b) => new Date(b.created_at) - new Date(a.created_at));
       setMergedData(newMergedData);
     }
-  }, [contents, histories]);
+  }, [histories]);
 
   const indexOfLastItem = currentPage * itemsPerPage;
   const indexOfFirstItem = indexOfLastItem - itemsPerPage;
@@ -197,7 +189,7 @@ const DetailUser = () => {
 
   const totalPages = Math.ceil(mergedData.length / itemsPerPage);
 
-  if (!user || !contents || !histories) {
+  if (!user || !histories) {
     return <div>Loading...</div>;
   }
 
@@ -313,7 +305,7 @@ const DetailUser = () => {
                     name="password"
                     value={formData.password}
                     onChange={handleInputChange}
-                    required
+                    placeholder="Kosongkan jika tidak diubah"
                   />
                   <button
                     type="button"
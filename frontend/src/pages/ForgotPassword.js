@@ -0,0 +1,69 @@
+import React, { useState } from 'react';
+import { useNavigate } from 'react-router-dom';
+import logo from '../assets/logojogja.png';
+
+function ForgotPassword() {
+    const [email, setEmail] = useState('');
+    const [sent, setSent] = useState(false);
+    const navigate = useNavigate();
+
+    const handleSubmit = async (e) => {
+        e.preventDefault();
+
+        try {
+            const response = await fetch('http://localhost:3000/api/password/forgot', {
+                method: 'POST',
+                headers: {
+                    'Content-Type': 'application/json',
+                },
+                body: JSON.stringify({ email }),
+            });
+
+            if (!response.ok) {
+                const errorData = await response.json();
+                alert(`Request failed: ${errorData.error || 'Unknown error'}`);
+                return;
+            }
+            setSent(true);
+        } catch (error) {
+            console.error("Password reset request failed:", error);
+            alert('Failed to connect to server');
+        }
+    };
+
+    return (
+        <div className="login-page">
+            <div className="login-left">
+                <img src={logo} alt="Logo Pemda DIY" className="logo" />
+                <h2>Lupa Password</h2>
+                {sent ? (
+                    <>
+                        <p>Jika email tersebut terdaftar, link untuk membuat password baru sudah dikirim. Periksa kotak masuk Anda.</p>
+                        <div className="form-row">
+                            <button type="button" onClick={() => navigate('/login')} className="btn btn-blue">Kembali ke Login</button>
+                        </div>
+                    </>
+                ) : (
+                    <form onSubmit={handleSubmit}>
+                        <p>Masukkan email akun Anda untuk menerima link reset password.</p>
+                        <div className="form-row">
+                            <input
+                                type="email"
+                                value={email}
+                                onChange={(e) => setEmail(e.target.value)}
+                                placeholder="Email"
+                                required
+                            />
+                        </div>
+                        <div className="form-row">
+                            <button type="submit" className="btn btn-blue">Kirim Link</button>
+                            <button type="button" onClick={() => navigate('/login')} className="btn btn-gray">Cancel</button>
+                        </div>
+                    </form>
+                )}
+            </div>
+        </div>
+    );
+}
+
+export default ForgotPassword;
@@ -1,13 +1,16 @@
 import React, { useEffect, useState } from "react";
 import { Link, useNavigate } from "react-router-dom";
 import { FaPlus } from "react-icons/fa";
+import ListPager from "../component/ListPager";
+import usePagedList from "../hooks/usePagedList";
 import { apiService } from '../services/ApiService';
 
 function Home() {
-  const [contents, setContents] = useState([]);
   const [user, setUser] = useState(null);
   const [isButtonVisible, setIsButtonVisible] = useState(false);
   const navigate = useNavigate();
+  const canViewContents = Boolean(user?.permissions?.includes("view_active_content"));
+  const contents = usePagedList(apiService.getActiveContents, { limit: 20, sort: "title", enabled: canViewContents });
 
   useEffect(() => {
 
@@ -41,13 +44,8 @@ function Home() {
           permissions: userData.permissions || [],
         };
         
+        // Konten aktif dimuat per halaman oleh usePagedList setelah izin diketahui
         setUser(userWithPermissions);
-
-        // Fetch active contents if user has permission
-        if (userWithPermissions.permissions?.includes("view_active_content")) {
-          const { data: contentData } = await apiService.getActiveContents();
-          setContents(contentData);
-        }
       } catch (error) {
         console.error("Error in initialization:", error);
         // Error handling is now managed by axios interceptor
@@ -139,9 +137,10 @@ function Home() {
           <hr className="gradient-hr-sub" />
         </h2>
 
-        {user?.permissions?.includes("view_active_content") && (
+        {canViewContents && (
+  <>
   <ul className="numbered">
-    {contents
+    {contents.items
       .filter((content) => content.id < 90 || content.id > 98)
       .map((content) => (
         <li key={content.id}>
@@ -149,6 +148,8 @@ function Home() {
         </li>
       ))}
   </ul>
+  <ListPager list={contents} />
+  </>
 )}
         {user?.permissions?.includes("create_content") && (
           <button className="button-create-content" onClick={handleAddClick}>
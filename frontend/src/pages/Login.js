@@ -1,5 +1,5 @@
-import React, { useState } from 'react';
-import { useNavigate } from 'react-router-dom';
+import React, { useEffect, useRef, useState } from 'react';
+import { useNavigate, useSearchParams } from 'react-router-dom';
 import { FaEye, FaEyeSlash } from "react-icons/fa";
 import logo from '../assets/logojogja.png'; // Pastikan path sesuai
 
@@ -8,6 +8,77 @@ function Login() {
     const [password, setPassword] = useState('');
     const navigate = useNavigate();
     const [passwordVisible, setPasswordVisible] = useState(false);
+    // Langkah kedua login untuk akun dengan 2FA
+    const [challenge, setChallenge] = useState(null);
+    const [setupRequired, setSetupRequired] = useState(false);
+    const [enrollment, setEnrollment] = useState(null);
+    const [code, setCode] = useState('');
+    const [useRecoveryCode, setUseRecoveryCode] = useState(false);
+    // Login SSO: tombol ditampilkan jika backend mengaktifkan OpenID Connect
+    const [sso, setSso] = useState(null);
+    const [searchParams, setSearchParams] = useSearchParams();
+    const ssoHandled = useRef(false);
+
+    const postJSON = (path, body) => fetch(`http://localhost:3000/api${path}`, {
+        method: 'POST',
+        headers: {
+            'Content-Type': 'application/json',
+        },
+        body: JSON.stringify(body),
+    });
+
+    const resetTwoFactor = () => {
+        setChallenge(null);
+        setSetupRequired(false);
+        setEnrollment(null);
+        setCode('');
+        setUseRecoveryCode(false);
+    };
+
+    const handleTwoFactorSubmit = async (e) => {
+        e.preventDefault();
+
+        try {
+            let response;
+            if (setupRequired) {
+                response = await postJSON('/login/2fa/enable', { challenge, code });
+            } else if (useRecoveryCode) {
+                response = await postJSON('/login/2fa', { challenge, recovery_code: code });
+            } else {
+                response = await postJSON('/login/2fa', { challenge, code });
+            }
+
+            if (!response.ok) {
+                const errorData = await response.json();
+                alert(`Verification failed: ${errorData.error || 'Invalid code'}`);
+                if (response.status !== 401 || (errorData.error || '').includes('log in again')) {
+                    resetTwoFactor();
+                }
+                return;
+            }
+
+            const data = await response.json();
+            if (data.recovery_codes) {
+                alert(`Simpan kode pemulihan berikut di tempat aman. Setiap kode hanya bisa dipakai sekali:\n\n${data.recovery_codes.join('\n')}`);
+            }
+            resetTwoFactor();
+            await finishLogin(data);
+        } catch (error) {
+            console.error("Two-factor verification failed:", error);
+            alert('Failed to connect to server');
+        }
+    };
+
+    const startEnrollment = async (loginChallenge) => {
+        const response = await postJSON('/login/2fa/setup', { challenge: loginChallenge });
+        if (!response.ok) {
+            const errorData = await response.json();
+            alert(`Two-factor setup failed: ${errorData.error || 'Unknown error'}`);
+            resetTwoFactor();
+            return;
+        }
+        setEnrollment(await response.json());
+    };
 
     const handleSubmit = async (e) => {
         e.preventDefault();
@@ -22,81 +93,141 @@ function Login() {
                 body: JSON.stringify({ email, password }),
             });
 
-            if (!response.ok) {
-                const errorData = await response.json();
-                alert(`Login failed: ${errorData.message || 'Invalid login credentials'}`);
-                return; // Jika login gagal, hentikan proses lebih lanjut
+            await handleLoginResponse(response);
+        } catch (error) {
+            console.error("Login failed:", error);
+            alert('Failed to connect to server');
+        }
+    };
+
+    // Jawaban login dengan password maupun SSO diproses dengan cara yang sama
+    const handleLoginResponse = async (response) => {
+        if (!response.ok) {
+            const errorData = await response.json();
+            alert(`Login failed: ${errorData.message || errorData.error || 'Invalid login credentials'}`);
+            return; // Jika login gagal, hentikan proses lebih lanjut
+        }
+
+        const data = await response.json();
+
+        // Login benar tetapi akun memakai (atau wajib memakai) 2FA
+        if (data.two_factor_required) {
+            setChallenge(data.challenge);
+            setSetupRequired(data.two_factor_setup_required);
+            if (data.two_factor_setup_required) {
+                await startEnrollment(data.challenge);
             }
+            return;
+        }
 
-            const data = await response.json();
-            console.log(data); // Debug log
-
-            if (data.token) {
-                // Simpan token ke localStorage
-                localStorage.setItem('token', data.token);
-
-                // Mendapatkan roles menggunakan token
-                const getRoles = await fetch('http://localhost:3000/api/roles', {
-                    method: 'GET',
-                    headers: {
-                        'Authorization': `Bearer ${data.token}`,
-                    },
-                });
-
-                if (!getRoles.ok) {
-                    alert('Failed to fetch roles');
-                    return; // Jika gagal mengambil roles, hentikan proses
-                }
+        await finishLogin(data);
+    };
 
-                const roles = await getRoles.json();
-                console.log(roles); // Debug log roles
+    const ssoErrors = {
+        denied: 'Login SSO dibatalkan',
+        expired: 'Login SSO kedaluwarsa, silakan coba lagi',
+        invalid_state: 'Login SSO tidak valid, silakan coba lagi',
+        email_not_verified: 'Email akun SSO Anda belum terverifikasi',
+        not_allowed: 'Akun SSO Anda tidak memiliki akses ke wiki',
+        link_not_allowed: 'Email ini sudah dipakai akun wiki lain; minta admin mengizinkan penautan akun',
+        unavailable: 'Layanan SSO sedang tidak tersedia',
+    };
 
-                // Ambil role yang sesuai berdasarkan role_id
-                const role = roles.find(role => role.id === data.role_id);
-                if (!role) {
-                    alert("Invalid role assignment");
-                    return; // Jika role tidak ditemukan, hentikan proses
-                }
+    useEffect(() => {
+        fetch('http://localhost:3000/api/sso')
+            .then((response) => response.json())
+            .then((data) => setSso(data.oidc && data.oidc.enabled ? data.oidc : null))
+            .catch(() => setSso(null));
+    }, []);
 
-                // Mendapatkan permissions berdasarkan role_id
-                const permissionsResponse = await fetch(`http://localhost:3000/api/role_permissions`, {
-                    method: 'GET',
-                    headers: {
-                        'Authorization': `Bearer ${data.token}`,
-                    },
-                });
-
-                if (!permissionsResponse.ok) {
-                    const errorData = await permissionsResponse.json();
-                    alert(`Failed to fetch permissions: ${errorData.message || 'Unknown error'}`);
-                    return; // Jika gagal mengambil permissions, hentikan proses
-                }
+    // Backend mengarahkan kembali ke /login?sso_code=... setelah login SSO berhasil
+    useEffect(() => {
+        const ssoCode = searchParams.get('sso_code');
+        const ssoError = searchParams.get('sso_error');
+        if ((!ssoCode && !ssoError) || ssoHandled.current) {
+            return;
+        }
+        ssoHandled.current = true;
+        // Kode hanya bisa dipakai sekali; hapus dari URL agar tidak terkirim ulang
+        setSearchParams({}, { replace: true });
 
-                const permissions = await permissionsResponse.json();
-                console.log(permissions); // Debug log permissions
-
-                const user = {
-                    id: data.id,
-                    name: data.name,
-                    email: data.email,
-                    user_instance_id: data.instance_id,
-                    role_id: data.role_id,
-                    role: role.name,
-                    permissions: permissions, // Menyimpan permissions di dalam data user
-                };
-
-                // Simpan user data termasuk permissions ke localStorage
-                localStorage.setItem('user', JSON.stringify(user));
-                window.dispatchEvent(new Event('storage'));
-
-                // Redirect ke halaman utama setelah login sukses
-                navigate('/');
-            } else {
-                alert('Invalid login credentials');
+        if (ssoError) {
+            alert(ssoErrors[ssoError] || 'Login SSO gagal');
+            return;
+        }
+        postJSON('/sso/exchange', { code: ssoCode })
+            .then(handleLoginResponse)
+            .catch((error) => {
+                console.error("SSO login failed:", error);
+                alert('Failed to connect to server');
+            });
+        // eslint-disable-next-line react-hooks/exhaustive-deps
+    }, []);
+
+    const finishLogin = async (data) => {
+        if (data.token) {
+            // Simpan token ke localStorage
+            localStorage.setItem('token', data.token);
+            localStorage.setItem('refresh_token', data.refresh_token);
+
+            // Mendapatkan roles menggunakan token
+            const getRoles = await fetch('http://localhost:3000/api/roles', {
+                method: 'GET',
+                headers: {
+                    'Authorization': `Bearer ${data.token}`,
+                },
+            });
+
+            if (!getRoles.ok) {
+                alert('Failed to fetch roles');
+                return; // Jika gagal mengambil roles, hentikan proses
             }
-        } catch (error) {
-            console.error("Login failed:", error);
-            alert('Failed to connect to server');
+
+            const roles = await getRoles.json();
+            console.log(roles); // Debug log roles
+
+            // Ambil role yang sesuai berdasarkan role_id
+            const role = roles.find(role => role.id === data.role_id);
+            if (!role) {
+                alert("Invalid role assignment");
+                return; // Jika role tidak ditemukan, hentikan proses
+            }
+
+            // Mendapatkan permissions berdasarkan role_id
+            const permissionsResponse = await fetch(`http://localhost:3000/api/role_permissions`, {
+                method: 'GET',
+                headers: {
+                    'Authorization': `Bearer ${data.token}`,
+                },
+            });
+
+            if (!permissionsResponse.ok) {
+                const errorData = await permissionsResponse.json();
+                alert(`Failed to fetch permissions: ${errorData.message || 'Unknown error'}`);
+                return; // Jika gagal mengambil permissions, hentikan proses
+            }
+
+            const permissions = await permissionsResponse.json();
+            console.log(permissions); // Debug log permissions
+
+            const user = {
+                id: data.id,
+                name: data.name,
+                email: data.email,
+                user_instance_id: data.instance_id,
+                role_id: data.role_id,
+                role: role.name,
+                permissions: permissions, // Menyimpan permissions di dalam data user
+            };
+
+            // Simpan user data termasuk permissions ke localStorage
+            localStorage.setItem('user', JSON.stringify(user));
+            window.dispatchEvent(new Event('storage'));
+
+            // Redirect ke halaman utama setelah login sukses
+            navigate('/');
+        } else {
+            alert('Invalid login credentials');
         }
     };
 
@@ -107,13 +238,57 @@ function Login() {
                 <img src={logo} alt="Logo Pemda DIY" className="logo" />
                 <h2>Login Account</h2>
                 <p>Silahkan Login Untuk Mengakses Web</p>
+                {challenge ? (
+                <form onSubmit={handleTwoFactorSubmit}>
+                    {setupRequired ? (
+                        <div className="form-row">
+                            <p>Akun Anda wajib memakai verifikasi dua langkah. Tambahkan akun ke aplikasi authenticator dengan kode berikut, lalu masukkan kode 6 digit yang muncul.</p>
+                            {enrollment && (
+                                <>
+                                    <p><strong>{enrollment.secret}</strong></p>
+                                    <p style={{ wordBreak: 'break-all', fontSize: '0.8em' }}>{enrollment.provisioning_uri}</p>
+                                </>
+                            )}
+                        </div>
+                    ) : (
+                        <p>{useRecoveryCode ? 'Masukkan salah satu kode pemulihan Anda.' : 'Masukkan kode 6 digit dari aplikasi authenticator.'}</p>
+                    )}
+                    <div className="form-row">
+                        <input
+                            type="text"
+                            value={code}
+                            onChange={(e) => setCode(e.target.value)}
+                            placeholder={useRecoveryCode ? 'Kode pemulihan' : 'Kode verifikasi'}
+                            autoComplete="one-time-code"
+                            required
+                        />
+                    </div>
+                    <div className="form-row">
+                        <button type="submit" className="btn btn-blue">Verifikasi</button>
+                        <button
+                            type="button"
+                            onClick={resetTwoFactor}
+                            className="btn btn-gray"
+                        >
+                            Cancel
+                        </button>
+                    </div>
+                    {!setupRequired && (
+                        <div className="help-links">
+                            <a href="#recovery" onClick={(e) => { e.preventDefault(); setUseRecoveryCode(!useRecoveryCode); setCode(''); }}>
+                                {useRecoveryCode ? 'Pakai kode authenticator' : 'Pakai kode pemulihan'}
+                            </a>
+                        </div>
+                    )}
+                </form>
+                ) : (
                 <form onSubmit={handleSubmit}>
                     <div className="form-row">
                         <input
-                            type="email"
+                            type="text"
                             value={email}
                             onChange={(e) => setEmail(e.target.value)}
-                            placeholder="Email"
+                            placeholder="Email atau NIP"
                             required
                         />
                     </div>
@@ -144,11 +319,24 @@ function Login() {
                             Cancel
                         </button>
                     </div>
+                    {sso && (
+                        <div className="form-row">
+                            <button
+                                type="button"
+                                onClick={() => { window.location.href = 'http://localhost:3000/api/sso/oidc/login'; }}
+                                className="btn btn-blue"
+                            >
+                                Login dengan {sso.name}
+                            </button>
+                        </div>
+                    )}
                     <div className="help-links">
+                        <a href="/forgot-password" onClick={(e) => { e.preventDefault(); navigate('/forgot-password'); }}>Lupa password?</a>
                         <a href="https://api.whatsapp.com/send/?phone=6282133576291&text=Hello&type=phone_number&app_absent=0">FAQ</a>
                         <a href="https://api.whatsapp.com/send/?phone=6282133576291&text=Hello&type=phone_number&app_absent=0">Helpdesk</a>
                     </div>
                 </form>
+                )}
             </div>
             <div className="login-right">
                 <br></br>
@@ -3,12 +3,16 @@ import { Link, useNavigate } from "react-router-dom";
 import { FaInfoCircle, FaCheck, FaTimes } from "react-icons/fa";
 import ApprovalCard from "../component/ApprovalCard";
 import RejectPopup from "../component/RejectPopup"; // Assuming you have a RejectPopup component
+import ListPager from "../component/ListPager";
+import usePagedList from "../hooks/usePagedList";
 import { apiService } from "../services/ApiService"; // Import apiService
 
 const ManageContent = () => {
-  const [contents, setContents] = useState([]);
-  const [currentPage, setCurrentPage] = useState(1);
-  const itemsPerPage = 10;
+  const [limit, setLimit] = useState(10);
+  const [sort, setSort] = useState("-id");
+  const [searchInput, setSearchInput] = useState("");
+  const [filters, setFilters] = useState({ q: "", instance_id: "" });
+  const [instances, setInstances] = useState([]);
   const navigate = useNavigate();
   const [user, setUser] = useState(null);
   const [isApprovalOpen, setIsApprovalOpen] = useState(false);
@@ -18,6 +22,10 @@ const ManageContent = () => {
   const [rejectReason, setRejectReason] = useState("");
   const [selectedContentId, setSelectedContentId] = useState(null);
 
+  // Filter kosong tidak dikirim supaya server tidak memfilter nilai ""
+  const activeFilters = Object.fromEntries(Object.entries(filters).filter(([, value]) => value !== ""));
+  const contents = usePagedList(apiService.getDrafts, { limit, sort, filters: activeFilters });
+
   useEffect(() => {
     const storedUser = JSON.parse(localStorage.getItem("user"));
     const token = localStorage.getItem("token");
@@ -41,19 +49,17 @@ const ManageContent = () => {
 
     fetchUserData();
 
-    const fetchContents = async () => {
-      try {
-        const response = await apiService.getDrafts();
-        const sortedData = response.data.sort((a, b) => b.id - a.id);
-        setContents(sortedData);
-      } catch (error) {
-        console.error("Error fetching contents:", error);
-      }
-    };
-
-    fetchContents();
+    apiService
+      .getInstances()
+      .then((res) => setInstances(res.data))
+      .catch((error) => console.error("Error fetching instances:", error));
   }, []);
 
+  const handleSearchSubmit = (e) => {
+    e.preventDefault();
+    setFilters({ ...filters, q: searchInput.trim() });
+  };
+
   const handleApprove = async (id) => {
     if (!user?.permissions?.includes("approve_content")) {
       alert("You don't have permission to approve content.");
@@ -116,8 +122,7 @@ const ManageContent = () => {
           return;
         }
 
-        setContents((prevContents) => prevContents.filter((content) => content.id !== selectedContentId));
-        setCurrentPage(1);
+        contents.reload();
         setIsRejectPopupOpen(false);
         setRejectReason("");
       } else {
@@ -167,8 +172,7 @@ const ManageContent = () => {
           return;
         }
 
-        setContents((prevContents) => prevContents.filter((content) => content.id !== id));
-        setCurrentPage(1);
+        contents.reload();
       } else {
         alert("Failed to approve content");
         console.error("Failed to approve content:", response.statusText);
@@ -179,13 +183,6 @@ const ManageContent = () => {
     }
   };
 
-  const handlePageChange = (page) => {
-    setCurrentPage(page);
-  };
-
-  const totalPages = Math.ceil(contents.length / itemsPerPage);
-  const currentContents = contents.slice((currentPage - 1) * itemsPerPage, currentPage * itemsPerPage);
-
   return (
     <div className="main-container">
       <div className="table-container">
@@ -194,6 +191,28 @@ const ManageContent = () => {
           <h1 className="manage-content-h1">Manage Content</h1>
           <p className="manage-content-p">Manage, optimize, and distribute your content easily to achieve maximum results.</p>
         </div>
+        <div className="list-toolbar">
+          <form onSubmit={handleSearchSubmit}>
+            <input type="search" placeholder="Search title or tag" value={searchInput} onChange={(e) => setSearchInput(e.target.value)} />
+            <button type="submit" className="btn btn-blue">
+              Search
+            </button>
+          </form>
+          <select value={filters.instance_id} onChange={(e) => setFilters({ ...filters, instance_id: e.target.value })}>
+            <option value="">All Instances</option>
+            {instances.map((instance) => (
+              <option key={instance.id} value={instance.id}>
+                {instance.name}
+              </option>
+            ))}
+          </select>
+          <select value={sort} onChange={(e) => setSort(e.target.value)}>
+            <option value="-id">Newest</option>
+            <option value="id">Oldest</option>
+            <option value="title">Title (A-Z)</option>
+            <option value="-updated_at">Recently Updated</option>
+          </select>
+        </div>
         <table className="table-manage">
           <thead className="thead-manage">
             <tr>
@@ -205,7 +224,7 @@ const ManageContent = () => {
             </tr>
           </thead>
           <tbody>
-            {currentContents.map((content) => (
+            {contents.items.map((content) => (
               <tr key={content.id}>
                 <td>{content.title}</td>
                 <td>{content.author_name}</td>
@@ -234,25 +253,7 @@ const ManageContent = () => {
             ))}
           </tbody>
         </table>
-        <div className="pagination">
-          <button onClick={() => handlePageChange(1)} disabled={currentPage === 1}>
-            &lt;&lt;
-          </button>
-          <button onClick={() => handlePageChange(currentPage - 1)} disabled={currentPage === 1}>
-            &lt;
-          </button>
-          {Array.from({ length: totalPages }, (_, index) => (
-            <button key={index + 1} onClick={() => handlePageChange(index + 1)} className={currentPage === index + 1 ? "active" : ""}>
-              {index + 1}
-            </button>
-          ))}
-          <button onClick={() => handlePageChange(currentPage + 1)} disabled={currentPage === totalPages}>
-            &gt;
-          </button>
-          <button onClick={() => handlePageChange(totalPages)} disabled={currentPage === totalPages}>
-            &gt;&gt;
-          </button>
-        </div>
+        <ListPager list={contents} limit={limit} onLimitChange={setLimit} />
       </div>
       <ApprovalCard
         isOpen={isApprovalOpen}
@@ -3,11 +3,12 @@ import { Link } from "react-router-dom";
 import { FaEye, FaEyeSlash, FaInfoCircle, FaTrash } from "react-icons/fa";
 import DeleteUserCard from "../component/DeleteUserCard"; // Import DeleteUserCard component
 import AddUserCard from "../component/AddUserCard"; // Import AddUserCard component
+import ListPager from "../component/ListPager";
+import usePagedList from "../hooks/usePagedList";
 import { apiService } from "../services/ApiService"; // Import apiService
 
 const ManageUser = () => {
   const [user, setUser] = useState(null);
-  const [users, setUsers] = useState([]);
   const [roles, setRoles] = useState([]);
   const [instances, setInstances] = useState([]);
   const [formData, setFormData] = useState({
@@ -19,16 +20,17 @@ const ManageUser = () => {
     instance_id: "",
   });
   const [passwordVisible, setPasswordVisible] = useState(false);
-  const [currentPage, setCurrentPage] = useState(1);
+  const [limit, setLimit] = useState(10);
+  const [sort, setSort] = useState("name");
+  const [searchInput, setSearchInput] = useState("");
+  const [filters, setFilters] = useState({ q: "", role_id: "", instance_id: "" });
   const [deleteUserModal, setDeleteUserModal] = useState({ isOpen: false, userId: null, userName: "" });
   const [addUserModal, setAddUserModal] = useState({ isOpen: false, userData: {} });
-  const itemsPerPage = 10;
 
-  const fetchUsers = useCallback(() => {
-    apiService.getAllUsers()
-      .then((res) => setUsers(res.data))
-      .catch((err) => console.error("Failed to fetch users:", err));
-  }, []);
+  // Filter kosong tidak dikirim supaya server tidak memfilter nilai ""
+  const activeFilters = Object.fromEntries(Object.entries(filters).filter(([, value]) => value !== ""));
+  const users = usePagedList(apiService.getAllUsers, { limit, sort, filters: activeFilters });
+  const fetchUsers = users.reload;
 
   const fetchRoles = useCallback(() => {
     apiService.getRoles()
@@ -64,10 +66,19 @@ const ManageUser = () => {
     };
 
     fetchUserData();
-    fetchUsers();
     fetchRoles();
     fetchInstances();
-  }, [fetchUsers, fetchRoles, fetchInstances]);
+  }, [fetchRoles, fetchInstances]);
+
+  const handleFilterChange = (e) => {
+    const { name, value } = e.target;
+    setFilters({ ...filters, [name]: value });
+  };
+
+  const handleSearchSubmit = (e) => {
+    e.preventDefault();
+    setFilters({ ...filters, q: searchInput.trim() });
+  };
 
   const handleInputChange = (e) => {
     const { name, value } = e.target;
@@ -174,16 +185,6 @@ const ManageUser = () => {
     );
   };
 
-  const indexOfLastItem = currentPage * itemsPerPage;
-  const indexOfFirstItem = indexOfLastItem - itemsPerPage;
-  const currentUsers = users.slice(indexOfFirstItem, indexOfLastItem);
-
-  const handlePageChange = (pageNumber) => {
-    setCurrentPage(pageNumber);
-  };
-
-  const totalPages = Math.ceil(users.length / itemsPerPage);
-
   return (
     <div className="main-container">
       <div className="table-container">
@@ -194,6 +195,36 @@ const ManageUser = () => {
           ]}
         />
         <div className="text text-gradient">Manage User</div>
+        <div className="list-toolbar">
+          <form onSubmit={handleSearchSubmit}>
+            <input type="search" placeholder="Search name or email" value={searchInput} onChange={(e) => setSearchInput(e.target.value)} />
+            <button type="submit" className="btn btn-blue">
+              Search
+            </button>
+          </form>
+          <select name="role_id" value={filters.role_id} onChange={handleFilterChange}>
+            <option value="">All Roles</option>
+            {roles.map((role) => (
+              <option key={role.id} value={role.id}>
+                {role.name}
+              </option>
+            ))}
+          </select>
+          <select name="instance_id" value={filters.instance_id} onChange={handleFilterChange}>
+            <option value="">All Instances</option>
+            {instances.map((instance) => (
+              <option key={instance.id} value={instance.id}>
+                {instance.name}
+              </option>
+            ))}
+          </select>
+          <select value={sort} onChange={(e) => setSort(e.target.value)}>
+            <option value="name">Name (A-Z)</option>
+            <option value="-name">Name (Z-A)</option>
+            <option value="-id">Newest</option>
+            <option value="id">Oldest</option>
+          </select>
+        </div>
         <table className="manageuser">
           <thead className="theaduser">
             <tr>
@@ -204,53 +235,34 @@ const ManageUser = () => {
             </tr>
           </thead>
           <tbody>
-            {Array.isArray(currentUsers) &&
-              currentUsers.map((userItem) => (
-                <tr key={userItem.id}>
-                  <td>{userItem.name}</td>
-                  <td>{userItem.role_name}</td>
-                  <td>{userItem.instance}</td>
+            {users.items.map((userItem) => (
+              <tr key={userItem.id}>
+                <td>{userItem.name}</td>
+                <td>{userItem.role_name}</td>
+                <td>{userItem.instance}</td>
+                <td>
+                  <Link to={`/detail/${userItem.id}`}>
+                    <button className="green-button" style={{ display: "flex", alignItems: "center" }}>
+                      <FaInfoCircle style={{ marginRight: "5px", paddingBottom: "3px" }} /> Detail
+                    </button>
+                  </Link>
+                </td>
+                {user?.permissions?.includes("delete_user") && (
                   <td>
-                    <Link to={`/detail/${userItem.id}`}>
-                      <button className="green-button" style={{ display: "flex", alignItems: "center" }}>
-                        <FaInfoCircle style={{ marginRight: "5px", paddingBottom: "3px" }} /> Detail
-                      </button>
-                    </Link>
+                    <button
+                      className="btn btn-red"
+                      onClick={() => openDeleteModal(userItem.id, userItem.name)}
+                      style={{ display: "flex", alignItems: "center" }}
+                    >
+                      <FaTrash style={{ marginRight: "5px", paddingBottom: "3px" }} /> Delete
+                    </button>
                   </td>
-                  {user?.permissions?.includes("delete_user") && (
-                    <td>
-                      <button
-                        className="btn btn-red"
-                        onClick={() => openDeleteModal(userItem.id, userItem.name)}
-                        style={{ display: "flex", alignItems: "center" }}
-                      >
-                        <FaTrash style={{ marginRight: "5px", paddingBottom: "3px" }} /> Delete
-                      </button>
-                    </td>
-                  )}
-                </tr>
-              ))}
+                )}
+              </tr>
+            ))}
           </tbody>
         </table>
-        <div className="pagination">
-          <button onClick={() => handlePageChange(1)} disabled={currentPage === 1}>
-            &lt;&lt;
-          </button>
-          <button onClick={() => handlePageChange(currentPage - 1)} disabled={currentPage === 1}>
-            &lt;
-          </button>
-          {Array.from({ length: totalPages }, (_, index) => (
-            <button key={index + 1} onClick={() => handlePageChange(index + 1)} className={currentPage === index + 1 ? "active" : ""}>
-              {index + 1}
-            </button>
-          ))}
-          <button onClick={() => handlePageChange(currentPage + 1)} disabled={currentPage === totalPages}>
-            &gt;
-          </button>
-          <button onClick={() => handlePageChange(totalPages)} disabled={currentPage === totalPages}>
-            &gt;&gt;
-          </button>
-        </div>
+        <ListPager list={users} limit={limit} onLimitChange={setLimit} />
       </div>
 
       {user?.permissions?.includes("create_user") && (
@@ -1,12 +1,14 @@
 import React, { useEffect, useState } from 'react';
 import { useNavigate, Link } from 'react-router-dom';
 import ModalLogout from '../component/ModalLogout';
-import { apiService } from '../services/ApiService';
+import { apiService, logoutSession } from '../services/ApiService';
 
 const Profile = () => {
     const navigate = useNavigate();
     const [user, setUser] = useState(null);
     const [isModalOpen, setIsModalOpen] = useState(false); // Status untuk modal logout
+    const [passwordForm, setPasswordForm] = useState({ current_password: '', new_password: '', confirm_password: '' });
+    const [passwordProblems, setPasswordProblems] = useState([]);
 
     useEffect(() => {
         const fetchUserData = async () => {
@@ -36,6 +38,9 @@ const Profile = () => {
 
     const handleLogout = async () => {
         try {
+            // Cabut sesi login di server sebelum beralih ke token guest
+            await logoutSession();
+
             // Fetch guest token
             const response = await apiService.getGuestToken();
             const guestData = response.data;
@@ -66,6 +71,29 @@ const Profile = () => {
         }
     };
 
+    // Ganti password sendiri; sesi di perangkat lain ikut diakhiri oleh server
+    const handleChangePassword = async (e) => {
+        e.preventDefault();
+        if (passwordForm.new_password !== passwordForm.confirm_password) {
+            setPasswordProblems(['Konfirmasi password tidak sama']);
+            return;
+        }
+
+        try {
+            const response = await apiService.changePassword({
+                current_password: passwordForm.current_password,
+                new_password: passwordForm.new_password,
+            });
+            localStorage.setItem('token', response.data.token);
+            setPasswordForm({ current_password: '', new_password: '', confirm_password: '' });
+            setPasswordProblems([]);
+            alert('Password berhasil diganti');
+        } catch (error) {
+            const data = error.response?.data;
+            setPasswordProblems(data?.problems || [data?.error || 'Gagal mengganti password']);
+        }
+    };
+
     const Breadcrumbs = ({ paths }) => {
         return (
             <nav>
@@ -124,6 +152,46 @@ const Profile = () => {
                         </tr>
                     </tbody>
                 </table>
+
+                <div className="text text-gradient">Ganti Password</div>
+                <form onSubmit={handleChangePassword} className="change-password-form">
+                    <div className="form-row">
+                        <input
+                            type="password"
+                            value={passwordForm.current_password}
+                            onChange={(e) => setPasswordForm({ ...passwordForm, current_password: e.target.value })}
+                            placeholder="Password saat ini"
+                            required
+                        />
+                    </div>
+                    <div className="form-row">
+                        <input
+                            type="password"
+                            value={passwordForm.new_password}
+                            onChange={(e) => setPasswordForm({ ...passwordForm, new_password: e.target.value })}
+                            placeholder="Password baru"
+                            required
+                        />
+                    </div>
+                    <div className="form-row">
+                        <input
+                            type="password"
+                            value={passwordForm.confirm_password}
+                            onChange={(e) => setPasswordForm({ ...passwordForm, confirm_password: e.target.value })}
+                            placeholder="Ulangi password baru"
+                            required
+                        />
+                    </div>
+                    {passwordProblems.length > 0 && (
+                        <ul className="error-list">
+                            {passwordProblems.map((problem) => (
+                                <li key={problem}>{problem}</li>
+                            ))}
+                        </ul>
+                    )}
+                    <button type="submit" className="btn btn-blue">Simpan Password</button>
+                </form>
+
                 <button onClick={handleLogoutConfirmation} className="btn btn-red">Logout</button>
 
                 {/* Modal Logout */}
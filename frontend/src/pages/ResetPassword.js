@@ -0,0 +1,80 @@
+import React, { useState } from 'react';
+import { useNavigate, useSearchParams } from 'react-router-dom';
+import logo from '../assets/logojogja.png';
+
+function ResetPassword() {
+    const [searchParams] = useSearchParams();
+    const [password, setPassword] = useState('');
+    const [confirmPassword, setConfirmPassword] = useState('');
+    const navigate = useNavigate();
+    const token = searchParams.get('token');
+
+    const handleSubmit = async (e) => {
+        e.preventDefault();
+
+        if (password !== confirmPassword) {
+            alert('Konfirmasi password tidak sama');
+            return;
+        }
+
+        try {
+            const response = await fetch('http://localhost:3000/api/password/reset', {
+                method: 'POST',
+                headers: {
+                    'Content-Type': 'application/json',
+                },
+                body: JSON.stringify({ token, password }),
+            });
+
+            const data = await response.json();
+            if (!response.ok) {
+                alert(`Reset failed: ${data.error || 'Unknown error'}`);
+                return;
+            }
+
+            alert('Password berhasil diganti. Silakan login dengan password baru.');
+            navigate('/login');
+        } catch (error) {
+            console.error("Password reset failed:", error);
+            alert('Failed to connect to server');
+        }
+    };
+
+    return (
+        <div className="login-page">
+            <div className="login-left">
+                <img src={logo} alt="Logo Pemda DIY" className="logo" />
+                <h2>Buat Password Baru</h2>
+                {token ? (
+                    <form onSubmit={handleSubmit}>
+                        <div className="form-row">
+                            <input
+                                type="password"
+                                value={password}
+                                onChange={(e) => setPassword(e.target.value)}
+                                placeholder="Password baru"
+                                required
+                            />
+                        </div>
+                        <div className="form-row">
+                            <input
+                                type="password"
+                                value={confirmPassword}
+                                onChange={(e) => setConfirmPassword(e.target.value)}
+                                placeholder="Ulangi password baru"
+                                required
+                            />
+                        </div>
+                        <div className="form-row">
+                            <button type="submit" className="btn btn-blue">Simpan</button>
+                        </div>
+                    </form>
+                ) : (
+                    <p>Link reset password tidak valid.</p>
+                )}
+            </div>
+        </div>
+    );
+}
+
+export default ResetPassword;
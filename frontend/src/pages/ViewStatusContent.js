@@ -1,12 +1,14 @@
-import React, { useState, useEffect } from 'react';
+import React, { useState, useCallback } from 'react';
 import { Link, useNavigate } from 'react-router-dom';
 import { FaPencilAlt } from "react-icons/fa";
+import ListPager from '../component/ListPager';
+import usePagedList from '../hooks/usePagedList';
 import { apiService } from '../services/ApiService';
 
 const ViewStatusContent = () => {
-    const [contents, setContents] = useState([]);
-    const [currentPage, setCurrentPage] = useState(1);
-    const itemsPerPage = 10;
+    const [limit, setLimit] = useState(10);
+    const [sort, setSort] = useState('-id');
+    const [status, setStatus] = useState('');
     const navigate = useNavigate();
     const [user, setUser] = useState(() => {
         try {
@@ -16,21 +18,14 @@ const ViewStatusContent = () => {
         }
     });
 
-    useEffect(() => {
-        const fetchContents = async () => {
-            try {
-                const response = await apiService.getUserContents(user.id);
-                const sortedData = response.data.sort((a, b) => b.id - a.id);
-                setContents(sortedData);
-            } catch (error) {
-                console.error('Error fetching contents:', error);
-            }
-        };
-
-        if (user?.id) {
-            fetchContents();
-        }
-    }, [user, navigate]);
+    const userId = user?.id;
+    const fetchUserContents = useCallback((params) => apiService.getUserContents(userId, params), [userId]);
+    const contents = usePagedList(fetchUserContents, {
+        limit,
+        sort,
+        filters: status ? { status } : {},
+        enabled: Boolean(userId),
+    });
 
     const Breadcrumbs = ({ paths }) => {
         return (
@@ -51,19 +46,6 @@ const ViewStatusContent = () => {
         );
     };
 
-    // Calculate the current contents to display based on the current page
-    const indexOfLastItem = currentPage * itemsPerPage;
-    const indexOfFirstItem = indexOfLastItem - itemsPerPage;
-    const currentContents = contents.slice(indexOfFirstItem, indexOfLastItem);
-
-    // Handle page change
-    const handlePageChange = (pageNumber) => {
-        setCurrentPage(pageNumber);
-    };
-
-    // Calculate total pages
-    const totalPages = Math.ceil(contents.length / itemsPerPage);
-
     return (
         <div className="main-container">
             <div className="table-container">
@@ -77,6 +59,20 @@ const ViewStatusContent = () => {
                     <h1 className="view-status-content-h1">View Status Content</h1>
                     <p className="view-status-content-p">Tracking Progress with View Status Content</p>
                 </div>
+                <div className="list-toolbar">
+                    <select value={status} onChange={(e) => setStatus(e.target.value)}>
+                        <option value="">All Status</option>
+                        <option value="pending">Pending</option>
+                        <option value="approved">Approved</option>
+                        <option value="rejected">Rejected</option>
+                    </select>
+                    <select value={sort} onChange={(e) => setSort(e.target.value)}>
+                        <option value="-id">Newest</option>
+                        <option value="id">Oldest</option>
+                        <option value="-updated_at">Recently Updated</option>
+                        <option value="title">Title (A-Z)</option>
+                    </select>
+                </div>
                 <table className="view-status">
                     <thead className="thead-status">
                         <tr>
@@ -89,7 +85,7 @@ const ViewStatusContent = () => {
                         </tr>
                     </thead>
                     <tbody>
-                        {currentContents.map(content => (
+                        {contents.items.map(content => (
                             <tr key={content.id}>
                                 <td>{content.title}</td>
                                 <td>{content.created_at}</td>
@@ -107,29 +103,7 @@ const ViewStatusContent = () => {
                         ))}
                     </tbody>
                 </table>
-                <div className="pagination">
-                    <button onClick={() => handlePageChange(1)} disabled={currentPage === 1}>
-                        &lt;&lt;
-                    </button>
-                    <button onClick={() => handlePageChange(currentPage - 1)} disabled={currentPage === 1}>
-                        &lt;
-                    </button>
-                    {Array.from({ length: totalPages }, (_, index) => (
-                        <button
-                            key={index + 1}
-                            onClick={() => handlePageChange(index + 1)}
-                            className={currentPage === index + 1 ? "active" : ""}
-                        >
-                            {index + 1}
-                        </button>
-                    ))}
-                    <button onClick={() => handlePageChange(currentPage + 1)} disabled={currentPage === totalPages}>
-                        &gt;
-                    </button>
-                    <button onClick={() => handlePageChange(totalPages)} disabled={currentPage === totalPages}>
-                        &gt;&gt;
-                    </button>
-                </div>
+                <ListPager list={contents} limit={limit} onLimitChange={setLimit} />
             </div>
         </div>
     );
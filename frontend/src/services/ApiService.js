@@ -11,6 +11,49 @@ const api = axios.create({
 
 let isAlertShown = false; 
 
+// Satu proses refresh dipakai bersama oleh semua request yang gagal bersamaan,
+// karena refresh token hanya bisa dipakai sekali
+let refreshPromise = null;
+
+const refreshAccessToken = () => {
+  if (!refreshPromise) {
+    const refreshToken = localStorage.getItem('refresh_token');
+    refreshPromise = axios
+      .post(`${api.defaults.baseURL}/token/refresh`, { refresh_token: refreshToken })
+      .then((response) => {
+        const { token, refresh_token, role_id, permissions } = response.data;
+        localStorage.setItem('token', token);
+        localStorage.setItem('refresh_token', refresh_token);
+
+        // Permission bisa berubah sejak login
+        const user = JSON.parse(localStorage.getItem('user') || '{}');
+        localStorage.setItem('user', JSON.stringify({ ...user, role_id, permissions }));
+        return token;
+      })
+      .catch((error) => {
+        localStorage.removeItem('refresh_token');
+        throw error;
+      })
+      .finally(() => {
+        refreshPromise = null;
+      });
+  }
+  return refreshPromise;
+};
+
+// logoutSession mencabut sesi di server lalu membersihkan token lokal
+export const logoutSession = async () => {
+  const refreshToken = localStorage.getItem('refresh_token');
+  localStorage.removeItem('refresh_token');
+  if (refreshToken) {
+    try {
+      await axios.post(`${api.defaults.baseURL}/logout`, { refresh_token: refreshToken });
+    } catch (error) {
+      console.error('Failed to revoke session:', error);
+    }
+  }
+};
+
 const showSessionExpiredModal = () => {
   return new Promise((resolve) => {
     const isFirstVisit = localStorage.getItem('isFirstVisit');
@@ -43,6 +86,17 @@ api.interceptors.response.use(
     
     if (error.response?.status === 401 && !originalRequest._retry) {
       originalRequest._retry = true;
+
+      // Access token kedaluwarsa: coba perpanjang sesi dengan refresh token dulu
+      if (localStorage.getItem('refresh_token')) {
+        try {
+          const token = await refreshAccessToken();
+          originalRequest.headers.Authorization = `Bearer ${token}`;
+          return api(originalRequest);
+        } catch (refreshError) {
+          console.error('Failed to refresh session:', refreshError);
+        }
+      }
       
       // Show alert message before redirect
       if (!isAlertShown) {
@@ -106,24 +160,39 @@ export const apiService = {
   getGuestToken: () => api.get('/guest'),
   decodeToken: (encrypted_token) => api.post('/decode', { encrypted_token }),
   login: (credentials) => api.post('/login', credentials),
+  refreshToken: (refresh_token) => api.post('/token/refresh', { refresh_token }),
+  logout: (refresh_token) => api.post('/logout', { refresh_token }),
+  getSessions: () => api.get('/sessions'),
+  revokeSession: (id) => api.delete(`/sessions/${id}`),
+  revokeOtherSessions: () => api.post('/sessions/revoke-others'),
+  getUserSessions: (userId) => api.get(`/user/${userId}/sessions`),
+  revokeUserSessions: (userId) => api.delete(`/user/${userId}/sessions`),
+  getTwoFactorStatus: () => api.get('/2fa'),
+  setupTwoFactor: () => api.post('/2fa/setup'),
+  enableTwoFactor: (code) => api.post('/2fa/enable', { code }),
+  disableTwoFactor: (data) => api.post('/2fa/disable', data),
+  regenerateRecoveryCodes: (data) => api.post('/2fa/recovery-codes', data),
+  getPasswordPolicy: () => api.get('/password/policy'),
+  changePassword: (data) => api.post('/password/change', data),
+  resetUserTwoFactor: (userId) => api.delete(`/user/${userId}/2fa`),
   
   // Content related
-  getActiveContents: () => api.get('/active'),
+  getActiveContents: (params) => api.get('/active', { params }),
   getContentById: (id) => api.get(`/content/${id}`),
   createContent: (data) => api.post('/content/add', data),
   editContent: (id, data) => api.put(`/content/edit/${id}`, data),
   deleteContent: (id) => api.put(`/content/delete/${id}`),
   searchContent: (params) => api.get('/content', { params }),
-  getUserContents: (userId) => api.get(`/contents/user/${userId}`),
+  getUserContents: (userId, params) => api.get(`/contents/user/${userId}`, { params }),
   
   // Additional endpoints from main.go
-  getNotRejectedContents: () => api.get('/notReject'),
-  getDrafts: () => api.get('/draft'),
+  getNotRejectedContents: (params) => api.get('/notReject', { params }),
+  getDrafts: (params) => api.get('/draft', { params }),
   createSubheading: (id, data) => api.post(`/subheading/add/${id}`, data),
   deleteSubheading: (id) => api.delete(`/subheading/delete/${id}`),
   getInstances: () => api.get('/instances'),
   getUserById: (id) => api.get(`/user/${id}`),
-  getAllUsers: () => api.get('/users'),
+  getAllUsers: (params) => api.get('/users', { params }),
   getRoles: () => api.get('/roles'),
   createUser: (data) => api.post('/createuser', data),
   editUser: (id, data) => api.put(`/user/edit/${id}`, data),